@@ -0,0 +1,348 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/weters/teamhex/internal/model"
+)
+
+// colorsFlag collects repeated -color name=#hex flags
+type colorsFlag []*model.Color
+
+func (f *colorsFlag) String() string {
+	parts := make([]string, len(*f))
+	for i, c := range *f {
+		parts[i] = c.Name + "=" + c.Hex
+	}
+
+	return strings.Join(parts, ",")
+}
+
+func (f *colorsFlag) Set(value string) error {
+	i := strings.LastIndex(value, "=")
+	if i <= 0 {
+		return fmt.Errorf("expected <name>=<hex>, got %q", value)
+	}
+
+	*f = append(*f, &model.Color{Name: value[:i], Hex: value[i+1:]})
+	return nil
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	return fs
+}
+
+func parseFlags(fs *flag.FlagSet, args []string, nArgs int) error {
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	if fs.NArg() != nArgs {
+		return errUsage
+	}
+
+	return nil
+}
+
+func loadModel() (*model.Model, error) {
+	data, err := model.Load(*dataFilename)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.NewFromDataFile(data), nil
+}
+
+// update loads the data file, applies fn and writes the file back if the
+// result is still valid. The changes are recorded in the history file.
+func update(fn func(data *model.DataFile) error) error {
+	filename := *historyFilename
+	if filename == "" {
+		filename = model.HistoryFilename(*dataFilename)
+	}
+
+	u, err := model.NewUpdater(model.BackendJSON, *dataFilename, model.NewFileHistory(filename))
+	if err != nil {
+		return err
+	}
+
+	_, err = u.Update(model.Edit{Actor: *actor, Reason: *reason}, fn)
+	return err
+}
+
+func runList(args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	m, err := loadModel()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case args[0] == "leagues" && len(args) == 1:
+		for _, league := range m.Leagues() {
+			fmt.Println(league.League)
+		}
+	case args[0] == "teams" && len(args) == 1:
+		printTeams(m.AllTeams())
+	case args[0] == "teams" && len(args) == 2:
+		teams, err := m.TeamsByLeague(args[1])
+		if err != nil {
+			return err
+		}
+		printTeams(teams)
+	default:
+		return errUsage
+	}
+
+	return nil
+}
+
+func runShow(args []string) error {
+	if len(args) != 2 {
+		return errUsage
+	}
+
+	m, err := loadModel()
+	if err != nil {
+		return err
+	}
+
+	team, err := m.TeamByLeagueAndName(args[0], args[1])
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Name:\t%s\n", team.Name)
+	fmt.Fprintf(w, "League:\t%s\n", team.League)
+	if team.Division != "" {
+		fmt.Fprintf(w, "Division:\t%s\n", team.Division)
+	}
+	fmt.Fprintf(w, "ID:\t%d\n", team.ID)
+	fmt.Fprintf(w, "Slug:\t%s\n", team.Slug)
+	if len(team.FormerNames) > 0 {
+		fmt.Fprintf(w, "Former names:\t%s\n", strings.Join(team.FormerNames, ", "))
+	}
+	for _, identity := range team.Predecessors {
+		fmt.Fprintf(w, "Predecessor:\t%s (%d-%d)\n", identity.Name, identity.From, identity.To)
+	}
+	for _, era := range team.Eras {
+		fmt.Fprintf(w, "\n%d\t%s\n", era.Year, describeEra(era))
+		for _, color := range era.Colors {
+			fields := []string{color.Name, color.Hex, color.Role, color.Pantone}
+			for fields[len(fields)-1] == "" {
+				fields = fields[:len(fields)-1]
+			}
+			fmt.Fprintf(w, "  %s\n", strings.Join(fields, "\t"))
+		}
+	}
+
+	return w.Flush()
+}
+
+// describeEra returns the label and dates of an era, e.g. "alternate, 2019-01-01 to 2019-12-31"
+func describeEra(era *model.Era) string {
+	label := era.Label
+	if label == "" {
+		label = model.PrimaryLabel
+	}
+
+	if era.End == nil {
+		return fmt.Sprintf("%s, from %s", label, era.StartDate())
+	}
+
+	return fmt.Sprintf("%s, %s to %s", label, era.StartDate(), era.End)
+}
+
+func runSearch(args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	m, err := loadModel()
+	if err != nil {
+		return err
+	}
+
+	printTeams(m.Search(args[0]))
+	return nil
+}
+
+func runAddTeam(args []string) error {
+	fs := newFlagSet("add-team")
+	division := fs.String("division", "", "division or conference")
+	id := fs.Int("id", 0, "team ID (default is the next ID that has never been used)")
+	year := fs.Int("year", 0, "year the colors were introduced")
+	var colors colorsFlag
+	fs.Var(&colors, "color", "color as <name>=<hex>; may be repeated")
+	if err := parseFlags(fs, args, 2); err != nil {
+		return err
+	}
+
+	return update(func(data *model.DataFile) error {
+		return data.AddTeam(&model.Team{
+			ID:       *id,
+			Name:     fs.Arg(1),
+			Eras:     []*model.Era{{Year: *year, Colors: colors}},
+			League:   fs.Arg(0),
+			Division: *division,
+		})
+	})
+}
+
+func runAddEra(args []string) error {
+	fs := newFlagSet("add-era")
+	year := fs.Int("year", 0, "year the colors were introduced")
+	label := fs.String("label", "", "how the colors are used, such as alternate or throwback (default primary)")
+	start := fs.String("start", "", "first day of the era as YYYY-MM-DD (default January 1 of the year)")
+	end := fs.String("end", "", "last day of the era as YYYY-MM-DD (default none)")
+	var colors colorsFlag
+	fs.Var(&colors, "color", "color as <name>=<hex>; may be repeated")
+	if err := parseFlags(fs, args, 2); err != nil {
+		return err
+	}
+
+	era := &model.Era{Year: *year, Label: *label, Colors: colors}
+	if *start != "" {
+		date, err := model.ParseDate(*start)
+		if err != nil {
+			return err
+		}
+		era.Start = date
+	}
+
+	if *end != "" {
+		date, err := model.ParseDate(*end)
+		if err != nil {
+			return err
+		}
+		era.End = &date
+	}
+
+	return update(func(data *model.DataFile) error {
+		return data.AddEra(fs.Arg(0), fs.Arg(1), era)
+	})
+}
+
+func runSetColor(args []string) error {
+	fs := newFlagSet("set-color")
+	add := fs.Bool("add", false, "add the color if the era does not have it")
+	label := fs.String("label", "", "the label of the era (default primary)")
+	pantone := fs.String("pantone", "", "the published Pantone reference")
+	cmyk := fs.String("cmyk", "", "the published CMYK values")
+	rgb := fs.String("rgb", "", "the published RGB values")
+	source := fs.String("source", "", "a URL or citation for the published values")
+	role := fs.String("role", "", "how the color is used: "+strings.Join(model.Roles, ", "))
+	if err := parseFlags(fs, args, 5); err != nil {
+		return err
+	}
+
+	key, err := model.ParseEraKey(fs.Arg(2), *label)
+	if err != nil {
+		return fmt.Errorf("invalid year or start date %q", fs.Arg(2))
+	}
+
+	color := &model.Color{Name: fs.Arg(3), Hex: fs.Arg(4), Pantone: *pantone, Source: *source, Role: *role}
+	if *cmyk != "" {
+		v, err := parseNumbers(*cmyk, 4)
+		if err != nil {
+			return fmt.Errorf("invalid CMYK values %q: %v", *cmyk, err)
+		}
+		color.CMYK = &model.CMYK{C: v[0], M: v[1], Y: v[2], K: v[3]}
+	}
+
+	if *rgb != "" {
+		v, err := parseNumbers(*rgb, 3)
+		if err != nil {
+			return fmt.Errorf("invalid RGB values %q: %v", *rgb, err)
+		}
+
+		for _, channel := range v {
+			if channel != float64(uint8(channel)) {
+				return fmt.Errorf("invalid RGB values %q: expected whole numbers from 0 to 255", *rgb)
+			}
+		}
+		color.RGB = &model.RGB{R: uint8(v[0]), G: uint8(v[1]), B: uint8(v[2])}
+	}
+
+	return update(func(data *model.DataFile) error {
+		return data.SetColor(fs.Arg(0), fs.Arg(1), key, color, *add)
+	})
+}
+
+// parseNumbers parses n comma-separated numbers
+func parseNumbers(s string, n int) ([]float64, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != n {
+		return nil, fmt.Errorf("expected %d comma-separated numbers", n)
+	}
+
+	values := make([]float64, n)
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+
+	return values, nil
+}
+
+func runRename(args []string) error {
+	if len(args) != 3 {
+		return errUsage
+	}
+
+	return update(func(data *model.DataFile) error {
+		return data.RenameTeam(args[0], args[1], args[2])
+	})
+}
+
+func runValidate(args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+
+	if _, err := model.Load(*dataFilename); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s: OK\n", *dataFilename)
+	return nil
+}
+
+func printTeams(teams model.Teams) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, team := range teams {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", team.League, team.Name, team.Division)
+	}
+	w.Flush()
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command teamhexctl queries and edits the team colors data file offline.
+//
+// Every command that changes the file validates the result before writing it
+// back in the same layout it was authored in, so diffs stay small.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+var dataFilename = flag.String("file", "configs/teamhex.json", "path to JSON colors file")
+var historyFilename = flag.String("history-file", "", "file changes are recorded in (default is the colors file with a .history.jsonl extension)")
+var actor = flag.String("actor", os.Getenv("USER"), "who is making the change, recorded in the history")
+var reason = flag.String("reason", "", "why the change is being made, recorded in the history")
+
+// errUsage is returned by a command when its arguments are wrong
+var errUsage = errors.New("invalid arguments")
+
+type command struct {
+	usage       string
+	description string
+	run         func(args []string) error
+}
+
+var commands = map[string]*command{
+	"list": {
+		usage:       "list leagues | list teams [league]",
+		description: "list the leagues, or the teams in all or one league",
+		run:         runList,
+	},
+	"show": {
+		usage:       "show <league> <team>",
+		description: "show a team and its colors in every era",
+		run:         runShow,
+	},
+	"search": {
+		usage:       "search <query>",
+		description: "search for teams by name",
+		run:         runSearch,
+	},
+	"add-team": {
+		usage:       "add-team [-division <division>] [-id <id>] -year <year> -color <name>=<hex>... <league> <team>",
+		description: "add a team with its current era",
+		run:         runAddTeam,
+	},
+	"add-era": {
+		usage:       "add-era -year <year> [-label <label>] [-start <YYYY-MM-DD>] [-end <YYYY-MM-DD>] -color <name>=<hex>... <league> <team>",
+		description: "add an era to a team",
+		run:         runAddEra,
+	},
+	"set-color": {
+		usage:       "set-color [-add] [-label <label>] [-pantone <ref>] [-cmyk <c,m,y,k>] [-rgb <r,g,b>] [-source <url>] [-role <role>] <league> <team> <year|YYYY-MM-DD> <color name> <hex>",
+		description: "change the values of a color, or add the color with -add",
+		run:         runSetColor,
+	},
+	"rename": {
+		usage:       "rename <league> <team> <new name>",
+		description: "rename a team, keeping the old name as a former name",
+		run:         runRename,
+	},
+	"validate": {
+		usage:       "validate",
+		description: "check the file for problems",
+		run:         runValidate,
+	},
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "teamhexctl: unknown command %q\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+
+	if err := cmd.run(flag.Args()[1:]); err != nil {
+		if err == errUsage {
+			fmt.Fprintf(os.Stderr, "usage: teamhexctl [-file <file>] %s\n", cmd.usage)
+			os.Exit(2)
+		}
+
+		fmt.Fprintf(os.Stderr, "teamhexctl: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("usage: teamhexctl [-file <file>] <command> [arguments]\n\nCommands:\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "  %-10s %s\n", name, commands[name].description)
+		fmt.Fprintf(&b, "  %-10s   %s\n", "", commands[name].usage)
+	}
+	b.WriteString("\nFlags:\n")
+	fmt.Fprint(flag.CommandLine.Output(), b.String())
+	flag.PrintDefaults()
+}
@@ -0,0 +1,46 @@
+//go:build sqlite
+// +build sqlite
+
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"github.com/weters/teamhex/internal/model"
+	"github.com/weters/teamhex/internal/model/sqlite"
+)
+
+func init() {
+	commands["export-sqlite"] = &command{
+		usage:       "export-sqlite <database>",
+		description: "write every team to a SQLite database for teamhexserver -store sqlite",
+		run:         runExportSQLite,
+	}
+}
+
+func runExportSQLite(args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	data, err := model.Load(*dataFilename)
+	if err != nil {
+		return err
+	}
+
+	return sqlite.Save(args[0], data)
+}
@@ -17,45 +17,252 @@ limitations under the License.
 package main
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"flag"
+	"fmt"
 	"github.com/gorilla/handlers"
 	"github.com/rs/cors"
 	"github.com/sirupsen/logrus"
+	"github.com/weters/teamhex/internal/config"
 	"github.com/weters/teamhex/internal/controller"
 	"github.com/weters/teamhex/internal/model"
+	"io/ioutil"
 	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 )
 
-const readTimeout = time.Second * 10
-const writeTimeout = time.Second * 5
-
 //Version is the version of the API. You can set it by passing `-ldflags "-X main.Version=v1.0.0"`
 var Version = "v0.0.0"
 var addr = flag.String("addr", ":5000", "address to listen on")
-var dataFilename = flag.String("file", "configs/teamhex.json", "path to JSON colors file")
+var dataFilename = flag.String("file", "configs/teamhex.json", "path to the colors file")
+var store = flag.String("store", model.BackendJSON, "storage backend for the colors file ("+strings.Join(model.Backends(), ", ")+")")
+var check = flag.Bool("check", false, "validate the colors file and exit")
+var apiKeysFile = flag.String("api-keys-file", "", "file of API keys, one `name:key` per line, that may change teams (writes are disabled if empty)")
+var historyFile = flag.String("history-file", "", "file the history of changes made over HTTP is appended to (default is the colors file with a .history.jsonl extension)")
+var reloadInterval = flag.Duration("reload-interval", time.Second*5, "how often to check the colors file for changes (0 disables; SIGHUP always reloads)")
+var configFile = flag.String(config.ConfigFlag, "", "JSON file of settings, keyed by flag name (flags and TEAMHEX_* environment variables take precedence)")
+var readTimeout = flag.Duration("read-timeout", time.Second*10, "maximum time to read a request")
+var writeTimeout = flag.Duration("write-timeout", time.Second*5, "maximum time to write a response")
+var idleTimeout = flag.Duration("idle-timeout", time.Second*60, "maximum time to keep an idle connection open")
+var shutdownTimeout = flag.Duration("shutdown-timeout", time.Second*25, "how long to wait for requests to finish after SIGTERM or SIGINT")
+var corsOrigins = flag.String("cors-origins", "*", "comma-separated origins allowed to make cross-origin requests")
+var logLevel = flag.String("log-level", logrus.InfoLevel.String(), "minimum level to log (debug, info, warn or error)")
+var logFormat = flag.String("log-format", "text", "log format (text or json)")
+var tlsCert = flag.String("tls-cert", "", "certificate file to serve HTTPS with (requires -tls-key)")
+var tlsKey = flag.String("tls-key", "", "private key file for -tls-cert")
+var metricsAddr = flag.String("metrics-addr", "", "address to serve /metrics on (default is to serve it with the API on -addr)")
 
 func main() {
 	flag.Parse()
+	if err := config.Apply(flag.CommandLine, os.Getenv); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if err := configureLogging(*logLevel, *logFormat); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if (*tlsCert == "") != (*tlsKey == "") {
+		fmt.Fprintln(os.Stderr, "-tls-cert and -tls-key must be given together")
+		os.Exit(2)
+	}
+
+	if *check {
+		if _, err := model.Open(*store, *dataFilename); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", *dataFilename, err)
+			os.Exit(1)
+		}
 
-	m, err := model.New(*dataFilename)
+		fmt.Printf("%s: OK\n", *dataFilename)
+		return
+	}
+
+	start := time.Now()
+	s, err := model.Open(*store, *dataFilename)
 	if err != nil {
-		logrus.WithError(err).Fatal("could not load model")
+		logrus.WithError(err).Fatal("could not open store")
+	}
+	c := controller.New(s, Version)
+	c.ObserveLoad(time.Since(start))
+
+	if *historyFile == "" {
+		*historyFile = model.HistoryFilename(*dataFilename)
 	}
-	c := controller.New(m, Version)
+	history := model.NewFileHistory(*historyFile)
+	c.EnableHistory(history)
+
+	allowedMethods := []string{http.MethodGet}
+	if *apiKeysFile != "" {
+		keys, err := readAPIKeys(*apiKeysFile)
+		if err != nil {
+			logrus.WithError(err).Fatal("could not read API keys")
+		}
+
+		u, err := model.NewUpdater(*store, *dataFilename, history)
+		if err != nil {
+			logrus.WithError(err).Fatal("could not enable writes")
+		}
+
+		c.EnableWrites(u, keys)
+		allowedMethods = append(allowedMethods, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete)
+		logrus.WithField("keys", len(keys)).Info("writes enabled")
+	}
+
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	go newReloader(c, *store, *dataFilename, *reloadInterval).run(sighup)
 
 	corsHandler := cors.New(cors.Options{
-		AllowedMethods: []string{http.MethodGet},
+		AllowedOrigins: splitList(*corsOrigins),
+		AllowedMethods: allowedMethods,
+		AllowedHeaders: []string{"Authorization", "Content-Type", "X-API-Key", "X-Change-Reason"},
 	})
 
-	server := &http.Server{
+	servers := []*http.Server{{
 		Addr:         *addr,
 		Handler:      handlers.CombinedLoggingHandler(os.Stdout, corsHandler.Handler(c)),
-		ReadTimeout:  readTimeout,
-		WriteTimeout: writeTimeout,
+		ReadTimeout:  *readTimeout,
+		WriteTimeout: *writeTimeout,
+		IdleTimeout:  *idleTimeout,
+	}}
+
+	if *metricsAddr == "" {
+		c.EnableMetrics()
+	} else {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", c.MetricsHandler())
+		servers = append(servers, &http.Server{
+			Addr:         *metricsAddr,
+			Handler:      mux,
+			ReadTimeout:  *readTimeout,
+			WriteTimeout: *writeTimeout,
+			IdleTimeout:  *idleTimeout,
+		})
+	}
+
+	errs := make(chan error, len(servers))
+	for i, server := range servers {
+		useTLS := *tlsCert != "" && i == 0
+		logrus.WithFields(logrus.Fields{"addr": server.Addr, "tls": useTLS}).Info("Server started")
+		go func(server *http.Server) {
+			if useTLS {
+				errs <- server.ListenAndServeTLS(*tlsCert, *tlsKey)
+			} else {
+				errs <- server.ListenAndServe()
+			}
+		}(server)
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGTERM, syscall.SIGINT)
+
+	select {
+	case err := <-errs:
+		logrus.WithError(err).Fatal("server stopped")
+	case sig := <-stop:
+		logrus.WithFields(logrus.Fields{"signal": sig.String(), "timeout": shutdownTimeout.String()}).Info("shutting down")
+	}
+
+	if err := shutdown(servers, *shutdownTimeout); err != nil {
+		logrus.WithError(err).Fatal("could not finish serving requests")
+	}
+
+	logrus.Info("Server stopped")
+}
+
+// shutdown stops the servers accepting connections and waits up to timeout
+// for the requests being served to finish
+func shutdown(servers []*http.Server, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	errs := make(chan error, len(servers))
+	for _, server := range servers {
+		go func(server *http.Server) {
+			errs <- server.Shutdown(ctx)
+		}(server)
+	}
+
+	var err error
+	for range servers {
+		if e := <-errs; e != nil && err == nil {
+			err = e
+		}
+	}
+
+	return err
+}
+
+func configureLogging(level, format string) error {
+	l, err := logrus.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	logrus.SetLevel(l)
+
+	switch format {
+	case "text":
+		logrus.SetFormatter(&logrus.TextFormatter{})
+	case "json":
+		logrus.SetFormatter(&logrus.JSONFormatter{})
+	default:
+		return fmt.Errorf("unknown log format %q, expected text or json", format)
+	}
+
+	return nil
+}
+
+// splitList splits a comma-separated list, ignoring blank entries
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
+
+// readAPIKeys reads one key per line, skipping blank lines and # comments.
+// A line of name:key names the key in the history; a key without a name is
+// recorded by a fingerprint of the key.
+func readAPIKeys(filename string) ([]controller.APIKey, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []controller.APIKey
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key := controller.APIKey{Key: line}
+		if i := strings.Index(line, ":"); i >= 0 {
+			key.Name, key.Key = strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:])
+		}
+
+		if key.Name == "" {
+			sum := sha256.Sum256([]byte(key.Key))
+			key.Name = "key-" + hex.EncodeToString(sum[:4])
+		}
+		keys = append(keys, key)
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%s: no API keys found", filename)
 	}
 
-	logrus.WithField("addr", server.Addr).Info("Server started")
-	logrus.Fatal(server.ListenAndServe())
+	return keys, nil
 }
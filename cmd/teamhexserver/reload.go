@@ -0,0 +1,116 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/weters/teamhex/internal/controller"
+	"github.com/weters/teamhex/internal/model"
+)
+
+// reloader reopens the store whenever the data file changes on disk or a
+// reload is requested, and swaps it into the controller
+type reloader struct {
+	c        *controller.Controller
+	backend  string
+	filename string
+	interval time.Duration
+	modTime  time.Time
+	size     int64
+}
+
+func newReloader(c *controller.Controller, backend, filename string, interval time.Duration) *reloader {
+	r := &reloader{
+		c:        c,
+		backend:  backend,
+		filename: filename,
+		interval: interval,
+	}
+
+	if info, err := os.Stat(filename); err == nil {
+		r.modTime = info.ModTime()
+		r.size = info.Size()
+	}
+
+	return r
+}
+
+// run blocks forever, polling the data file every interval and reloading on
+// every value received from signals. Polling is disabled if interval is zero.
+func (r *reloader) run(signals <-chan os.Signal) {
+	var tick <-chan time.Time
+	if r.interval > 0 {
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case sig := <-signals:
+			logrus.WithField("signal", sig.String()).Info("reload requested")
+			r.reload()
+		case <-tick:
+			if r.changed() {
+				logrus.WithField("file", r.filename).Info("data file changed")
+				r.reload()
+			}
+		}
+	}
+}
+
+// changed reports whether the file has been modified since it was last seen
+func (r *reloader) changed() bool {
+	info, err := os.Stat(r.filename)
+	if err != nil {
+		logrus.WithError(err).WithField("file", r.filename).Warn("could not stat data file")
+		return false
+	}
+
+	if info.ModTime().Equal(r.modTime) && info.Size() == r.size {
+		return false
+	}
+
+	r.modTime = info.ModTime()
+	r.size = info.Size()
+	return true
+}
+
+// reload opens a new store and swaps it in. If the store cannot be opened,
+// the current store continues to be served.
+func (r *reloader) reload() {
+	start := time.Now()
+	s, err := r.c.ReplaceStore(func() (model.Store, error) {
+		return model.Open(r.backend, r.filename)
+	})
+	r.c.ObserveReload(err)
+	if err != nil {
+		logrus.WithError(err).WithField("file", r.filename).Error("could not reload store, keeping current store")
+		return
+	}
+
+	r.c.ObserveLoad(time.Since(start))
+	logrus.WithFields(logrus.Fields{
+		"file":       r.filename,
+		"generated":  s.GenerationDate(),
+		"teams":      len(s.AllTeams()),
+		"durationMs": time.Since(start).Milliseconds(),
+	}).Info("store reloaded")
+}
@@ -3,18 +3,16 @@ module github.com/weters/teamhex
 go 1.13
 
 require (
-	github.com/golang/protobuf v1.3.3 // indirect
+	github.com/andybalholm/brotli v1.0.4
 	github.com/gorilla/handlers v1.4.2
 	github.com/gorilla/mux v1.7.4
 	github.com/kr/pretty v0.2.0 // indirect
+	github.com/mattn/go-sqlite3 v1.14.0
 	github.com/onsi/gomega v1.9.0
+	github.com/prometheus/client_golang v1.7.0
 	github.com/rs/cors v1.7.0
 	github.com/sirupsen/logrus v1.4.2
 	github.com/stretchr/testify v1.4.0 // indirect
-	golang.org/x/net v0.0.0-20200114155413-6afb5195e5aa // indirect
-	golang.org/x/sys v0.0.0-20200124204421-9fbb57f87de9 // indirect
 	golang.org/x/text v0.3.2 // indirect
-	golang.org/x/xerrors v0.0.0-20191011141410-1b5146add898 // indirect
-	gopkg.in/check.v1 v1.0.0-20180628173108-788fd7840127 // indirect
 	gopkg.in/yaml.v2 v2.2.8 // indirect
 )
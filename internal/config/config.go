@@ -0,0 +1,160 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package config fills in command line flags from environment variables and
+// a JSON config file.
+//
+// A setting is taken from the first of these that has it:
+//
+//   1. the command line flag, e.g. -read-timeout 10s
+//   2. the environment variable, e.g. TEAMHEX_READ_TIMEOUT=10s
+//   3. the config file, e.g. {"read-timeout": "10s"}
+//   4. the flag's default
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+// EnvPrefix starts the name of every environment variable
+const EnvPrefix = "TEAMHEX_"
+
+// EnvName returns the environment variable for a flag, e.g. TEAMHEX_READ_TIMEOUT
+// for read-timeout
+func EnvName(flagName string) string {
+	return EnvPrefix + strings.ToUpper(strings.Replace(flagName, "-", "_", -1))
+}
+
+// ConfigFlag is the name of the flag that names the config file
+const ConfigFlag = "config"
+
+// Apply sets every flag in fs that was not given on the command line from
+// its environment variable or, failing that, the config file named by the
+// config flag. getenv is usually os.Getenv.
+func Apply(fs *flag.FlagSet, getenv func(string) string) error {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	var configFile string
+	if f := fs.Lookup(ConfigFlag); f != nil {
+		configFile = f.Value.String()
+		if !set[ConfigFlag] && getenv(EnvName(ConfigFlag)) != "" {
+			configFile = getenv(EnvName(ConfigFlag))
+		}
+	}
+
+	var file map[string]string
+	if configFile != "" {
+		var err error
+		if file, err = readFile(fs, configFile); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil || set[f.Name] {
+			return
+		}
+
+		if value := getenv(EnvName(f.Name)); value != "" {
+			if e := fs.Set(f.Name, value); e != nil {
+				err = fmt.Errorf("config: %s: invalid value %q: %v", EnvName(f.Name), value, e)
+			}
+			return
+		}
+
+		if value, ok := file[f.Name]; ok && f.Name != ConfigFlag {
+			if e := fs.Set(f.Name, value); e != nil {
+				err = fmt.Errorf("config: %s: %s: invalid value %q: %v", configFile, f.Name, value, e)
+			}
+		}
+	})
+
+	return err
+}
+
+// readFile reads a JSON object of flag names to values. Lists of strings
+// are joined with commas.
+func readFile(fs *flag.FlagSet, filename string) (map[string]string, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("config: %v", err)
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, fmt.Errorf("config: %s: %v", filename, err)
+	}
+
+	names := make([]string, 0, len(raw))
+	for name := range raw {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	values := make(map[string]string, len(raw))
+	for _, name := range names {
+		if fs.Lookup(name) == nil {
+			return nil, fmt.Errorf("config: %s: unknown setting %q", filename, name)
+		}
+
+		value, err := settingValue(raw[name])
+		if err != nil {
+			return nil, fmt.Errorf("config: %s: %s: %v", filename, name, err)
+		}
+		values[name] = value
+	}
+
+	return values, nil
+}
+
+func settingValue(raw json.RawMessage) (string, error) {
+	var v interface{}
+	dec := json.NewDecoder(strings.NewReader(string(raw)))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return "", err
+	}
+
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return fmt.Sprint(v), nil
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, part := range v {
+			s, ok := part.(string)
+			if !ok {
+				return "", fmt.Errorf("expected a list of strings")
+			}
+			parts[i] = s
+		}
+		return strings.Join(parts, ","), nil
+	}
+
+	return "", fmt.Errorf("expected a string, number, boolean or list of strings")
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+
+package config
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+)
+
+type settings struct {
+	fs           *flag.FlagSet
+	config       *string
+	addr         *string
+	readTimeout  *time.Duration
+	corsOrigins  *string
+	writeTimeout *time.Duration
+}
+
+func newSettings() *settings {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	return &settings{
+		fs:           fs,
+		config:       fs.String("config", "", ""),
+		addr:         fs.String("addr", ":5000", ""),
+		readTimeout:  fs.Duration("read-timeout", 10*time.Second, ""),
+		corsOrigins:  fs.String("cors-origins", "*", ""),
+		writeTimeout: fs.Duration("write-timeout", 5*time.Second, ""),
+	}
+}
+
+func writeFile(t *testing.T, dir, contents string) string {
+	f, err := ioutil.TempFile(dir, "teamhex.*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(contents); err != nil {
+		t.Fatal(err)
+	}
+
+	return f.Name()
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "teamhex")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return dir
+}
+
+func env(vars map[string]string) func(string) string {
+	return func(name string) string {
+		return vars[name]
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	g := gomega.NewWithT(t)
+	g.Expect(EnvName("read-timeout")).Should(gomega.Equal("TEAMHEX_READ_TIMEOUT"))
+	g.Expect(EnvName("addr")).Should(gomega.Equal("TEAMHEX_ADDR"))
+}
+
+func TestApplyPrecedence(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	filename := writeFile(t, dir, `{
+		"addr": ":6000",
+		"read-timeout": "20s",
+		"cors-origins": ["https://teamhex.dev", "https://www.teamhex.dev"],
+		"write-timeout": "15s"
+	}`)
+
+	s := newSettings()
+	g.Expect(s.fs.Parse([]string{"-config", filename, "-read-timeout", "30s"})).Should(gomega.Succeed())
+	g.Expect(Apply(s.fs, env(map[string]string{
+		"TEAMHEX_READ_TIMEOUT":  "40s",
+		"TEAMHEX_WRITE_TIMEOUT": "45s",
+	}))).Should(gomega.Succeed())
+
+	g.Expect(*s.readTimeout).Should(gomega.Equal(30*time.Second), "flag beats env and file")
+	g.Expect(*s.writeTimeout).Should(gomega.Equal(45*time.Second), "env beats file")
+	g.Expect(*s.addr).Should(gomega.Equal(":6000"), "file beats default")
+	g.Expect(*s.corsOrigins).Should(gomega.Equal("https://teamhex.dev,https://www.teamhex.dev"))
+}
+
+func TestApplyConfigFromEnv(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	filename := writeFile(t, dir, `{"addr": ":6000"}`)
+
+	s := newSettings()
+	g.Expect(s.fs.Parse(nil)).Should(gomega.Succeed())
+	g.Expect(Apply(s.fs, env(map[string]string{"TEAMHEX_CONFIG": filename}))).Should(gomega.Succeed())
+	g.Expect(*s.config).Should(gomega.Equal(filename))
+	g.Expect(*s.addr).Should(gomega.Equal(":6000"))
+	g.Expect(*s.readTimeout).Should(gomega.Equal(10 * time.Second))
+}
+
+func TestApplyWithoutConfig(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	s := newSettings()
+	g.Expect(s.fs.Parse(nil)).Should(gomega.Succeed())
+	g.Expect(Apply(s.fs, env(nil))).Should(gomega.Succeed())
+	g.Expect(*s.addr).Should(gomega.Equal(":5000"))
+}
+
+func TestApplyErrors(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		contents string
+		env      map[string]string
+		err      string
+	}{
+		{`{"adr": ":6000"}`, nil, `unknown setting "adr"`},
+		{`{"read-timeout": "soon"}`, nil, `read-timeout: invalid value "soon"`},
+		{`{"addr": {"port": 5000}}`, nil, "addr: expected a string, number, boolean or list of strings"},
+		{`{"cors-origins": [1, 2]}`, nil, "cors-origins: expected a list of strings"},
+		{`["addr"]`, nil, "cannot unmarshal array"},
+		{`{}`, map[string]string{"TEAMHEX_WRITE_TIMEOUT": "later"}, `config: TEAMHEX_WRITE_TIMEOUT: invalid value "later"`},
+	}
+
+	for _, test := range tests {
+		filename := writeFile(t, dir, test.contents)
+
+		s := newSettings()
+		g.Expect(s.fs.Parse([]string{"-config", filename})).Should(gomega.Succeed())
+		err := Apply(s.fs, env(test.env))
+		g.Expect(err).Should(gomega.HaveOccurred(), test.contents)
+		g.Expect(err.Error()).Should(gomega.ContainSubstring(test.err))
+	}
+
+	s := newSettings()
+	g.Expect(s.fs.Parse([]string{"-config", "does-not-exist.json"})).Should(gomega.Succeed())
+	g.Expect(Apply(s.fs, env(nil))).Should(gomega.MatchError(gomega.ContainSubstring("does-not-exist.json")))
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/weters/teamhex/internal/model"
+)
+
+// Successful response
+// swagger:response accessibilityResponse
+type accessibilityResponse *model.AccessibilityReport
+
+// swagger:operation GET /leagues/{league}/{team}/accessibility leagues getTeamAccessibility
+//
+// Get a WCAG 2.1 contrast report for a team's colors
+//
+// This endpoint returns the contrast ratio of every pair of colors in each of the team's eras, whether each pair
+// meets levels AA and AAA for normal and large text, and the most readable text color for each background. The
+// recommended text color is a team color when one meets level AA, otherwise black or white.
+//
+// ---
+// produces:
+// - application/json
+// parameters:
+// - in: path
+//   name: league
+//   required: true
+//   type: string
+// - in: path
+//   name: team
+//   required: true
+//   type: string
+// - name: year
+//   in: query
+//   description: Only report on the era in effect for the year
+//   required: false
+//   type: integer
+// - name: date
+//   in: query
+//   description: Only report on the era in effect on the day, as YYYY-MM-DD
+//   required: false
+//   type: string
+// - name: label
+//   in: query
+//   description: With year or date, report on the era with the label instead of the primary era
+//   required: false
+//   type: string
+// responses:
+//   '200':
+//     '$ref': '#/responses/accessibilityResponse'
+//   '301':
+//     description: The team was asked for by its name or a former name. Location is its canonical URL.
+//   '400':
+//     '$ref': '#/responses/errorResponse'
+//   '404':
+//     '$ref': '#/responses/errorResponse'
+//   '500':
+//     '$ref': '#/responses/errorResponse'
+func (c *Controller) getLeaguesLeagueTeamAccessibility() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s := c.Store()
+		leagueName, teamName := mux.Vars(r)["league"], mux.Vars(r)["team"]
+		report, err := s.Accessibility(leagueName, teamName)
+		if err != nil {
+			serveModelError(w, err)
+			return
+		}
+
+		if len(r.FormValue("year")) > 0 || len(r.FormValue("date")) > 0 {
+			team, err := s.TeamByLeagueAndName(leagueName, teamName)
+			if err != nil {
+				serveModelError(w, err)
+				return
+			}
+
+			era, err := eraForRequest(r, team)
+			if err != nil {
+				serveModelError(w, err)
+				return
+			}
+
+			// the report has an entry for each of the team's eras in order
+			for i, e := range team.Eras {
+				if e == era {
+					report.Eras = []*model.EraAccessibility{report.Eras[i]}
+					break
+				}
+			}
+		}
+
+		serveJSON(w, http.StatusOK, report)
+	}
+}
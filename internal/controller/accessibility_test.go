@@ -0,0 +1,65 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/onsi/gomega"
+	"github.com/weters/teamhex/internal/model"
+)
+
+func TestGetTeamAccessibility(t *testing.T) {
+	runWithSetupAndTeardown(t, func() {
+		res, body := getBody("/leagues/nfl/buffalo%20bills/accessibility", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+
+		var report model.AccessibilityReport
+		must(json.Unmarshal([]byte(body), &report))
+		g.Expect(report.Team).Should(gomega.Equal("Buffalo Bills"))
+		g.Expect(len(report.Eras)).Should(gomega.Equal(2))
+
+		res, body = getBody("/leagues/nfl/buffalo%20bills/accessibility?year=2005", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(body).Should(gomega.MatchJSON(`{
+			"team": "Buffalo Bills",
+			"league": "NFL",
+			"eras": [
+				{
+					"year": 2002,
+					"pairs": [],
+					"backgrounds": [
+						{
+							"background": { "name": "Midnight Navy", "hex": "#091F2C" },
+							"text": { "name": "White", "hex": "#FFFFFF" },
+							"ratio": 16.88
+						}
+					]
+				}
+			],
+			"_link": "/leagues/nfl/buffalo-bills"
+		}`))
+
+		res, _ = getBody("/leagues/nfl/buffalo%20bills/accessibility?year=1990", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusNotFound))
+
+		res, _ = getBody("/leagues/nfl/oakland%20raiders/accessibility", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusNotFound))
+	})
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/weters/teamhex/internal/model"
+)
+
+const (
+	defaultAutocompleteLimit = 8
+	maxAutocompleteLimit     = 50
+)
+
+// Successful response
+// swagger:response autocompleteResponse
+type autocompleteResponse []*model.Suggestion
+
+// swagger:operation GET /autocomplete autocomplete getAutocomplete
+//
+// Suggest leagues, divisions and teams as a name is typed
+//
+// This endpoint returns leagues, divisions and teams with a name, alias or word in their name that starts with the
+// query. Matches from the start of a name come first, then leagues, divisions and teams in that order.
+//
+// ---
+// produces:
+// - application/json
+// parameters:
+// - name: q
+//   in: query
+//   description: The start of a name
+//   required: true
+//   type: string
+// - name: limit
+//   in: query
+//   description: The maximum number of suggestions to return
+//   required: false
+//   type: integer
+//   minimum: 1
+//   maximum: 50
+//   default: 8
+// responses:
+//   '200':
+//     '$ref': '#/responses/autocompleteResponse'
+//   '400':
+//     '$ref': '#/responses/errorResponse'
+func (c *Controller) getAutocomplete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.FormValue("q")
+		if len(q) == 0 {
+			serveJSONError(w, http.StatusBadRequest, errors.New("q is required"))
+			return
+		}
+
+		limit := defaultAutocompleteLimit
+		if l := r.FormValue("limit"); len(l) > 0 {
+			var err error
+			limit, err = strconv.Atoi(l)
+			if err != nil || limit < 1 || limit > maxAutocompleteLimit {
+				serveJSONError(w, http.StatusBadRequest, fmt.Errorf("limit must be a number from 1 to %d", maxAutocompleteLimit))
+				return
+			}
+		}
+
+		serveJSON(w, http.StatusOK, c.Store().Autocomplete(q, limit))
+	}
+}
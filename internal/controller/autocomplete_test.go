@@ -0,0 +1,55 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+func TestGetAutocomplete(t *testing.T) {
+	runWithSetupAndTeardown(t, func() {
+		res, body := getBody("/autocomplete?q=buffalo%20s", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(body).Should(gomega.MatchJSON(`[
+			{
+				"name": "Buffalo Sabres",
+				"type": "team",
+				"league": "NHL",
+				"_link": "/leagues/nhl/buffalo-sabres"
+			}
+		]`))
+
+		res, body = getBody("/autocomplete?q=n&limit=2", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(body).Should(gomega.MatchJSON(`[
+			{ "name": "NCAA", "type": "league", "league": "NCAA", "_link": "/leagues/ncaa" },
+			{ "name": "NFL", "type": "league", "league": "NFL", "_link": "/leagues/nfl" }
+		]`))
+
+		res, body = getBody("/autocomplete?q=xyz", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(body).Should(gomega.Equal("[]\n"))
+
+		for _, query := range []string{"", "q=a&limit=0", "q=a&limit=51", "q=a&limit=x"} {
+			res, _ = getBody("/autocomplete?"+query, nil)
+			g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusBadRequest), query)
+		}
+	})
+}
@@ -0,0 +1,405 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"compress/gzip"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gorilla/mux"
+	"github.com/weters/teamhex/internal/model"
+)
+
+// maxCachedResponses limits how many responses are kept for each store
+const maxCachedResponses = 1000
+
+// maxCachedBytes limits the size of the responses kept for each store,
+// including their compressed copies
+const maxCachedBytes = 32 << 20
+
+// minCompressSize is the smallest body worth compressing
+const minCompressSize = 256
+
+// Compression levels. Responses are compressed by the request that first
+// caches them, so these trade a little size for much less CPU than the best
+// compression.
+const (
+	gzipLevel   = gzip.DefaultCompression
+	brotliLevel = 5
+)
+
+// responseCache keeps the encoded and compressed GET responses for one
+// store. The data only changes when the store is replaced or a team's era
+// starts or ends, either of which replaces the cache.
+type responseCache struct {
+	lastModified time.Time
+	// erasChanged is the last era change before the cache was made
+	erasChanged model.Date
+	mu          sync.Mutex
+	entries     map[string]*list.Element
+	// order has the most recently used response at the front
+	order *list.List
+	// size is the number of bytes kept for all of the responses
+	size int
+}
+
+// newResponseCache returns an empty cache for the store's responses today.
+// Responses were last modified when the data was generated or, if later, when
+// the current eras took effect.
+func newResponseCache(s model.Store) *responseCache {
+	erasChanged := s.LastEraChange(model.Today())
+	lastModified := s.GenerationDate()
+	if erasChanged.After(lastModified) {
+		lastModified = erasChanged.Time
+	}
+
+	return &responseCache{
+		lastModified: lastModified.UTC().Truncate(time.Second),
+		erasChanged:  erasChanged,
+		entries:      make(map[string]*list.Element),
+		order:        list.New(),
+	}
+}
+
+func (rc *responseCache) get(key string) (*cachedResponse, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	e, ok := rc.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	rc.order.MoveToFront(e)
+	return e.Value.(*cachedResponse), true
+}
+
+func (rc *responseCache) add(res *cachedResponse) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if e, ok := rc.entries[res.key]; ok {
+		rc.order.MoveToFront(e)
+		return
+	}
+
+	rc.entries[res.key] = rc.order.PushFront(res)
+	rc.size += res.size
+	rc.evict()
+}
+
+// evict drops the least recently used responses until the cache is within
+// its limits. rc.mu must be held.
+func (rc *responseCache) evict() {
+	for rc.order.Len() > maxCachedResponses || rc.size > maxCachedBytes && rc.order.Len() > 1 {
+		oldest := rc.order.Back()
+		res := oldest.Value.(*cachedResponse)
+		rc.order.Remove(oldest)
+		delete(rc.entries, res.key)
+		rc.size -= res.size
+	}
+}
+
+// encodings are the content encodings responses are compressed with, in the
+// order they are preferred
+var encodings = []string{"br", "gzip"}
+
+// cachedResponse is a successful response along with its body in each
+// encoding that makes it smaller
+type cachedResponse struct {
+	key    string
+	header http.Header
+	etag   string
+	body   []byte
+	// compressed has the body in each encoding that makes it smaller
+	compressed map[string][]byte
+	// size is the number of bytes kept for the body in every encoding
+	size int
+}
+
+// newCachedResponse returns the response with its body compressed in each
+// encoding, so serving it again never has to compress it
+func newCachedResponse(key string, header http.Header, body []byte) *cachedResponse {
+	sum := sha256.Sum256(body)
+	res := &cachedResponse{
+		key:        key,
+		header:     header,
+		etag:       hex.EncodeToString(sum[:16]),
+		body:       body,
+		compressed: make(map[string][]byte),
+		size:       len(body),
+	}
+
+	if len(body) >= minCompressSize {
+		for _, encoding := range encodings {
+			if compressed := compress(encoding, body); compressed != nil {
+				res.compressed[encoding] = compressed
+				res.size += len(compressed)
+			}
+		}
+	}
+
+	return res
+}
+
+// compress returns body in the encoding, or nil if that isn't smaller
+func compress(encoding string, body []byte) []byte {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w, _ = gzip.NewWriterLevel(&buf, gzipLevel)
+	case "br":
+		w = brotli.NewWriterLevel(&buf, brotliLevel)
+	default:
+		return nil
+	}
+
+	if _, err := w.Write(body); err != nil || w.Close() != nil || buf.Len() >= len(body) {
+		return nil
+	}
+
+	return buf.Bytes()
+}
+
+// serve writes the response in the best encoding the client accepts. Each
+// encoding has its own strong ETag.
+func (res *cachedResponse) serve(w http.ResponseWriter, r *http.Request, lastModified time.Time) {
+	h := w.Header()
+	for key, values := range res.header {
+		h[key] = append([]string(nil), values...)
+	}
+	h.Add("Vary", "Accept-Encoding")
+	if !lastModified.IsZero() {
+		h.Set("Last-Modified", lastModified.Format(http.TimeFormat))
+	}
+
+	encoding := res.encoding(r)
+	etag := res.etag
+	if encoding != "" {
+		etag += "-" + encoding
+	}
+	h.Set("ETag", `"`+etag+`"`)
+
+	if res.notModified(r, lastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	body := res.body
+	if encoding != "" {
+		body = res.compressed[encoding]
+		h.Set("Content-Encoding", encoding)
+	}
+
+	h.Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+// encoding returns the preferred encoding the client accepts that the body
+// was compressed with, or "" if it should be sent uncompressed
+func (res *cachedResponse) encoding(r *http.Request) string {
+	acceptEncoding := r.Header.Get("Accept-Encoding")
+	for _, encoding := range encodings {
+		if _, ok := res.compressed[encoding]; ok && acceptsEncoding(acceptEncoding, encoding) {
+			return encoding
+		}
+	}
+
+	return ""
+}
+
+// notModified reports whether the client's copy is current. A copy in any
+// encoding is current if the body hasn't changed.
+func (res *cachedResponse) notModified(r *http.Request, lastModified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" {
+				return true
+			}
+
+			tag = strings.Trim(tag, `"`)
+			if tag == res.etag || strings.HasPrefix(tag, res.etag+"-") {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" && !lastModified.IsZero() {
+		t, err := http.ParseTime(ims)
+		return err == nil && !lastModified.After(t)
+	}
+
+	return false
+}
+
+// acceptsEncoding reports whether the Accept-Encoding header allows the
+// encoding, honoring q=0
+func acceptsEncoding(header, encoding string) bool {
+	accepted := false
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		name := strings.ToLower(strings.TrimSpace(fields[0]))
+		if name != encoding && name != "*" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+
+		// an exact match overrides *
+		if name == encoding {
+			return q > 0
+		}
+		accepted = q > 0
+	}
+
+	return accepted
+}
+
+// responseRecorder captures a response so it can be cached
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *responseRecorder) Header() http.Header {
+	return rec.header
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	rec.WriteHeader(http.StatusOK)
+	return rec.body.Write(b)
+}
+
+// cacheKey returns the key of the request's response, which is made of its
+// path, the query parameters its route reads and the stylesheet format it
+// negotiates. Requests for routes not registered with Controller.get are not
+// cached.
+func (c *Controller) cacheKey(r *http.Request) (string, bool) {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return "", false
+	}
+
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return "", false
+	}
+
+	queries, ok := c.cachedQueries[template]
+	if !ok {
+		return "", false
+	}
+
+	var key strings.Builder
+	key.WriteString(r.URL.Path)
+	query := r.URL.Query()
+	for _, name := range queries {
+		// handlers treat an empty parameter as a missing one
+		if value := query.Get(name); value != "" {
+			key.WriteString("\n" + name + "=" + value)
+		}
+	}
+
+	key.WriteString("\n" + negotiateStylesheet(r))
+	return key.String(), true
+}
+
+// cacheResponses serves GET requests from the current store's response
+// cache. Only successful responses without Cache-Control: no-store are
+// cached.
+func (c *Controller) cacheResponses(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, ok := c.cacheKey(r)
+		if r.Method != http.MethodGet || !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cache := c.responseCache()
+		res, ok := cache.get(key)
+		if !ok {
+			rec := &responseRecorder{header: make(http.Header)}
+			next.ServeHTTP(rec, r)
+
+			// responses that must not be stored, such as metrics, are passed through
+			if rec.status != http.StatusOK || strings.Contains(rec.header.Get("Cache-Control"), "no-store") {
+				for k, v := range rec.header {
+					w.Header()[k] = v
+				}
+				w.WriteHeader(rec.status)
+				_, _ = w.Write(rec.body.Bytes())
+				return
+			}
+
+			res = newCachedResponse(key, rec.header, rec.body.Bytes())
+			cache.add(res)
+		}
+
+		res.serve(w, r, cache.lastModified)
+	})
+}
+
+// responseCache returns the current store's response cache. The cache is
+// replaced on the first request after a team's era starts or ends, because
+// responses with the current eras change without the store changing.
+func (c *Controller) responseCache() *responseCache {
+	c.mu.RLock()
+	s, cache := c.store, c.cache
+	c.mu.RUnlock()
+
+	if s.LastEraChange(model.Today()).Equal(cache.erasChanged.Time) {
+		return cache
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cache == cache {
+		c.cache = newResponseCache(c.store)
+	}
+
+	return c.cache
+}
@@ -0,0 +1,268 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"github.com/onsi/gomega"
+	"github.com/weters/teamhex/internal/model"
+)
+
+// getRaw is getBody without the client's transparent gzip decoding
+func getRaw(path string, header http.Header) (*http.Response, []byte) {
+	req, err := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+	must(err)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	client := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+	res, err := client.Do(req)
+	must(err)
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	must(err)
+	return res, body
+}
+
+func TestCachedResponses(t *testing.T) {
+	runWithSetupAndTeardown(t, func() {
+		res, body := getRaw("/teams", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(res.Header.Get("Content-Type")).Should(gomega.Equal("application/json"))
+		g.Expect(res.Header.Get("Content-Encoding")).Should(gomega.BeEmpty())
+		g.Expect(res.Header.Get("Last-Modified")).Should(gomega.Equal("Sat, 22 Feb 2020 12:00:00 GMT"))
+		g.Expect(res.Header.Get("Vary")).Should(gomega.Equal("Accept-Encoding"))
+		etag := res.Header.Get("ETag")
+		g.Expect(etag).Should(gomega.MatchRegexp(`^"[0-9a-f]{32}"$`))
+		identity := string(body)
+
+		res, body = getRaw("/teams", http.Header{"Accept-Encoding": {"gzip"}})
+		g.Expect(res.Header.Get("Content-Encoding")).Should(gomega.Equal("gzip"))
+		g.Expect(res.Header.Get("ETag")).Should(gomega.Equal(etag[:33] + `-gzip"`))
+		gz, err := gzip.NewReader(bytes.NewReader(body))
+		must(err)
+		decoded, err := ioutil.ReadAll(gz)
+		must(err)
+		g.Expect(string(decoded)).Should(gomega.Equal(identity))
+
+		res, body = getRaw("/teams", http.Header{"Accept-Encoding": {"gzip, deflate, br"}})
+		g.Expect(res.Header.Get("Content-Encoding")).Should(gomega.Equal("br"))
+		decoded, err = ioutil.ReadAll(brotli.NewReader(bytes.NewReader(body)))
+		must(err)
+		g.Expect(string(decoded)).Should(gomega.Equal(identity))
+
+		res, _ = getRaw("/teams", http.Header{"Accept-Encoding": {"br;q=0, gzip"}})
+		g.Expect(res.Header.Get("Content-Encoding")).Should(gomega.Equal("gzip"))
+
+		// the same body in any encoding is not modified
+		for _, inm := range []string{etag, `"other", ` + etag[:33] + `-br"`, "*"} {
+			res, body = getRaw("/teams", http.Header{"If-None-Match": {inm}})
+			g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusNotModified), inm)
+			g.Expect(body).Should(gomega.BeEmpty())
+			g.Expect(res.Header.Get("ETag")).Should(gomega.Equal(etag))
+		}
+
+		res, body = getRaw("/teams", http.Header{"If-None-Match": {etag}, "Accept-Encoding": {"gzip"}})
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusNotModified))
+		g.Expect(body).Should(gomega.BeEmpty())
+		g.Expect(res.Header.Get("Content-Encoding")).Should(gomega.BeEmpty())
+		g.Expect(res.Header.Get("ETag")).Should(gomega.Equal(etag[:33] + `-gzip"`))
+
+		res, _ = getRaw("/teams", http.Header{"If-None-Match": {`"other"`}, "If-Modified-Since": {"Sat, 22 Feb 2020 12:00:00 GMT"}})
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+
+		res, _ = getRaw("/teams", http.Header{"If-Modified-Since": {"Sat, 22 Feb 2020 12:00:00 GMT"}})
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusNotModified))
+		res, _ = getRaw("/teams", http.Header{"If-Modified-Since": {"Sat, 22 Feb 2020 11:59:59 GMT"}})
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+
+		// each query is cached separately
+		res, body = getRaw("/teams?league=nhl", nil)
+		g.Expect(res.Header.Get("ETag")).ShouldNot(gomega.Equal(etag))
+		g.Expect(string(body)).ShouldNot(gomega.Equal(identity))
+
+		// errors are not cached
+		res, _ = getRaw("/leagues/nba", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusNotFound))
+		g.Expect(res.Header.Get("ETag")).Should(gomega.BeEmpty())
+	})
+}
+
+func TestCachedResponsesKey(t *testing.T) {
+	runWithSetupAndTeardown(t, func() {
+		c := New(s, "v1.0.0")
+		ts.Config.Handler = c
+
+		// a body is compressed as soon as it is cached
+		gzipped := http.Header{"Accept-Encoding": {"gzip"}}
+		res, _ := getRaw("/teams?league=nfl&sort=-name", gzipped)
+		g.Expect(res.Header.Get("Content-Encoding")).Should(gomega.Equal("gzip"))
+		etag := res.Header.Get("ETag")
+
+		// parameters the handler doesn't read and their order are ignored
+		for _, query := range []string{"sort=-name&league=nfl", "league=nfl&sort=-name&x=1", "league=nfl&sort=-name&page="} {
+			res, _ = getRaw("/teams?"+query, gzipped)
+			g.Expect(res.Header.Get("Content-Encoding")).Should(gomega.Equal("gzip"), query)
+			g.Expect(res.Header.Get("ETag")).Should(gomega.Equal(etag), query)
+		}
+		g.Expect(c.responseCache().order.Len()).Should(gomega.Equal(1))
+
+		// routes that don't read any parameters have one response
+		for i := 0; i < 3; i++ {
+			getRaw(fmt.Sprintf("/leagues?x=%d", i), nil)
+		}
+		g.Expect(c.responseCache().order.Len()).Should(gomega.Equal(2))
+
+		// the stylesheet format negotiated from Accept is part of the key
+		res, body := getRaw("/leagues/nfl/buffalo-bills", http.Header{"Accept": {"text/css"}})
+		g.Expect(string(body)).Should(gomega.HavePrefix(":root {"))
+		res, body = getRaw("/leagues/nfl/buffalo-bills", http.Header{"Accept": {"application/json"}})
+		g.Expect(string(body)).Should(gomega.HavePrefix("{"))
+		g.Expect(c.responseCache().order.Len()).Should(gomega.Equal(4))
+
+		// and cached responses still say they vary by it
+		res, _ = getRaw("/leagues/nfl/buffalo-bills", http.Header{"Accept": {"text/css"}})
+		g.Expect(res.Header["Vary"]).Should(gomega.Equal([]string{"Accept", "Accept-Encoding"}))
+	})
+}
+
+func TestResponseCacheLimits(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	s, err := model.NewMemoryStore(time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC), &model.Team{
+		Name:   "Buffalo Bandits",
+		League: "NLL",
+		Eras:   []*model.Era{{Year: 1992, Colors: []*model.Color{{Name: "Orange", Hex: "#F47A38"}}}},
+	})
+	g.Expect(err).Should(gomega.BeNil())
+
+	rc := newResponseCache(s)
+	body := bytes.Repeat([]byte("a"), maxCachedBytes/4)
+
+	// the compressed copies count against the limit
+	res := newCachedResponse("0", nil, body)
+	g.Expect(res.compressed).Should(gomega.HaveKey("br"))
+	g.Expect(res.compressed).Should(gomega.HaveKey("gzip"))
+	g.Expect(res.size).Should(gomega.Equal(len(body) + len(res.compressed["br"]) + len(res.compressed["gzip"])))
+
+	rc.add(res)
+	for i := 1; i < 3; i++ {
+		rc.add(newCachedResponse(fmt.Sprint(i), nil, body))
+	}
+	g.Expect(rc.order.Len()).Should(gomega.Equal(3))
+	g.Expect(rc.size).Should(gomega.Equal(3 * res.size))
+
+	rc.add(newCachedResponse("3", nil, body))
+	g.Expect(rc.order.Len()).Should(gomega.Equal(3))
+	g.Expect(rc.size).Should(gomega.Equal(3 * res.size))
+	_, ok := rc.get("0")
+	g.Expect(ok).Should(gomega.BeFalse())
+
+	// small bodies aren't compressed
+	small := newCachedResponse("small", nil, []byte("{}"))
+	g.Expect(small.compressed).Should(gomega.BeEmpty())
+	g.Expect(small.size).Should(gomega.Equal(2))
+}
+
+func TestCachedResponsesReplacedWithStore(t *testing.T) {
+	runWithSetupAndTeardown(t, func() {
+		c := New(s, "v1.0.0")
+		ts.Config.Handler = c
+
+		res, _ := getRaw("/leagues", nil)
+		etag := res.Header.Get("ETag")
+
+		newStore, err := model.NewMemoryStore(time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC), &model.Team{
+			Name:   "Buffalo Bandits",
+			League: "NLL",
+			Eras:   []*model.Era{{Year: 1992, Colors: []*model.Color{{Name: "Orange", Hex: "#F47A38"}}}},
+		})
+		must(err)
+		c.SetStore(newStore)
+
+		res, body := getRaw("/leagues", http.Header{"If-None-Match": {etag}})
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(res.Header.Get("Last-Modified")).Should(gomega.Equal("Sun, 01 Mar 2020 00:00:00 GMT"))
+		g.Expect(string(body)).Should(gomega.Equal(`[{"league":"NLL","_link":"/leagues/nll"}]` + "\n"))
+	})
+}
+
+// erasStore is a store whose eras last changed on a set day
+type erasStore struct {
+	model.Store
+	changed model.Date
+}
+
+func (s *erasStore) LastEraChange(model.Date) model.Date {
+	return s.changed
+}
+
+func TestCachedResponsesReplacedWhenErasChange(t *testing.T) {
+	runWithSetupAndTeardown(t, func() {
+		es := &erasStore{Store: s, changed: s.LastEraChange(model.Today())}
+		c := New(es, "v1.0.0")
+		ts.Config.Handler = c
+
+		res, _ := getRaw("/leagues", nil)
+		g.Expect(res.Header.Get("Last-Modified")).Should(gomega.Equal("Sat, 22 Feb 2020 12:00:00 GMT"))
+		cache := c.responseCache()
+		g.Expect(cache.order.Len()).Should(gomega.Equal(1))
+
+		// an era that starts after the data was generated becomes current
+		es.changed = model.NewDate(2020, time.September, 1)
+		res, _ = getRaw("/leagues", http.Header{"If-Modified-Since": {"Sat, 22 Feb 2020 12:00:00 GMT"}})
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(res.Header.Get("Last-Modified")).Should(gomega.Equal("Tue, 01 Sep 2020 00:00:00 GMT"))
+		g.Expect(c.responseCache()).ShouldNot(gomega.BeIdenticalTo(cache))
+
+		res, _ = getRaw("/leagues", http.Header{"If-Modified-Since": {"Tue, 01 Sep 2020 00:00:00 GMT"}})
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusNotModified))
+	})
+}
+
+func TestAcceptsEncoding(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	tests := []struct {
+		header   string
+		expected bool
+	}{
+		{"", false},
+		{"gzip", true},
+		{"deflate, GZIP", true},
+		{"gzip;q=0", false},
+		{"gzip; q=0.5", true},
+		{"*", true},
+		{"*;q=0", false},
+		{"*, gzip;q=0", false},
+		{"br", false},
+	}
+
+	for _, test := range tests {
+		g.Expect(acceptsEncoding(test.header, "gzip")).Should(gomega.Equal(test.expected), test.header)
+	}
+}
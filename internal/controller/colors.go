@@ -0,0 +1,110 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/weters/teamhex/internal/model"
+)
+
+const (
+	defaultNearestLimit = 10
+	maxNearestLimit     = 100
+)
+
+// Successful response
+// swagger:response nearestColorsResponse
+type nearestColorsResponse []*model.ColorMatch
+
+// swagger:operation GET /colors/nearest colors getNearestColors
+//
+// Find the team colors closest to a color
+//
+// This endpoint returns the colors in each team's current era that are perceptually closest to the provided color,
+// closest first. The distance is the CIEDE2000 color difference; under 2 is hard to tell apart and over 10 is
+// clearly distinct.
+//
+// ---
+// produces:
+// - application/json
+// parameters:
+// - name: hex
+//   in: query
+//   description: The color to match in the form #RRGGBB. The leading # is optional.
+//   required: true
+//   type: string
+// - name: limit
+//   in: query
+//   description: The maximum number of colors to return
+//   required: false
+//   type: integer
+//   minimum: 1
+//   maximum: 100
+//   default: 10
+// - name: league
+//   in: query
+//   description: Only search teams in the league
+//   required: false
+//   type: string
+// responses:
+//   '200':
+//     '$ref': '#/responses/nearestColorsResponse'
+//   '400':
+//     '$ref': '#/responses/errorResponse'
+//   '404':
+//     '$ref': '#/responses/errorResponse'
+func (c *Controller) getColorsNearest() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hex := r.FormValue("hex")
+		if len(hex) == 0 {
+			serveJSONError(w, http.StatusBadRequest, errors.New("hex is required"))
+			return
+		}
+
+		if !strings.HasPrefix(hex, "#") {
+			hex = "#" + hex
+		}
+
+		if _, err := model.ParseHex(hex); err != nil {
+			serveJSONError(w, http.StatusBadRequest, errors.New("hex must be in the form #RRGGBB"))
+			return
+		}
+
+		limit := defaultNearestLimit
+		if l := r.FormValue("limit"); len(l) > 0 {
+			var err error
+			limit, err = strconv.Atoi(l)
+			if err != nil || limit < 1 || limit > maxNearestLimit {
+				serveJSONError(w, http.StatusBadRequest, fmt.Errorf("limit must be a number from 1 to %d", maxNearestLimit))
+				return
+			}
+		}
+
+		matches, err := c.Store().NearestColors(hex, r.FormValue("league"), limit)
+		if err != nil {
+			serveModelError(w, err)
+			return
+		}
+
+		serveJSON(w, http.StatusOK, matches)
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+func TestGetNearestColors(t *testing.T) {
+	runWithSetupAndTeardown(t, func() {
+		res, body := getBody("/colors/nearest?hex=%23C8102F&limit=2", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(body).Should(gomega.MatchJSON(`[
+			{
+				"team": "Buffalo Bills",
+				"league": "NFL",
+				"color": { "name": "Scarlet Red", "hex": "#C8102E" },
+				"distance": 0.28,
+				"_link": "/leagues/nfl/buffalo-bills"
+			},
+			{
+				"team": "The Ohio State University",
+				"league": "NCAA",
+				"color": { "name": "Scarlet", "hex": "#BA0C2F" },
+				"distance": 3.25,
+				"_link": "/leagues/ncaa/the-ohio-state-university"
+			}
+		]`))
+
+		res, body = getBody("/colors/nearest?hex=C8102F&league=nhl", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(body).Should(gomega.ContainSubstring(`"team":"Buffalo Sabres"`))
+
+		res, body = getBody("/colors/nearest?hex=C8102F&league=bad", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusNotFound))
+		g.Expect(body).Should(gomega.Equal(`{"message":"league not found"}` + "\n"))
+
+		for _, query := range []string{"", "hex=red", "hex=%23FFF", "hex=FFFFFF&limit=0", "hex=FFFFFF&limit=101", "hex=FFFFFF&limit=x"} {
+			res, _ = getBody("/colors/nearest?"+query, nil)
+			g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusBadRequest), query)
+		}
+	})
+}
@@ -26,6 +26,18 @@ limitations under the License.
 // Produces:
 // - application/json
 //
+// SecurityDefinitions:
+// bearer:
+//   type: apiKey
+//   in: header
+//   name: Authorization
+//   description: An API key sent as "Bearer <key>". Only needed to change teams.
+// apiKey:
+//   type: apiKey
+//   in: header
+//   name: X-API-Key
+//   description: An API key. Only needed to change teams.
+//
 // swagger:meta
 package controller
 
@@ -33,6 +45,9 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -40,34 +55,180 @@ import (
 	"github.com/weters/teamhex/internal/model"
 )
 
+var errInvalidYear = errors.New("year must be a number")
+var errInvalidDate = errors.New("date must be in the form YYYY-MM-DD")
+var errInvalidRole = errors.New("role must be one of " + strings.Join(model.Roles, ", "))
+
+//errNoColors is returned when a team has no era for the query parameters that were used to find one
+type errNoColors struct {
+	params []string
+}
+
+func (e *errNoColors) Error() string {
+	return "no colors found for " + strings.Join(e.params, " and ")
+}
+
+//noColorsFor replaces ErrEraNotFound with an error naming the query parameters the era was looked up by. param is
+//year or date, or empty if the era was only looked up by its label.
+func noColorsFor(err error, param, label string) error {
+	if err != model.ErrEraNotFound {
+		return err
+	}
+
+	e := &errNoColors{}
+	if len(param) > 0 {
+		e.params = append(e.params, param)
+	}
+	if len(label) > 0 {
+		e.params = append(e.params, "label")
+	}
+
+	return e
+}
+
 //Controller provides capabilities for handling HTTP requests
 type Controller struct {
 	*mux.Router
-	model   *model.Model
-	version string
+	mu sync.RWMutex
+	// replaceMu is held from reading the data until its store is served, so
+	// stores are served in the order their data was read
+	replaceMu sync.Mutex
+	store     model.Store
+	version   string
+	// updater is nil unless writes are enabled
+	updater model.Updater
+	// cache holds the responses for store and is replaced with it, or when
+	// its current eras change
+	cache *responseCache
+	// cachedQueries has the query parameters read by each cached route,
+	// keyed by its path template
+	cachedQueries map[string][]string
+	metrics       *controllerMetrics
 }
 
+// Query parameters read by the handlers that serve teams
+var (
+	teamQueries = []string{"year", "date", "label", "role", "formats"}
+	listQueries = []string{"sort", "page", "per_page", "fields", "formats"}
+)
+
 //New returns a new instance of the controller
 //This instance implements the methods required of an HTTP handler
-func New(m *model.Model, version string) *Controller {
+func New(s model.Store, version string) *Controller {
 	c := Controller{
-		model:   m,
-		version: version,
+		store:         s,
+		version:       version,
+		cache:         newResponseCache(s),
+		cachedQueries: make(map[string][]string),
 	}
 
+	c.metrics = newControllerMetrics(&c)
+
 	router := mux.NewRouter()
+	router.Use(c.instrument, c.cacheResponses)
+	router.NotFoundHandler = c.instrument(http.NotFoundHandler())
+	router.MethodNotAllowedHandler = c.instrument(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
 	c.Router = router
 
-	router.Methods(http.MethodGet).Path("/").Handler(c.getRoot())
-	router.Methods(http.MethodGet).Path("/swagger.json").Handler(c.getSwaggerJSON())
-	router.Methods(http.MethodGet).Path("/teams").Handler(c.getTeams())
-	router.Methods(http.MethodGet).Path("/leagues").Handler(c.getLeagues())
-	router.Methods(http.MethodGet).Path("/leagues/{league:[^/]+}").Handler(c.getLeaguesLeague())
-	router.Methods(http.MethodGet).Path("/leagues/{league:[^/]+}/{team:[^/]+}").Handler(c.getLeaguesLeagueTeam())
+	c.get("/", c.getRoot())
+	c.get("/swagger.json", c.getSwaggerJSON())
+	c.get("/teams", c.getTeams(), append([]string{"league", "search"}, listQueries...)...)
+	c.get("/teams/{id:[0-9]+}", c.getTeamsTeam(), teamQueries...)
+	c.get("/leagues", c.getLeagues())
+	c.get("/colors/nearest", c.getColorsNearest(), "hex", "limit", "league")
+	c.get("/autocomplete", c.getAutocomplete(), "q", "limit")
+	c.get("/matchup", c.getMatchup(), "home", "away")
+	c.get("/leagues/{league:[^/]+}.{format:"+stylesheetFormats+"}", c.getLeaguesLeagueStylesheet())
+	c.get("/leagues/{league:[^/]+}", c.getLeaguesLeague(), listQueries...)
+	c.get("/leagues/{league:[^/]+}/divisions", c.getLeaguesLeagueDivisions())
+	c.get("/leagues/{league:[^/]+}/divisions/{division:[^/]+}", c.getLeaguesLeagueDivisionsDivision(), listQueries...)
+	c.get("/leagues/{league:[^/]+}/{team:[^/]+}.{format:"+stylesheetFormats+"}", c.canonicalTeam(c.getLeaguesLeagueTeamStylesheet()), "year", "date", "label", "role")
+	c.get("/leagues/{league:[^/]+}/{team:[^/]+}", c.canonicalTeam(c.getLeaguesLeagueTeam()), teamQueries...)
+	c.get("/leagues/{league:[^/]+}/{team:[^/]+}/swatch.svg", c.canonicalTeam(c.getLeaguesLeagueTeamSwatch()), "year", "date", "label", "layout", "size", "labels")
+	c.get("/leagues/{league:[^/]+}/{team:[^/]+}/accessibility", c.canonicalTeam(c.getLeaguesLeagueTeamAccessibility()), "year", "date", "label")
+	c.get("/leagues/{league:[^/]+}/{team:[^/]+}/lineage", c.canonicalTeam(c.getLeaguesLeagueTeamLineage()), "year")
 
 	return &c
 }
 
+//get registers a GET route whose successful responses are cached. queries must
+//list every query parameter the handler reads, as only those are part of the
+//cache key.
+func (c *Controller) get(path string, h http.Handler, queries ...string) {
+	c.Router.Methods(http.MethodGet).Path(path).Handler(h)
+	c.cachedQueries[path] = queries
+}
+
+//canonicalTeam permanently redirects a request for a team by anything but its
+//slug, such as its name or a former name, to the same resource at the team's
+//canonical URL
+func (c *Controller) canonicalTeam(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		team, err := c.Store().TeamByLeagueAndName(vars["league"], vars["team"])
+		if err != nil || vars["team"] == team.Slug {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		pairs := make([]string, 0, 2*len(vars))
+		for name, value := range vars {
+			switch name {
+			case "league":
+				value = strings.ToLower(team.League)
+			case "team":
+				value = team.Slug
+			}
+			pairs = append(pairs, name, value)
+		}
+
+		u, err := mux.CurrentRoute(r).URL(pairs...)
+		if err != nil {
+			serveJSONError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		u.RawQuery = r.URL.RawQuery
+		http.Redirect(w, r, u.String(), http.StatusMovedPermanently)
+	})
+}
+
+//Store returns the store currently used to serve requests
+func (c *Controller) Store() model.Store {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.store
+}
+
+//SetStore replaces the store used to serve requests
+//It is safe to call while requests are being served. Requests already in
+//progress finish with the store they started with. Cached responses from the
+//previous store are dropped.
+func (c *Controller) SetStore(s model.Store) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.store = s
+	c.cache = newResponseCache(s)
+}
+
+//ReplaceStore opens a store with open and serves it. It waits for writes in
+//progress, so a store opened before a write is saved can't replace the store
+//with the write.
+func (c *Controller) ReplaceStore(open func() (model.Store, error)) (model.Store, error) {
+	c.replaceMu.Lock()
+	defer c.replaceMu.Unlock()
+
+	s, err := open()
+	if err != nil {
+		return nil, err
+	}
+
+	c.SetStore(s)
+	return s, nil
+}
+
 // Successful response
 // swagger:response rootResponse
 type rootResponse struct {
@@ -88,17 +249,16 @@ type rootResponse struct {
 // Responses:
 //   200: rootResponse
 func (c *Controller) getRoot() http.HandlerFunc {
-	resp := rootResponse{
-		Version:        c.version,
-		GenerationDate: c.model.GenerationDate(),
-		Links: []string{
-			"/teams{?search}",
-			"/leagues",
-		},
-	}
-
 	return func(w http.ResponseWriter, r *http.Request) {
-		serveJSON(w, http.StatusOK, resp)
+		serveJSON(w, http.StatusOK, rootResponse{
+			Version:        c.version,
+			GenerationDate: c.Store().GenerationDate(),
+			Links: []string{
+				"/teams{?search,league}",
+				"/leagues",
+				"/autocomplete{?q,limit}",
+			},
+		})
 	}
 }
 
@@ -119,7 +279,7 @@ type leaguesResponse []*model.LeagueRecord
 //   200: leaguesResponse
 func (c *Controller) getLeagues() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		serveJSON(w, http.StatusOK, c.model.Leagues())
+		serveJSON(w, http.StatusOK, c.Store().Leagues())
 	}
 }
 
@@ -131,7 +291,10 @@ type teamsResponse model.Teams
 //
 // Get all teams, or teams filtered by a search query
 //
-// By default, this endpoint will return all teams. You can search using the search query parameter.
+// By default, this endpoint will return all teams. You can search using the search query parameter, which matches
+// team names and aliases such as abbreviations and nicknames, and tolerates typos. Search results are ordered best
+// match first and include a score from 0 to 1. The teams can be sorted, paginated and limited to selected fields, and
+// each color can be given in other formats such as HSL and CMYK.
 //
 // ---
 // produces:
@@ -142,17 +305,74 @@ type teamsResponse model.Teams
 //   description: Search for the specified team
 //   required: false
 //   type: string
+// - name: league
+//   in: query
+//   description: Only return teams in the league
+//   required: false
+//   type: string
+// - name: sort
+//   in: query
+//   description: Sort by name, league or division. Prefix with - for descending order.
+//   required: false
+//   type: string
+// - name: page
+//   in: query
+//   description: Return one page of teams. Link headers point to the other pages.
+//   required: false
+//   type: integer
+//   minimum: 1
+// - name: per_page
+//   in: query
+//   description: The number of teams per page
+//   required: false
+//   type: integer
+//   minimum: 1
+//   maximum: 100
+//   default: 50
+// - name: fields
+//   in: query
+//   description: Only return these comma-separated fields, e.g. name,league,eras.colors
+//   required: false
+//   type: string
+// - name: formats
+//   in: query
+//   description: Add each color in these comma-separated formats, from rgb, hsl, cmyk, lab and oklch, or all
+//   required: false
+//   type: string
 // responses:
 //   '200':
 //     '$ref': '#/responses/teamsResponse'
+//   '400':
+//     '$ref': '#/responses/errorResponse'
+//   '404':
+//     '$ref': '#/responses/errorResponse'
 func (c *Controller) getTeams() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if s := r.FormValue("search"); len(s) > 0 {
-			serveJSON(w, http.StatusOK, c.model.Search(s))
+		s := c.Store()
+		league := r.FormValue("league")
+		if q := r.FormValue("search"); len(q) > 0 {
+			results, err := s.SearchRanked(q, league)
+			if err != nil {
+				serveModelError(w, err)
+				return
+			}
+
+			serveTeamList(w, r, searchResultsList(results))
 			return
 		}
 
-		serveJSON(w, http.StatusOK, c.model.AllTeams())
+		if len(league) > 0 {
+			teams, err := s.TeamsByLeague(league)
+			if err != nil {
+				serveModelError(w, err)
+				return
+			}
+
+			serveTeams(w, r, teams)
+			return
+		}
+
+		serveTeams(w, r, s.AllTeams())
 	}
 }
 
@@ -160,19 +380,53 @@ func (c *Controller) getTeams() http.HandlerFunc {
 //
 // Get all teams in a league
 //
-// This endpoint returns a list of teams found in a provided league.
+// This endpoint returns a list of teams found in a provided league. Requesting text/css or text/x-scss in the Accept
+// header returns a stylesheet of every team's current colors instead.
 //
 // ---
 // produces:
 // - application/json
+// - text/css
+// - text/x-scss
 // parameters:
 // - in: path
 //   name: league
 //   required: true
 //   type: string
+// - name: sort
+//   in: query
+//   description: Sort by name, league or division. Prefix with - for descending order.
+//   required: false
+//   type: string
+// - name: page
+//   in: query
+//   description: Return one page of teams. Link headers point to the other pages.
+//   required: false
+//   type: integer
+//   minimum: 1
+// - name: per_page
+//   in: query
+//   description: The number of teams per page
+//   required: false
+//   type: integer
+//   minimum: 1
+//   maximum: 100
+//   default: 50
+// - name: fields
+//   in: query
+//   description: Only return these comma-separated fields, e.g. name,league,eras.colors
+//   required: false
+//   type: string
+// - name: formats
+//   in: query
+//   description: Add each color in these comma-separated formats, from rgb, hsl, cmyk, lab and oklch, or all
+//   required: false
+//   type: string
 // responses:
 //   '200':
 //     '$ref': '#/responses/teamsResponse'
+//   '400':
+//     '$ref': '#/responses/errorResponse'
 //   '404':
 //     '$ref': '#/responses/errorResponse'
 //   '500':
@@ -180,17 +434,18 @@ func (c *Controller) getTeams() http.HandlerFunc {
 func (c *Controller) getLeaguesLeague() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		league := mux.Vars(r)["league"]
-		teams, err := c.model.TeamsByLeague(league)
+		teams, err := c.Store().TeamsByLeague(league)
 		if err != nil {
-			if err == model.ErrLeagueNotFound {
-				serveJSONError(w, http.StatusNotFound, errors.New("league not found"))
-				return
-			}
+			serveModelError(w, err)
+			return
+		}
 
-			serveJSONError(w, http.StatusInternalServerError, err)
+		if format := stylesheetFormat(w, r); format != "" {
+			serveLeagueStylesheet(w, format, teams)
 			return
 		}
-		serveJSON(w, http.StatusOK, teams)
+
+		serveTeams(w, r, teams)
 	}
 }
 
@@ -202,11 +457,16 @@ type teamResponse *model.Team
 //
 // Get a single team in a provided league
 //
-// This endpoint returns a list of teams found in a provided league.
+// This endpoint returns a single team found in a provided league by its slug, e.g. arizona-cardinals. Asking for
+// the team by its name or a former name redirects to its canonical URL. If a year is provided, only the era in effect
+// for that year is returned. Requesting text/css or text/x-scss in the Accept header returns a stylesheet of the
+// team's colors instead.
 //
 // ---
 // produces:
 // - application/json
+// - text/css
+// - text/x-scss
 // parameters:
 // - in: path
 //   name: league
@@ -216,9 +476,39 @@ type teamResponse *model.Team
 //   name: team
 //   required: true
 //   type: string
+// - name: year
+//   in: query
+//   description: Only return the era in effect for the year
+//   required: false
+//   type: integer
+// - name: date
+//   in: query
+//   description: Only return the era in effect on the day, as YYYY-MM-DD
+//   required: false
+//   type: string
+// - name: label
+//   in: query
+//   description: Only return eras with the label, such as primary, alternate, throwback or city edition
+//   required: false
+//   type: string
+// - name: role
+//   in: query
+//   description: Only return colors with the role, leaving out eras without one
+//   required: false
+//   type: string
+//   enum: [primary, secondary, accent, text, background]
+// - name: formats
+//   in: query
+//   description: Add each color in these comma-separated formats, from rgb, hsl, cmyk, lab and oklch, or all
+//   required: false
+//   type: string
 // responses:
 //   '200':
 //     '$ref': '#/responses/teamResponse'
+//   '301':
+//     description: The team was asked for by its name or a former name. Location is its canonical URL.
+//   '400':
+//     '$ref': '#/responses/errorResponse'
 //   '404':
 //     '$ref': '#/responses/errorResponse'
 //   '500':
@@ -227,21 +517,236 @@ func (c *Controller) getLeaguesLeagueTeam() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		leagueName := mux.Vars(r)["league"]
 		teamName := mux.Vars(r)["team"]
-		team, err := c.model.TeamByLeagueAndName(leagueName, teamName)
+		team, err := c.Store().TeamByLeagueAndName(leagueName, teamName)
 		if err != nil {
-			if err == model.ErrLeagueNotFound {
-				serveJSONError(w, http.StatusNotFound, errors.New("league not found"))
-				return
-			} else if err == model.ErrTeamNotFound {
-				serveJSONError(w, http.StatusNotFound, errors.New("team not found"))
-				return
-			}
+			serveModelError(w, err)
+			return
+		}
 
+		serveTeam(w, r, team)
+	}
+}
+
+// swagger:operation GET /teams/{id} teams getTeamByID
+//
+// Get a single team by its ID
+//
+// A team's ID never changes, so unlike its name or slug it can be stored as a permanent reference to the team.
+//
+// ---
+// produces:
+// - application/json
+// - text/css
+// - text/x-scss
+// parameters:
+// - in: path
+//   name: id
+//   required: true
+//   type: integer
+// - name: year
+//   in: query
+//   description: Only return the era in effect for the year
+//   required: false
+//   type: integer
+// - name: date
+//   in: query
+//   description: Only return the era in effect on the day, as YYYY-MM-DD
+//   required: false
+//   type: string
+// - name: label
+//   in: query
+//   description: Only return eras with the label, such as primary, alternate, throwback or city edition
+//   required: false
+//   type: string
+// - name: role
+//   in: query
+//   description: Only return colors with the role, leaving out eras without one
+//   required: false
+//   type: string
+//   enum: [primary, secondary, accent, text, background]
+// - name: formats
+//   in: query
+//   description: Add each color in these comma-separated formats, from rgb, hsl, cmyk, lab and oklch, or all
+//   required: false
+//   type: string
+// responses:
+//   '200':
+//     '$ref': '#/responses/teamResponse'
+//   '400':
+//     '$ref': '#/responses/errorResponse'
+//   '404':
+//     '$ref': '#/responses/errorResponse'
+//   '500':
+//     '$ref': '#/responses/errorResponse'
+func (c *Controller) getTeamsTeam() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			serveModelError(w, model.ErrTeamNotFound)
+			return
+		}
+
+		team, err := c.Store().TeamByID(id)
+		if err != nil {
+			serveModelError(w, err)
+			return
+		}
+
+		serveTeam(w, r, team)
+	}
+}
+
+//serveTeam serves a team as JSON or a stylesheet. If a year or date is asked
+//for, only the era in effect then is served, and if a label is asked for,
+//only eras with the label. If a role is asked for, only colors with the role
+//are served.
+func serveTeam(w http.ResponseWriter, r *http.Request, team *model.Team) {
+	if format := stylesheetFormat(w, r); format != "" {
+		serveTeamStylesheet(w, r, format, team)
+		return
+	}
+
+	if len(r.FormValue("year")) > 0 || len(r.FormValue("date")) > 0 {
+		era, err := eraForRequest(r, team)
+		if err != nil {
+			serveModelError(w, err)
+			return
+		}
+
+		teamAtYear := *team
+		teamAtYear.Eras = []*model.Era{era}
+		team = &teamAtYear
+	} else if label := r.FormValue("label"); len(label) > 0 {
+		eras := team.ErasWithLabel(label)
+		if len(eras) == 0 {
+			serveModelError(w, noColorsFor(model.ErrEraNotFound, "", label))
+			return
+		}
+
+		labelled := *team
+		labelled.Eras = eras
+		team = &labelled
+	}
+
+	team, err := teamWithRole(r, team)
+	if err != nil {
+		serveModelError(w, err)
+		return
+	}
+
+	formats, err := parseColorFormats(r)
+	if err != nil {
+		serveJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if formats != nil {
+		if team, err = team.WithColorFormats(formats); err != nil {
 			serveJSONError(w, http.StatusInternalServerError, err)
 			return
 		}
-		serveJSON(w, http.StatusOK, team)
 	}
+
+	serveJSON(w, http.StatusOK, team)
+}
+
+//eraForRequest returns the era in effect for the date or year query parameter, or the current era if neither is
+//provided. The era has the label query parameter if one is provided, otherwise it is primary.
+func eraForRequest(r *http.Request, team *model.Team) (*model.Era, error) {
+	label := r.FormValue("label")
+	if d := r.FormValue("date"); len(d) > 0 {
+		date, err := model.ParseDate(d)
+		if err != nil {
+			return nil, errInvalidDate
+		}
+
+		era, err := team.EraOn(date, label)
+		return era, noColorsFor(err, "date", label)
+	}
+
+	y := r.FormValue("year")
+	if len(y) == 0 {
+		if len(label) == 0 {
+			return team.CurrentEra(), nil
+		}
+
+		if era, err := team.EraOn(model.Today(), label); err == nil {
+			return era, nil
+		}
+
+		// a label no longer in use, such as a throwback, has its newest era
+		eras := team.ErasWithLabel(label)
+		if len(eras) == 0 {
+			return nil, noColorsFor(model.ErrEraNotFound, "", label)
+		}
+
+		return eras[0], nil
+	}
+
+	year, err := strconv.Atoi(y)
+	if err != nil {
+		return nil, errInvalidYear
+	}
+
+	era, err := team.EraIn(year, label)
+	return era, noColorsFor(err, "year", label)
+}
+
+//eraWithRole returns a copy of the era with only the colors with the role query parameter, or the era itself if no
+//role is asked for. ErrColorNotFound is returned if no color has the role.
+func eraWithRole(r *http.Request, era *model.Era) (*model.Era, error) {
+	role := strings.ToLower(r.FormValue("role"))
+	if len(role) == 0 {
+		return era, nil
+	}
+
+	if !model.IsRole(role) {
+		return nil, errInvalidRole
+	}
+
+	colors := era.ColorsByRole(role)
+	if len(colors) == 0 {
+		return nil, model.ErrColorNotFound
+	}
+
+	copied := *era
+	copied.Colors = colors
+	return &copied, nil
+}
+
+//teamWithRole returns a copy of the team with only the colors with the role query parameter, leaving out eras without
+//any. The team itself is returned if no role is asked for.
+func teamWithRole(r *http.Request, team *model.Team) (*model.Team, error) {
+	if len(r.FormValue("role")) == 0 {
+		return team, nil
+	}
+
+	var eras []*model.Era
+	for _, era := range team.Eras {
+		withRole, err := eraWithRole(r, era)
+		if err == model.ErrColorNotFound {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		eras = append(eras, withRole)
+	}
+
+	if len(eras) == 0 {
+		return nil, model.ErrColorNotFound
+	}
+
+	copied := *team
+	copied.Eras = eras
+	return &copied, nil
+}
+
+//stylesheetFormat returns the stylesheet format negotiated from the Accept header, which the response varies by
+//An empty string is returned if JSON should be served.
+func stylesheetFormat(w http.ResponseWriter, r *http.Request) string {
+	w.Header().Add("Vary", "Accept")
+	return negotiateStylesheet(r)
 }
 
 func (c *Controller) getSwaggerJSON() http.HandlerFunc {
@@ -265,6 +770,42 @@ type errorResponse struct {
 	Message string `json:"message"`
 }
 
+//serveModelError translates errors returned by the model into the appropriate response
+func serveModelError(w http.ResponseWriter, err error) {
+	var noColors *errNoColors
+	if errors.As(err, &noColors) {
+		serveJSONError(w, http.StatusNotFound, noColors)
+		return
+	}
+
+	switch err {
+	case model.ErrLeagueNotFound:
+		serveJSONError(w, http.StatusNotFound, errors.New("league not found"))
+	case model.ErrTeamNotFound:
+		serveJSONError(w, http.StatusNotFound, errors.New("team not found"))
+	case model.ErrDivisionNotFound:
+		serveJSONError(w, http.StatusNotFound, errors.New("division not found"))
+	case model.ErrEraNotFound:
+		serveJSONError(w, http.StatusNotFound, errors.New("era not found"))
+	case model.ErrColorNotFound:
+		serveJSONError(w, http.StatusNotFound, errors.New("color not found"))
+	case model.ErrTeamExists:
+		serveJSONError(w, http.StatusConflict, errors.New("team already exists"))
+	case model.ErrEraExists:
+		serveJSONError(w, http.StatusConflict, errors.New("era already exists"))
+	case model.ErrEraAmbiguous:
+		serveJSONError(w, http.StatusConflict, errors.New("more than one era started in the year, give the day it started instead, e.g. 2011-09-11"))
+	case model.ErrIDChanged:
+		serveJSONError(w, http.StatusBadRequest, errors.New("a team's id cannot be changed"))
+	case model.ErrIDUsed:
+		serveJSONError(w, http.StatusConflict, errors.New("id has already been used"))
+	case errInvalidYear, errInvalidDate, errInvalidRole:
+		serveJSONError(w, http.StatusBadRequest, err)
+	default:
+		serveJSONError(w, http.StatusInternalServerError, err)
+	}
+}
+
 func serveJSONError(w http.ResponseWriter, statusCode int, err error) {
 	var msg string
 	if err != nil {
@@ -18,6 +18,7 @@ package controller
 
 import (
 	"encoding/json"
+	"errors"
 	"github.com/onsi/gomega"
 	"github.com/weters/teamhex/internal/model"
 	"io/ioutil"
@@ -27,16 +28,68 @@ import (
 	"time"
 )
 
-const testFile = "./testdata/teamhex.json"
-
 var g *gomega.GomegaWithT
-var m *model.Model
+var s *model.Model
 var ts *httptest.Server
 
+var testGenerated = time.Date(2020, 2, 22, 12, 0, 0, 0, time.UTC)
+
+func newTestTeams() model.Teams {
+	return model.Teams{
+		{
+			ID:       1,
+			Name:     "The Ohio State University",
+			League:   "NCAA",
+			Division: "Big Ten Conference",
+			Eras: []*model.Era{
+				{Year: 2004, Colors: []*model.Color{{Name: "Scarlet", Hex: "#BA0C2F"}}},
+			},
+		},
+		{
+			ID:       2,
+			Name:     "Buffalo Bills",
+			League:   "NFL",
+			Division: "AFC",
+			Eras: []*model.Era{
+				{Year: 2011, Colors: []*model.Color{{Name: "Royal Blue", Hex: "#003087"}, {Name: "Scarlet Red", Hex: "#C8102E"}}},
+				{Year: 2002, Colors: []*model.Color{{Name: "Midnight Navy", Hex: "#091F2C"}}},
+			},
+		},
+		{
+			ID:       3,
+			Name:     "University At Buffalo, The State University Of New York",
+			League:   "NCAA",
+			Division: "Mid-American Conference",
+			Eras: []*model.Era{
+				{Year: 2016, Colors: []*model.Color{{Name: "Royal Blue", Hex: "#0057B7"}, {Name: "White", Hex: "#FFFFFF"}}},
+			},
+		},
+		{
+			ID:     19,
+			Name:   "Buffalo Sabres",
+			League: "NHL",
+			Eras: []*model.Era{
+				{Year: 2010, Colors: []*model.Color{{Name: "Navy", Hex: "#041E42"}}},
+			},
+		},
+	}
+}
+
+func newTestStore() *model.Model {
+	store, err := model.NewMemoryStore(testGenerated, newTestTeams()...)
+	must(err)
+
+	return store
+}
+
 func runWithSetupAndTeardown(t *testing.T, tests func()) {
 	g = gomega.NewWithT(t)
-	m, _ = model.New(testFile)
-	c := New(m, "v1.0.0")
+
+	var err error
+	s, err = model.NewMemoryStore(testGenerated, newTestTeams()...)
+	g.Expect(err).ShouldNot(gomega.HaveOccurred())
+
+	c := New(s, "v1.0.0")
 	ts = httptest.NewServer(c)
 	defer ts.Close()
 
@@ -59,8 +112,9 @@ func TestGetRoot(t *testing.T) {
 			Version:        "v1.0.0",
 			GenerationDate: time.Date(2020, 2, 22, 12, 0, 0, 0, time.UTC),
 			Links: []string{
-				"/teams{?search}",
+				"/teams{?search,league}",
 				"/leagues",
+				"/autocomplete{?q,limit}",
 			},
 		})))
 	})
@@ -88,10 +142,13 @@ func TestGetLeagues(t *testing.T) {
 func TestGetTeamsByLeague(t *testing.T) {
 	expected := `[
     {
+      "id": 1,
       "name": "The Ohio State University",
+      "slug": "the-ohio-state-university",
       "eras": [
         {
           "year": 2004,
+          "start": "2004-01-01",
           "colors": [
             { "name": "Scarlet", "hex": "#BA0C2F" }
           ]
@@ -99,13 +156,16 @@ func TestGetTeamsByLeague(t *testing.T) {
       ],
       "league": "NCAA",
       "division": "Big Ten Conference",
-      "_link": "/leagues/ncaa/the%20ohio%20state%20university"
+      "_link": "/leagues/ncaa/the-ohio-state-university"
     },
     {
+      "id": 3,
       "name": "University At Buffalo, The State University Of New York",
+      "slug": "university-at-buffalo-the-state-university-of-new-york",
       "eras": [
         {
           "year": 2016,
+          "start": "2016-01-01",
           "colors": [
             { "name": "Royal Blue", "hex": "#0057B7" },
             { "name": "White", "hex": "#FFFFFF" }
@@ -114,7 +174,7 @@ func TestGetTeamsByLeague(t *testing.T) {
       ],
       "league": "NCAA",
       "division": "Mid-American Conference",
-      "_link": "/leagues/ncaa/university%20at%20buffalo%2C%20the%20state%20university%20of%20new%20york"
+      "_link": "/leagues/ncaa/university-at-buffalo-the-state-university-of-new-york"
     }
 ]`
 
@@ -145,15 +205,18 @@ func TestGetTeamsByLeagueWithLeagueNotFound(t *testing.T) {
 func TestGetTeamByLeagueAndName(t *testing.T) {
 	expected := `
     {
+      "id": 19,
       "name": "Buffalo Sabres",
+      "slug": "buffalo-sabres",
       "eras": [
         {
           "year": 2010,
+          "start": "2010-01-01",
           "colors": [ { "name": "Navy", "hex": "#041E42" } ]
         }
       ],
       "league": "NHL",
-	  "_link": "/leagues/nhl/buffalo%20sabres"
+	  "_link": "/leagues/nhl/buffalo-sabres"
     }
 `
 
@@ -192,13 +255,248 @@ func TestGetTeamByLeagueAndNameWithTeamNotFound(t *testing.T) {
 	})
 }
 
+func TestGetTeamByLeagueAndNameWithYear(t *testing.T) {
+	expected := `
+    {
+      "id": 2,
+      "name": "Buffalo Bills",
+      "slug": "buffalo-bills",
+      "eras": [
+        {
+          "year": 2002,
+          "start": "2002-01-01",
+          "colors": [ { "name": "Midnight Navy", "hex": "#091F2C" } ]
+        }
+      ],
+      "league": "NFL",
+      "division": "AFC",
+      "_link": "/leagues/nfl/buffalo-bills"
+    }
+`
+
+	runWithSetupAndTeardown(t, func() {
+		res, err := http.Get(ts.URL + "/leagues/nfl/buffalo%20bills?year=2005")
+		g.Expect(err).Should(gomega.BeNil())
+		defer res.Body.Close()
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		body, _ := ioutil.ReadAll(res.Body)
+
+		var team *model.Team
+		must(json.Unmarshal([]byte(expected), &team))
+		g.Expect(string(body)).Should(gomega.Equal(toJSON(team)))
+
+		// the model must not be modified
+		team, _ = s.TeamByLeagueAndName("nfl", "buffalo bills")
+		g.Expect(len(team.Eras)).Should(gomega.Equal(2))
+	})
+}
+
+func TestGetTeamByLeagueAndNameWithYearNotFound(t *testing.T) {
+	runWithSetupAndTeardown(t, func() {
+		res, err := http.Get(ts.URL + "/leagues/nfl/buffalo%20bills?year=1998")
+		g.Expect(err).Should(gomega.BeNil())
+		defer res.Body.Close()
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusNotFound))
+		body, _ := ioutil.ReadAll(res.Body)
+		g.Expect(string(body)).Should(gomega.Equal(`{"message":"no colors found for year"}` + "\n"))
+
+		res, err = http.Get(ts.URL + "/leagues/nfl/buffalo%20bills?year=abc")
+		g.Expect(err).Should(gomega.BeNil())
+		defer res.Body.Close()
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusBadRequest))
+	})
+}
+
+func TestGetTeamByLeagueAndNameWithDateAndLabel(t *testing.T) {
+	runWithSetupAndTeardown(t, func() {
+		end := model.NewDate(2020, time.December, 31)
+		store, err := model.NewMemoryStore(testGenerated, &model.Team{
+			ID:     2,
+			Name:   "Buffalo Bills",
+			League: "NFL",
+			Eras: []*model.Era{
+				{Year: 2020, End: &end, Label: "throwback", Colors: []*model.Color{{Name: "Red", Hex: "#C60C30"}}},
+				{Year: 2011, Start: model.NewDate(2011, time.September, 11), Colors: []*model.Color{{Name: "Royal Blue", Hex: "#003087"}}},
+				{Year: 2002, Colors: []*model.Color{{Name: "Midnight Navy", Hex: "#091F2C"}}},
+			},
+		})
+		must(err)
+
+		c := New(store, "v1.0.0")
+		server := httptest.NewServer(c)
+		defer server.Close()
+		get := func(path string) (int, string) {
+			res, err := http.Get(server.URL + path)
+			must(err)
+			defer res.Body.Close()
+			body, err := ioutil.ReadAll(res.Body)
+			must(err)
+			return res.StatusCode, string(body)
+		}
+
+		team, _ := store.TeamByID(2)
+		eras := func(eras ...*model.Era) string {
+			copied := *team
+			copied.Eras = eras
+			return toJSON(&copied)
+		}
+
+		tests := []struct {
+			path   string
+			status int
+			body   string
+		}{
+			{"/leagues/nfl/buffalo-bills?date=2011-06-01", http.StatusOK, eras(team.Eras[2])},
+			{"/leagues/nfl/buffalo-bills?date=2011-09-11", http.StatusOK, eras(team.Eras[1])},
+			{"/leagues/nfl/buffalo-bills?date=2020-10-01&label=throwback", http.StatusOK, eras(team.Eras[0])},
+			{"/leagues/nfl/buffalo-bills?date=2021-10-01&label=throwback", http.StatusNotFound, `{"message":"no colors found for date and label"}` + "\n"},
+			{"/leagues/nfl/buffalo-bills?date=2001-10-01", http.StatusNotFound, `{"message":"no colors found for date"}` + "\n"},
+			{"/leagues/nfl/buffalo-bills?year=2019&label=throwback", http.StatusNotFound, `{"message":"no colors found for year and label"}` + "\n"},
+			{"/leagues/nfl/buffalo-bills?year=2020&label=Throwback", http.StatusOK, eras(team.Eras[0])},
+			{"/leagues/nfl/buffalo-bills?year=2020", http.StatusOK, eras(team.Eras[1])},
+			{"/leagues/nfl/buffalo-bills?label=throwback", http.StatusOK, eras(team.Eras[0])},
+			{"/leagues/nfl/buffalo-bills?label=primary", http.StatusOK, eras(team.Eras[1], team.Eras[2])},
+			{"/leagues/nfl/buffalo-bills?label=alternate", http.StatusNotFound, `{"message":"no colors found for label"}` + "\n"},
+			{"/leagues/nfl/buffalo-bills?date=2011-13-01", http.StatusBadRequest, `{"message":"date must be in the form YYYY-MM-DD"}` + "\n"},
+			{"/leagues/nfl/buffalo-bills.css?label=throwback", http.StatusOK, ":root {\n  /* Buffalo Bills (NFL) */\n  --buffalo-bills-red: #C60C30;\n}\n"},
+			{"/leagues/nfl/buffalo-bills.css", http.StatusOK, ":root {\n  /* Buffalo Bills (NFL) */\n  --buffalo-bills-royal-blue: #003087;\n}\n"},
+		}
+
+		for _, test := range tests {
+			status, body := get(test.path)
+			g.Expect(status).Should(gomega.Equal(test.status), test.path)
+			g.Expect(body).Should(gomega.Equal(test.body), test.path)
+		}
+	})
+}
+
+func TestGetTeamByLeagueAndNameWithRole(t *testing.T) {
+	runWithSetupAndTeardown(t, func() {
+		store, err := model.NewMemoryStore(testGenerated, &model.Team{
+			ID:     2,
+			Name:   "Buffalo Bills",
+			League: "NFL",
+			Eras: []*model.Era{
+				{Year: 2011, Colors: []*model.Color{
+					{Name: "White", Hex: "#FFFFFF", Role: model.RoleText},
+					{Name: "Royal Blue", Hex: "#003087", Role: model.RolePrimary},
+					{Name: "Red", Hex: "#C60C30", Role: model.RoleAccent},
+					{Name: "Navy", Hex: "#0C2340", Role: model.RoleAccent},
+				}},
+				{Year: 2002, Colors: []*model.Color{{Name: "Midnight Navy", Hex: "#091F2C"}}},
+			},
+		})
+		must(err)
+
+		c := New(store, "v1.0.0")
+		server := httptest.NewServer(c)
+		defer server.Close()
+		get := func(path string) (int, string) {
+			res, err := http.Get(server.URL + path)
+			must(err)
+			defer res.Body.Close()
+			body, err := ioutil.ReadAll(res.Body)
+			must(err)
+			return res.StatusCode, string(body)
+		}
+
+		team, _ := store.TeamByID(2)
+		colors := func(colors ...*model.Color) string {
+			era := *team.Eras[0]
+			era.Colors = colors
+			copied := *team
+			copied.Eras = []*model.Era{&era}
+			return toJSON(&copied)
+		}
+
+		tests := []struct {
+			path   string
+			status int
+			body   string
+		}{
+			{"/leagues/nfl/buffalo-bills?role=primary", http.StatusOK, colors(team.Eras[0].Colors[1])},
+			{"/leagues/nfl/buffalo-bills?role=Accent", http.StatusOK, colors(team.Eras[0].Colors[2:]...)},
+			{"/teams/2?year=2020&role=text", http.StatusOK, colors(team.Eras[0].Colors[0])},
+			{"/leagues/nfl/buffalo-bills?year=2005&role=primary", http.StatusNotFound, `{"message":"color not found"}` + "\n"},
+			{"/leagues/nfl/buffalo-bills?role=background", http.StatusNotFound, `{"message":"color not found"}` + "\n"},
+			{"/leagues/nfl/buffalo-bills?role=highlight", http.StatusBadRequest, `{"message":"role must be one of primary, secondary, accent, text, background"}` + "\n"},
+			{"/leagues/nfl/buffalo-bills.css?role=accent", http.StatusOK, ":root {\n  /* Buffalo Bills (NFL) */\n  --buffalo-bills-red: #C60C30;\n  --buffalo-bills-navy: #0C2340;\n}\n"},
+			{"/leagues/nfl/buffalo-bills.css?year=2005&role=primary", http.StatusNotFound, `{"message":"color not found"}` + "\n"},
+		}
+
+		for _, test := range tests {
+			status, body := get(test.path)
+			g.Expect(status).Should(gomega.Equal(test.status), test.path)
+			g.Expect(body).Should(gomega.Equal(test.body), test.path)
+		}
+	})
+}
+
+func TestGetTeamByID(t *testing.T) {
+	runWithSetupAndTeardown(t, func() {
+		res, body := getBody("/teams/19?year=2010", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		team, _ := s.TeamByID(19)
+		g.Expect(body).Should(gomega.Equal(toJSON(team)))
+
+		res, body = getBody("/teams/20", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusNotFound))
+		g.Expect(body).Should(gomega.Equal(`{"message":"team not found"}` + "\n"))
+
+		res, _ = getBody("/teams/99999999999999999999", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusNotFound))
+	})
+}
+
+func TestTeamRedirects(t *testing.T) {
+	runWithSetupAndTeardown(t, func() {
+		store, err := model.NewMemoryStore(testGenerated, append(newTestTeams(), &model.Team{
+			Name:        "Arizona Coyotes",
+			League:      "NHL",
+			FormerNames: []string{"Winnipeg Jets", "Phoenix Coyotes"},
+			Eras:        []*model.Era{{Year: 2003, Colors: []*model.Color{{Name: "Brick Red", Hex: "#8C2633"}}}},
+		})...)
+		must(err)
+		c := New(store, "v1.0.0")
+		c.EnableHistory(model.NewFileHistory("testdata/does-not-exist.jsonl"))
+
+		for path, location := range map[string]string{
+			"/leagues/nfl/buffalo%20bills?year=2011":           "/leagues/nfl/buffalo-bills?year=2011",
+			"/leagues/NFL/Buffalo-Bills.css":                   "/leagues/nfl/buffalo-bills.css",
+			"/leagues/nhl/phoenix%20coyotes/swatch.svg":        "/leagues/nhl/arizona-coyotes/swatch.svg",
+			"/leagues/nhl/winnipeg-jets/accessibility":         "/leagues/nhl/arizona-coyotes/accessibility",
+			"/leagues/nhl/Phoenix%20Coyotes/history":           "/leagues/nhl/arizona-coyotes/history",
+			"/leagues/nhl/phoenix%20coyotes.tailwind.json?a=b": "/leagues/nhl/arizona-coyotes.tailwind.json?a=b",
+		} {
+			res := httptest.NewRecorder()
+			c.ServeHTTP(res, httptest.NewRequest(http.MethodGet, path, nil))
+			g.Expect(res.Code).Should(gomega.Equal(http.StatusMovedPermanently), path)
+			g.Expect(res.Header().Get("Location")).Should(gomega.Equal(location), path)
+		}
+
+		for path, status := range map[string]int{
+			"/leagues/nhl/arizona-coyotes":   http.StatusOK,
+			"/leagues/NHL/arizona-coyotes":   http.StatusOK,
+			"/leagues/nhl/atlanta-thrashers": http.StatusNotFound,
+			"/leagues/nfl/phoenix-coyotes":   http.StatusNotFound,
+		} {
+			res := httptest.NewRecorder()
+			c.ServeHTTP(res, httptest.NewRequest(http.MethodGet, path, nil))
+			g.Expect(res.Code).Should(gomega.Equal(status), path)
+		}
+	})
+}
+
 func TestGetTeamsByAll(t *testing.T) {
 	expected := `[
     {
+      "id": 2,
       "name": "Buffalo Bills",
+      "slug": "buffalo-bills",
       "eras": [
         {
           "year": 2011,
+          "start": "2011-01-01",
           "colors": [
             { "name": "Royal Blue", "hex": "#003087" },
             { "name": "Scarlet Red", "hex": "#C8102E" }
@@ -206,6 +504,7 @@ func TestGetTeamsByAll(t *testing.T) {
         },
         {
           "year": 2002,
+          "start": "2002-01-01",
           "colors": [
             { "name": "Midnight Navy", "hex": "#091F2C" }
           ]
@@ -213,24 +512,30 @@ func TestGetTeamsByAll(t *testing.T) {
       ],
       "league": "NFL",
       "division": "AFC",
-	  "_link": "/leagues/nfl/buffalo%20bills"
+	  "_link": "/leagues/nfl/buffalo-bills"
     },
     {
+      "id": 19,
       "name": "Buffalo Sabres",
+      "slug": "buffalo-sabres",
       "eras": [
         {
           "year": 2010,
+          "start": "2010-01-01",
           "colors": [ { "name": "Navy", "hex": "#041E42" } ]
         }
       ],
       "league": "NHL",
-	  "_link": "/leagues/nhl/buffalo%20sabres"
+	  "_link": "/leagues/nhl/buffalo-sabres"
     },
     {
+      "id": 1,
       "name": "The Ohio State University",
+      "slug": "the-ohio-state-university",
       "eras": [
         {
           "year": 2004,
+          "start": "2004-01-01",
           "colors": [
             { "name": "Scarlet", "hex": "#BA0C2F" }
           ]
@@ -238,13 +543,16 @@ func TestGetTeamsByAll(t *testing.T) {
       ],
       "league": "NCAA",
       "division": "Big Ten Conference",
-      "_link": "/leagues/ncaa/the%20ohio%20state%20university"
+      "_link": "/leagues/ncaa/the-ohio-state-university"
     },
     {
+      "id": 3,
       "name": "University At Buffalo, The State University Of New York",
+      "slug": "university-at-buffalo-the-state-university-of-new-york",
       "eras": [
         {
           "year": 2016,
+          "start": "2016-01-01",
           "colors": [
             { "name": "Royal Blue", "hex": "#0057B7" },
             { "name": "White", "hex": "#FFFFFF" }
@@ -253,7 +561,7 @@ func TestGetTeamsByAll(t *testing.T) {
       ],
       "league": "NCAA",
       "division": "Mid-American Conference",
-      "_link": "/leagues/ncaa/university%20at%20buffalo%2C%20the%20state%20university%20of%20new%20york"
+      "_link": "/leagues/ncaa/university-at-buffalo-the-state-university-of-new-york"
     }
   ]`
 
@@ -273,33 +581,41 @@ func TestGetTeamsByAll(t *testing.T) {
 func TestGetTeamsBySearch(t *testing.T) {
 	expected := `[
     {
-      "name": "The Ohio State University",
+      "id": 3,
+      "name": "University At Buffalo, The State University Of New York",
+      "slug": "university-at-buffalo-the-state-university-of-new-york",
       "eras": [
         {
-          "year": 2004,
+          "year": 2016,
+          "start": "2016-01-01",
           "colors": [
-            { "name": "Scarlet", "hex": "#BA0C2F" }
+            { "name": "Royal Blue", "hex": "#0057B7" },
+            { "name": "White", "hex": "#FFFFFF" }
           ]
         }
       ],
       "league": "NCAA",
-      "division": "Big Ten Conference",
-      "_link": "/leagues/ncaa/the%20ohio%20state%20university"
+      "division": "Mid-American Conference",
+      "_link": "/leagues/ncaa/university-at-buffalo-the-state-university-of-new-york",
+      "score": 0.9
     },
     {
-      "name": "University At Buffalo, The State University Of New York",
+      "id": 1,
+      "name": "The Ohio State University",
+      "slug": "the-ohio-state-university",
       "eras": [
         {
-          "year": 2016,
+          "year": 2004,
+          "start": "2004-01-01",
           "colors": [
-            { "name": "Royal Blue", "hex": "#0057B7" },
-            { "name": "White", "hex": "#FFFFFF" }
+            { "name": "Scarlet", "hex": "#BA0C2F" }
           ]
         }
       ],
       "league": "NCAA",
-      "division": "Mid-American Conference",
-      "_link": "/leagues/ncaa/university%20at%20buffalo%2C%20the%20state%20university%20of%20new%20york"
+      "division": "Big Ten Conference",
+      "_link": "/leagues/ncaa/the-ohio-state-university",
+      "score": 0.725
     }
 ]`
 
@@ -310,9 +626,28 @@ func TestGetTeamsBySearch(t *testing.T) {
 		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
 		body, _ := ioutil.ReadAll(res.Body)
 
-		var teams []*model.Team
-		must(json.Unmarshal([]byte(expected), &teams))
-		g.Expect(string(body)).Should(gomega.Equal(toJSON(teams)))
+		var results []*model.SearchResult
+		must(json.Unmarshal([]byte(expected), &results))
+		g.Expect(string(body)).Should(gomega.Equal(toJSON(results)))
+	})
+}
+
+func TestGetTeamsBySearchAndLeague(t *testing.T) {
+	runWithSetupAndTeardown(t, func() {
+		res, err := http.Get(ts.URL + "/teams?search=buffalo&league=nhl")
+		g.Expect(err).Should(gomega.BeNil())
+		defer res.Body.Close()
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+
+		var results []*model.SearchResult
+		must(json.NewDecoder(res.Body).Decode(&results))
+		g.Expect(results).Should(gomega.HaveLen(1))
+		g.Expect(results[0].Name).Should(gomega.Equal("Buffalo Sabres"))
+
+		res, err = http.Get(ts.URL + "/teams?search=buffalo&league=xfl")
+		g.Expect(err).Should(gomega.BeNil())
+		defer res.Body.Close()
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusNotFound))
 	})
 }
 
@@ -328,6 +663,75 @@ func TestGetTeamsBySearchNoResults(t *testing.T) {
 	})
 }
 
+func TestSetStore(t *testing.T) {
+	runWithSetupAndTeardown(t, func() {
+		newStore, err := model.NewMemoryStore(time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC), &model.Team{
+			Name:   "Buffalo Bandits",
+			League: "NLL",
+			Eras:   []*model.Era{{Year: 1992, Colors: []*model.Color{{Name: "Orange", Hex: "#F47A38"}}}},
+		})
+		must(err)
+
+		c := New(s, "v1.0.0")
+		g.Expect(c.Store()).Should(gomega.Equal(s))
+		c.SetStore(newStore)
+		g.Expect(c.Store()).Should(gomega.Equal(newStore))
+
+		server := httptest.NewServer(c)
+		defer server.Close()
+
+		res, err := http.Get(server.URL + "/leagues")
+		g.Expect(err).Should(gomega.BeNil())
+		defer res.Body.Close()
+		body, _ := ioutil.ReadAll(res.Body)
+		g.Expect(string(body)).Should(gomega.Equal(`[{"league":"NLL","_link":"/leagues/nll"}]` + "\n"))
+	})
+}
+
+func TestReplaceStore(t *testing.T) {
+	runWithSetupAndTeardown(t, func() {
+		c := New(s, "v1.0.0")
+		first, second := newTestStore(), newTestStore()
+
+		// a store read first is served first, even if it takes longer to open
+		opening := make(chan struct{})
+		release := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, err := c.ReplaceStore(func() (model.Store, error) {
+				close(opening)
+				<-release
+				return first, nil
+			})
+			must(err)
+		}()
+
+		<-opening
+		replaced := make(chan struct{})
+		go func() {
+			defer close(replaced)
+			_, err := c.ReplaceStore(func() (model.Store, error) {
+				return second, nil
+			})
+			must(err)
+		}()
+
+		g.Consistently(replaced, 50*time.Millisecond).ShouldNot(gomega.BeClosed())
+		close(release)
+		<-done
+		<-replaced
+		g.Expect(c.Store()).Should(gomega.BeIdenticalTo(second))
+
+		// a store that can't be opened is not served
+		_, err := c.ReplaceStore(func() (model.Store, error) {
+			return nil, errors.New("bad file")
+		})
+		g.Expect(err).Should(gomega.MatchError("bad file"))
+		g.Expect(c.Store()).Should(gomega.BeIdenticalTo(second))
+	})
+}
+
 func must(err error) {
 	if err != nil {
 		panic(err)
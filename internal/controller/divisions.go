@@ -0,0 +1,103 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/weters/teamhex/internal/model"
+)
+
+// Successful response
+// swagger:response divisionsResponse
+type divisionsResponse []*model.DivisionRecord
+
+// swagger:operation GET /leagues/{league}/divisions leagues getDivisions
+//
+// Get all divisions in a league
+//
+// This endpoint returns a list of the divisions or conferences in a provided league. Leagues without divisions return
+// an empty list.
+//
+// ---
+// produces:
+// - application/json
+// parameters:
+// - in: path
+//   name: league
+//   required: true
+//   type: string
+// responses:
+//   '200':
+//     '$ref': '#/responses/divisionsResponse'
+//   '404':
+//     '$ref': '#/responses/errorResponse'
+//   '500':
+//     '$ref': '#/responses/errorResponse'
+func (c *Controller) getLeaguesLeagueDivisions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		divisions, err := c.Store().Divisions(mux.Vars(r)["league"])
+		if err != nil {
+			serveModelError(w, err)
+			return
+		}
+
+		serveJSON(w, http.StatusOK, divisions)
+	}
+}
+
+// swagger:operation GET /leagues/{league}/divisions/{division} leagues getTeamsByDivision
+//
+// Get all teams in a division
+//
+// This endpoint returns a list of teams found in a provided division or conference, given by the slug in its _link or
+// by its name. The teams can be sorted, paginated, limited to selected fields and given color formats in the same way
+// as /teams.
+//
+// ---
+// produces:
+// - application/json
+// parameters:
+// - in: path
+//   name: league
+//   required: true
+//   type: string
+// - in: path
+//   name: division
+//   required: true
+//   type: string
+// responses:
+//   '200':
+//     '$ref': '#/responses/teamsResponse'
+//   '400':
+//     '$ref': '#/responses/errorResponse'
+//   '404':
+//     '$ref': '#/responses/errorResponse'
+//   '500':
+//     '$ref': '#/responses/errorResponse'
+func (c *Controller) getLeaguesLeagueDivisionsDivision() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		teams, err := c.Store().TeamsByDivision(mux.Vars(r)["league"], mux.Vars(r)["division"])
+		if err != nil {
+			serveModelError(w, err)
+			return
+		}
+
+		serveTeams(w, r, teams)
+	}
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+func TestGetDivisions(t *testing.T) {
+	runWithSetupAndTeardown(t, func() {
+		res, body := getBody("/leagues/ncaa/divisions", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(body).Should(gomega.MatchJSON(`[
+			{ "division": "Big Ten Conference", "league": "NCAA", "_link": "/leagues/ncaa/divisions/big-ten-conference" },
+			{ "division": "Mid-American Conference", "league": "NCAA", "_link": "/leagues/ncaa/divisions/mid-american-conference" }
+		]`))
+
+		res, body = getBody("/leagues/nhl/divisions", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(body).Should(gomega.Equal("[]\n"))
+
+		res, body = getBody("/leagues/bad/divisions", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusNotFound))
+		g.Expect(body).Should(gomega.Equal(`{"message":"league not found"}` + "\n"))
+	})
+}
+
+func TestGetTeamsByDivision(t *testing.T) {
+	runWithSetupAndTeardown(t, func() {
+		res, body := getBody("/leagues/ncaa/divisions/big-ten-conference?fields=name,_link", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(body).Should(gomega.MatchJSON(`[
+			{ "name": "The Ohio State University", "_link": "/leagues/ncaa/the-ohio-state-university" }
+		]`))
+
+		// the division's name is accepted too
+		res, body = getBody("/leagues/ncaa/divisions/Big%20Ten%20Conference?fields=name", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(body).Should(gomega.MatchJSON(`[{ "name": "The Ohio State University" }]`))
+
+		res, body = getBody("/leagues/ncaa/divisions/sec", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusNotFound))
+		g.Expect(body).Should(gomega.Equal(`{"message":"division not found"}` + "\n"))
+	})
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/weters/teamhex/internal/model"
+)
+
+// Successful response
+// swagger:response historyResponse
+type historyResponse []*model.Change
+
+// EnableHistory registers the endpoint that lists the changes made to a team
+func (c *Controller) EnableHistory(h model.History) {
+	c.get("/leagues/{league:[^/]+}/{team:[^/]+}/history", c.canonicalTeam(c.getLeaguesLeagueTeamHistory(h)))
+}
+
+// swagger:operation GET /leagues/{league}/{team}/history leagues getTeamHistory
+//
+// Get the changes made to a team
+//
+// This endpoint returns every change made to the team over the API since it was created, newest first. Each change
+// records who made it, when and why, a summary of what changed, and the team before and after. Changes made under the
+// team's earlier names are included, but not those to another team that had its name. Changes are matched by the team's
+// id, so a new team given a deleted or renamed team's name starts with its own history. The history of a deleted team
+// can still be read by the name it was last changed under.
+//
+// ---
+// produces:
+// - application/json
+// parameters:
+// - in: path
+//   name: league
+//   required: true
+//   type: string
+// - in: path
+//   name: team
+//   required: true
+//   type: string
+// responses:
+//   '200':
+//     '$ref': '#/responses/historyResponse'
+//   '301':
+//     description: The team was asked for by its name or a former name. Location is its canonical URL.
+//   '404':
+//     '$ref': '#/responses/errorResponse'
+//   '500':
+//     '$ref': '#/responses/errorResponse'
+func (c *Controller) getLeaguesLeagueTeamHistory(h model.History) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		leagueName, teamName := mux.Vars(r)["league"], mux.Vars(r)["team"]
+		team, notFound := c.Store().TeamByLeagueAndName(leagueName, teamName)
+		if notFound == model.ErrLeagueNotFound || notFound == model.ErrTeamNotFound {
+			// a deleted team is found by the name it was last changed under
+			team = &model.Team{League: leagueName, Name: teamName}
+		} else if notFound != nil {
+			serveModelError(w, notFound)
+			return
+		}
+
+		changes, err := h.TeamHistory(team.League, team.ID, team.Name)
+		if err != nil {
+			serveModelError(w, err)
+			return
+		}
+
+		if len(changes) == 0 && team.ID == 0 {
+			serveModelError(w, notFound)
+			return
+		}
+
+		serveJSON(w, http.StatusOK, changes)
+	}
+}
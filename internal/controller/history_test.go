@@ -0,0 +1,75 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/onsi/gomega"
+	"github.com/weters/teamhex/internal/model"
+)
+
+func TestGetTeamHistory(t *testing.T) {
+	runWithWrites(t, func(c *Controller, filename string) {
+		res, body := getBody("/leagues/nfl/buffalo%20bills/history", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(body).Should(gomega.MatchJSON(`[]`))
+
+		res, _ = write(http.MethodPatch, "/leagues/nfl/buffalo%20bills/eras/2002", `{"colors": [{"name": "Red", "hex": "#C60C30"}]}`, map[string]string{"X-Change-Reason": "add red"})
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		res, _ = write(http.MethodPatch, "/leagues/nfl/buffalo%20bills", `{"name": "Toronto Bills"}`, map[string]string{"X-API-Key": "other", "Authorization": "", "X-Change-Reason": "moved"})
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+
+		res, body = getBody("/leagues/nfl/toronto%20bills/history", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+
+		var changes []*model.Change
+		must(json.Unmarshal([]byte(body), &changes))
+		g.Expect(changes).Should(gomega.HaveLen(2))
+		g.Expect(changes[0].Actor).Should(gomega.Equal("other"))
+		g.Expect(changes[0].Reason).Should(gomega.Equal("moved"))
+		g.Expect(changes[0].Summary).Should(gomega.Equal([]string{`renamed from "Buffalo Bills" to "Toronto Bills"`}))
+		g.Expect(changes[0].Before.Name).Should(gomega.Equal("Buffalo Bills"))
+		g.Expect(changes[0].After.Name).Should(gomega.Equal("Toronto Bills"))
+		g.Expect(changes[1].Actor).Should(gomega.Equal("tester"))
+		g.Expect(changes[1].Reason).Should(gomega.Equal("add red"))
+		g.Expect(changes[1].Action).Should(gomega.Equal(model.ActionUpdate))
+
+		// the old name redirects to the renamed team
+		res, body = getBody("/leagues/nfl/buffalo%20bills/history", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(res.Request.URL.Path).Should(gomega.Equal("/leagues/nfl/toronto-bills/history"))
+		must(json.Unmarshal([]byte(body), &changes))
+		g.Expect(changes).Should(gomega.HaveLen(2))
+
+		// a deleted team's history can still be read
+		res, _ = write(http.MethodDelete, "/leagues/nfl/toronto-bills", "", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusNoContent))
+		res, body = getBody("/leagues/nfl/toronto-bills/history", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		must(json.Unmarshal([]byte(body), &changes))
+		g.Expect(changes).Should(gomega.HaveLen(3))
+		g.Expect(changes[0].Action).Should(gomega.Equal(model.ActionDelete))
+
+		res, _ = getBody("/leagues/nfl/unknown/history", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusNotFound))
+		res, _ = getBody("/leagues/nba/toronto-bills/history", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusNotFound))
+	})
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/weters/teamhex/internal/model"
+)
+
+// Successful response
+// swagger:response lineageResponse
+type lineageResponse *model.Lineage
+
+// swagger:operation GET /leagues/{league}/{team}/lineage leagues getTeamLineage
+//
+// Get every identity of a team's franchise
+//
+// This endpoint returns the names a franchise played under before relocating or being renamed, oldest first, ending
+// with the team today. Each identity has its city, the seasons it was used and its own eras, along with the names of
+// the identities before and after it. The team may be asked for by the name of any of its predecessors.
+//
+// ---
+// produces:
+// - application/json
+// parameters:
+// - in: path
+//   name: league
+//   required: true
+//   type: string
+// - in: path
+//   name: team
+//   required: true
+//   type: string
+// - name: year
+//   in: query
+//   description: Only return the identity the franchise played under in the year, with the era in effect
+//   required: false
+//   type: integer
+// responses:
+//   '200':
+//     '$ref': '#/responses/lineageResponse'
+//   '301':
+//     description: The team was asked for by its name, a former name or a predecessor. Location is its canonical URL.
+//   '400':
+//     '$ref': '#/responses/errorResponse'
+//   '404':
+//     '$ref': '#/responses/errorResponse'
+//   '500':
+//     '$ref': '#/responses/errorResponse'
+func (c *Controller) getLeaguesLeagueTeamLineage() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		lineage, err := c.Store().Lineage(mux.Vars(r)["league"], mux.Vars(r)["team"])
+		if err != nil {
+			serveModelError(w, err)
+			return
+		}
+
+		if y := r.FormValue("year"); len(y) > 0 {
+			year, err := strconv.Atoi(y)
+			if err != nil {
+				serveModelError(w, errInvalidYear)
+				return
+			}
+
+			if lineage, err = lineage.AtYear(year); err != nil {
+				serveModelError(w, noColorsFor(err, "year", ""))
+				return
+			}
+		}
+
+		serveJSON(w, http.StatusOK, lineage)
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/onsi/gomega"
+	"github.com/weters/teamhex/internal/model"
+)
+
+func newLineageController() *Controller {
+	store, err := model.NewMemoryStore(testGenerated, append(newTestTeams(), &model.Team{
+		ID:     20,
+		Name:   "Las Vegas Raiders",
+		League: "NFL",
+		Eras:   []*model.Era{{Year: 2020, Colors: []*model.Color{{Name: "Silver", Hex: "#A2AAAD"}}}},
+		Predecessors: []*model.Identity{{
+			Name: "Oakland Raiders",
+			City: "Oakland",
+			From: 1995,
+			To:   2019,
+			Eras: []*model.Era{{Year: 1995, Colors: []*model.Color{{Name: "Silver", Hex: "#A5ACAF"}}}},
+		}},
+	})...)
+	must(err)
+
+	return New(store, "v1.0.0")
+}
+
+func TestGetTeamLineage(t *testing.T) {
+	runWithSetupAndTeardown(t, func() {
+		c := newLineageController()
+
+		res := httptest.NewRecorder()
+		c.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/leagues/nfl/las-vegas-raiders/lineage", nil))
+		g.Expect(res.Code).Should(gomega.Equal(http.StatusOK))
+		g.Expect(res.Body.String()).Should(gomega.MatchJSON(`{
+			"team": "Las Vegas Raiders",
+			"league": "NFL",
+			"identities": [
+				{
+					"name": "Oakland Raiders",
+					"city": "Oakland",
+					"from": 1995,
+					"to": 2019,
+					"eras": [{"year": 1995, "start": "1995-01-01", "colors": [{"name": "Silver", "hex": "#A5ACAF"}]}],
+					"current": false,
+					"successor": "Las Vegas Raiders"
+				},
+				{
+					"name": "Las Vegas Raiders",
+					"from": 2020,
+					"eras": [{"year": 2020, "start": "2020-01-01", "colors": [{"name": "Silver", "hex": "#A2AAAD"}]}],
+					"current": true,
+					"predecessor": "Oakland Raiders"
+				}
+			],
+			"_link": "/leagues/nfl/las-vegas-raiders"
+		}`))
+
+		res = httptest.NewRecorder()
+		c.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/leagues/nfl/las-vegas-raiders/lineage?year=2005", nil))
+		g.Expect(res.Code).Should(gomega.Equal(http.StatusOK))
+		g.Expect(res.Body.String()).Should(gomega.MatchJSON(`{
+			"team": "Las Vegas Raiders",
+			"league": "NFL",
+			"identities": [
+				{
+					"name": "Oakland Raiders",
+					"city": "Oakland",
+					"from": 1995,
+					"to": 2019,
+					"eras": [{"year": 1995, "start": "1995-01-01", "colors": [{"name": "Silver", "hex": "#A5ACAF"}]}],
+					"current": false,
+					"successor": "Las Vegas Raiders"
+				}
+			],
+			"_link": "/leagues/nfl/las-vegas-raiders"
+		}`))
+
+		for path, status := range map[string]int{
+			"/leagues/nfl/las-vegas-raiders/lineage?year=1990": http.StatusNotFound,
+			"/leagues/nfl/las-vegas-raiders/lineage?year=abc":  http.StatusBadRequest,
+			"/leagues/nfl/buffalo-bills/lineage":               http.StatusOK,
+			"/leagues/nfl/baltimore-colts/lineage":             http.StatusNotFound,
+		} {
+			res := httptest.NewRecorder()
+			c.ServeHTTP(res, httptest.NewRequest(http.MethodGet, path, nil))
+			g.Expect(res.Code).Should(gomega.Equal(status), path)
+		}
+	})
+}
+
+func TestPredecessorRedirects(t *testing.T) {
+	runWithSetupAndTeardown(t, func() {
+		c := newLineageController()
+
+		for path, location := range map[string]string{
+			"/leagues/nfl/oakland-raiders":               "/leagues/nfl/las-vegas-raiders",
+			"/leagues/nfl/Oakland%20Raiders/lineage":     "/leagues/nfl/las-vegas-raiders/lineage",
+			"/leagues/nfl/oakland-raiders.css?year=2000": "/leagues/nfl/las-vegas-raiders.css?year=2000",
+		} {
+			res := httptest.NewRecorder()
+			c.ServeHTTP(res, httptest.NewRequest(http.MethodGet, path, nil))
+			g.Expect(res.Code).Should(gomega.Equal(http.StatusMovedPermanently), path)
+			g.Expect(res.Header().Get("Location")).Should(gomega.Equal(location), path)
+		}
+	})
+}
@@ -0,0 +1,331 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/weters/teamhex/internal/model"
+)
+
+const (
+	defaultPerPage = 50
+	maxPerPage     = 100
+)
+
+// teamSortKeys are the values accepted by the sort query parameter
+var teamSortKeys = map[string]func(t *model.Team) string{
+	"name":     func(t *model.Team) string { return t.Name },
+	"league":   func(t *model.Team) string { return t.League },
+	"division": func(t *model.Team) string { return t.Division },
+}
+
+// listOptions controls how a list of teams is sorted, paginated and projected
+type listOptions struct {
+	// sortKey is empty if the list should stay in its natural order
+	sortKey    string
+	descending bool
+	// page is 0 if the list is not paginated
+	page    int
+	perPage int
+	fields  fieldTree
+	// formats are the color formats to add to every color
+	formats []string
+}
+
+func parseListOptions(r *http.Request) (*listOptions, error) {
+	opts := &listOptions{}
+
+	if s := r.FormValue("sort"); len(s) > 0 {
+		opts.sortKey = strings.TrimPrefix(s, "-")
+		opts.descending = strings.HasPrefix(s, "-")
+		if _, ok := teamSortKeys[opts.sortKey]; !ok {
+			return nil, errors.New("sort must be name, league or division, optionally prefixed with - for descending order")
+		}
+	}
+
+	p, pp := r.FormValue("page"), r.FormValue("per_page")
+	if len(p) > 0 || len(pp) > 0 {
+		opts.page, opts.perPage = 1, defaultPerPage
+
+		if len(p) > 0 {
+			page, err := strconv.Atoi(p)
+			if err != nil || page < 1 {
+				return nil, errors.New("page must be a positive number")
+			}
+			opts.page = page
+		}
+
+		if len(pp) > 0 {
+			perPage, err := strconv.Atoi(pp)
+			if err != nil || perPage < 1 || perPage > maxPerPage {
+				return nil, fmt.Errorf("per_page must be a number from 1 to %d", maxPerPage)
+			}
+			opts.perPage = perPage
+		}
+	}
+
+	if f := r.FormValue("fields"); len(f) > 0 {
+		opts.fields = parseFields(f)
+	}
+
+	formats, err := parseColorFormats(r)
+	if err != nil {
+		return nil, err
+	}
+	opts.formats = formats
+
+	return opts, nil
+}
+
+// teamList is a list of teams, possibly with extra data such as search
+// scores, that can be sorted and paginated
+type teamList interface {
+	Len() int
+	Team(i int) *model.Team
+	// Subset returns a new list of the elements at the indices, in order
+	Subset(indices []int) teamList
+	// SetTeam replaces the team at i, keeping any extra data
+	SetTeam(i int, team *model.Team)
+}
+
+type teamsList model.Teams
+
+func (l teamsList) Len() int               { return len(l) }
+func (l teamsList) Team(i int) *model.Team { return l[i] }
+func (l teamsList) Subset(idx []int) teamList {
+	subset := make(teamsList, len(idx))
+	for i, j := range idx {
+		subset[i] = l[j]
+	}
+	return subset
+}
+func (l teamsList) SetTeam(i int, team *model.Team) { l[i] = team }
+
+type searchResultsList []*model.SearchResult
+
+func (l searchResultsList) Len() int               { return len(l) }
+func (l searchResultsList) Team(i int) *model.Team { return l[i].Team }
+func (l searchResultsList) Subset(idx []int) teamList {
+	subset := make(searchResultsList, len(idx))
+	for i, j := range idx {
+		subset[i] = l[j]
+	}
+	return subset
+}
+func (l searchResultsList) SetTeam(i int, team *model.Team) {
+	l[i] = &model.SearchResult{Team: team, Score: l[i].Score}
+}
+
+// serveTeams sorts, paginates and projects the teams according to the
+// request. Link headers are added for paginated responses.
+func serveTeams(w http.ResponseWriter, r *http.Request, teams model.Teams) {
+	serveTeamList(w, r, teamsList(teams))
+}
+
+// serveTeamList is serveTeams for any kind of team list
+func serveTeamList(w http.ResponseWriter, r *http.Request, list teamList) {
+	opts, err := parseListOptions(r)
+	if err != nil {
+		serveJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	indices := make([]int, list.Len())
+	for i := range indices {
+		indices[i] = i
+	}
+
+	if opts.sortKey != "" {
+		sortTeams(list, indices, opts.sortKey, opts.descending)
+	}
+
+	if opts.page > 0 {
+		total := len(indices)
+		lastPage := (total + opts.perPage - 1) / opts.perPage
+		if lastPage == 0 {
+			lastPage = 1
+		}
+
+		w.Header().Set("Link", paginationLinks(r.URL, opts.page, lastPage))
+		w.Header().Set("X-Total-Count", strconv.Itoa(total))
+
+		start := (opts.page - 1) * opts.perPage
+		if start > total {
+			start = total
+		}
+		end := start + opts.perPage
+		if end > total {
+			end = total
+		}
+		indices = indices[start:end]
+	}
+
+	list = list.Subset(indices)
+
+	if opts.formats != nil {
+		for i := 0; i < list.Len(); i++ {
+			team, err := list.Team(i).WithColorFormats(opts.formats)
+			if err != nil {
+				serveJSONError(w, http.StatusInternalServerError, err)
+				return
+			}
+			list.SetTeam(i, team)
+		}
+	}
+
+	if opts.fields == nil {
+		serveJSON(w, http.StatusOK, list)
+		return
+	}
+
+	projected, err := opts.fields.project(list)
+	if err != nil {
+		serveJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	serveJSON(w, http.StatusOK, projected)
+}
+
+// parseColorFormats returns the color formats requested with the formats
+// query parameter, or nil if there are none
+func parseColorFormats(r *http.Request) ([]string, error) {
+	f := r.FormValue("formats")
+	if len(f) == 0 {
+		return nil, nil
+	}
+
+	formats, err := model.ParseColorFormats(f)
+	if err != nil {
+		return nil, errors.New(strings.TrimPrefix(err.Error(), "model: "))
+	}
+
+	return formats, nil
+}
+
+// sortTeams sorts the indices into list by the key, breaking ties by name
+func sortTeams(list teamList, indices []int, key string, descending bool) {
+	value := teamSortKeys[key]
+	sort.SliceStable(indices, func(i, j int) bool {
+		a, b := list.Team(indices[i]), list.Team(indices[j])
+		if cmp := strings.Compare(value(a), value(b)); cmp != 0 {
+			return (cmp < 0) != descending
+		}
+
+		return a.Name < b.Name
+	})
+}
+
+// paginationLinks returns an RFC 5988 Link header value for the page
+func paginationLinks(u *url.URL, page, lastPage int) string {
+	link := func(rel string, page int) string {
+		query := u.Query()
+		query.Set("page", strconv.Itoa(page))
+		target := url.URL{Path: u.Path, RawQuery: query.Encode()}
+		return fmt.Sprintf(`<%s>; rel="%s"`, target.String(), rel)
+	}
+
+	links := []string{link("first", 1)}
+	if page > 1 {
+		links = append(links, link("prev", page-1))
+	}
+	if page < lastPage {
+		links = append(links, link("next", page+1))
+	}
+	links = append(links, link("last", lastPage))
+
+	return strings.Join(links, ", ")
+}
+
+// fieldTree is a set of dotted JSON field paths, e.g. name,eras.colors. An
+// empty key marks a field that was selected in full.
+type fieldTree map[string]fieldTree
+
+func parseFields(fields string) fieldTree {
+	tree := make(fieldTree)
+	for _, field := range strings.Split(fields, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		node := tree
+		for _, part := range strings.Split(field, ".") {
+			child, ok := node[part]
+			if !ok {
+				child = make(fieldTree)
+				node[part] = child
+			}
+			node = child
+		}
+		node[""] = nil
+	}
+
+	return tree
+}
+
+// project returns only the selected fields of v's JSON representation.
+// Arrays are projected element by element.
+func (f fieldTree) project(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var generic interface{}
+	if err := json.Unmarshal(b, &generic); err != nil {
+		return nil, err
+	}
+
+	return f.projectValue(generic), nil
+}
+
+func (f fieldTree) projectValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case []interface{}:
+		projected := make([]interface{}, len(v))
+		for i, elem := range v {
+			projected[i] = f.projectValue(elem)
+		}
+		return projected
+	case map[string]interface{}:
+		projected := make(map[string]interface{}, len(f))
+		for key, sub := range f {
+			value, ok := v[key]
+			if !ok {
+				continue
+			}
+
+			if _, whole := sub[""]; whole {
+				projected[key] = value
+			} else {
+				projected[key] = sub.projectValue(value)
+			}
+		}
+		return projected
+	default:
+		return v
+	}
+}
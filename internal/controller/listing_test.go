@@ -0,0 +1,155 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+func TestGetTeamsSorted(t *testing.T) {
+	runWithSetupAndTeardown(t, func() {
+		res, body := getBody("/teams?sort=league&fields=name", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(body).Should(gomega.MatchJSON(`[
+			{ "name": "The Ohio State University" },
+			{ "name": "University At Buffalo, The State University Of New York" },
+			{ "name": "Buffalo Bills" },
+			{ "name": "Buffalo Sabres" }
+		]`))
+
+		res, body = getBody("/teams?sort=-league&fields=name", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(body).Should(gomega.MatchJSON(`[
+			{ "name": "Buffalo Sabres" },
+			{ "name": "Buffalo Bills" },
+			{ "name": "The Ohio State University" },
+			{ "name": "University At Buffalo, The State University Of New York" }
+		]`))
+
+		res, body = getBody("/leagues/ncaa?sort=-division&fields=division", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(body).Should(gomega.MatchJSON(`[
+			{ "division": "Mid-American Conference" },
+			{ "division": "Big Ten Conference" }
+		]`))
+
+		res, _ = getBody("/teams?sort=color", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusBadRequest))
+	})
+}
+
+func TestGetTeamsPaginated(t *testing.T) {
+	runWithSetupAndTeardown(t, func() {
+		res, body := getBody("/teams?per_page=1&fields=name", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(body).Should(gomega.MatchJSON(`[{ "name": "Buffalo Bills" }]`))
+		g.Expect(res.Header.Get("X-Total-Count")).Should(gomega.Equal("4"))
+		g.Expect(res.Header.Get("Link")).Should(gomega.Equal(
+			`</teams?fields=name&page=1&per_page=1>; rel="first", ` +
+				`</teams?fields=name&page=2&per_page=1>; rel="next", ` +
+				`</teams?fields=name&page=4&per_page=1>; rel="last"`))
+
+		res, body = getBody("/teams?search=buffalo&page=2&per_page=2&fields=name", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(body).Should(gomega.MatchJSON(`[{ "name": "University At Buffalo, The State University Of New York" }]`))
+		g.Expect(res.Header.Get("X-Total-Count")).Should(gomega.Equal("3"))
+		g.Expect(res.Header.Get("Link")).Should(gomega.Equal(
+			`</teams?fields=name&page=1&per_page=2&search=buffalo>; rel="first", ` +
+				`</teams?fields=name&page=1&per_page=2&search=buffalo>; rel="prev", ` +
+				`</teams?fields=name&page=2&per_page=2&search=buffalo>; rel="last"`))
+
+		res, body = getBody("/leagues/nfl?page=5", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(body).Should(gomega.Equal("[]\n"))
+
+		for _, query := range []string{"page=0", "page=x", "per_page=0", "per_page=101"} {
+			res, _ = getBody("/teams?"+query, nil)
+			g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusBadRequest), query)
+		}
+	})
+}
+
+func TestGetTeamsFields(t *testing.T) {
+	runWithSetupAndTeardown(t, func() {
+		res, body := getBody("/leagues/nfl?fields=name,league,eras.colors.hex,missing", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(body).Should(gomega.MatchJSON(`[
+			{
+				"name": "Buffalo Bills",
+				"league": "NFL",
+				"eras": [
+					{ "colors": [ { "hex": "#003087" }, { "hex": "#C8102E" } ] },
+					{ "colors": [ { "hex": "#091F2C" } ] }
+				]
+			}
+		]`))
+
+		res, body = getBody("/leagues/nhl?fields=eras,eras.year", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(body).Should(gomega.MatchJSON(`[
+			{ "eras": [ { "year": 2010, "start": "2010-01-01", "colors": [ { "name": "Navy", "hex": "#041E42" } ] } ] }
+		]`))
+	})
+}
+
+func TestGetTeamsColorFormats(t *testing.T) {
+	runWithSetupAndTeardown(t, func() {
+		res, body := getBody("/leagues/nhl?formats=rgb,cmyk&fields=eras.colors", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(body).Should(gomega.MatchJSON(`[
+			{
+				"eras": [
+					{
+						"colors": [
+							{
+								"name": "Navy",
+								"hex": "#041E42",
+								"formats": {
+									"rgb": { "r": 4, "g": 30, "b": 66 },
+									"cmyk": { "c": 93.9, "m": 54.5, "y": 0, "k": 74.1 }
+								}
+							}
+						]
+					}
+				]
+			}
+		]`))
+
+		res, body = getBody("/leagues/nhl/buffalo%20sabres?formats=hsl,oklch", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(body).Should(gomega.ContainSubstring(`"formats":{"hsl":{"h":214.8,"s":88.6,"l":13.7},"oklch":{"l":0.2399,"c":0.0755,"h":257.21}}`))
+
+		res, body = getBody("/teams?search=sabres&formats=all", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(body).Should(gomega.ContainSubstring(`"score":`))
+		g.Expect(body).Should(gomega.ContainSubstring(`"lab":{`))
+
+		// colors are only converted when asked
+		_, body = getBody("/leagues/nhl/buffalo%20sabres", nil)
+		g.Expect(body).ShouldNot(gomega.ContainSubstring(`"formats"`))
+
+		res, body = getBody("/leagues/nhl?formats=pms", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusBadRequest))
+		g.Expect(body).Should(gomega.Equal(`{"message":"unknown color format \"pms\", expected one of rgb, hsl, cmyk, lab, oklch"}` + "\n"))
+
+		res, _ = getBody("/leagues/nhl/buffalo%20sabres?formats=pms", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusBadRequest))
+	})
+}
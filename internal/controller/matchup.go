@@ -0,0 +1,95 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/weters/teamhex/internal/model"
+)
+
+// Successful response
+// swagger:response matchupResponse
+type matchupResponse *model.Matchup
+
+// swagger:operation GET /matchup matchup getMatchup
+//
+// Pick colors that tell two teams apart
+//
+// This endpoint picks a color from each team's current era for when they play each other. Each team's colors are
+// tried from the most representative: its primary color, its secondary color, then the rest in the order they are
+// listed, with text and background colors last. The first colors are used unless they are closer than a CIEDE2000
+// difference of 20, in which case the away team's next color is tried, then the home team's, then both, and so on.
+// If every pair is that close, the colors furthest apart are used and clash is true. Each side has the most readable
+// text color on its chosen color.
+//
+// ---
+// produces:
+// - application/json
+// parameters:
+// - name: home
+//   in: query
+//   description: The home team as league/team, e.g. nfl/arizona-cardinals
+//   required: true
+//   type: string
+// - name: away
+//   in: query
+//   description: The away team as league/team, e.g. nfl/atlanta-falcons
+//   required: true
+//   type: string
+// responses:
+//   '200':
+//     '$ref': '#/responses/matchupResponse'
+//   '400':
+//     '$ref': '#/responses/errorResponse'
+//   '404':
+//     '$ref': '#/responses/errorResponse'
+func (c *Controller) getMatchup() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		homeLeague, homeName, err := teamParam(r, "home")
+		if err != nil {
+			serveJSONError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		awayLeague, awayName, err := teamParam(r, "away")
+		if err != nil {
+			serveJSONError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		matchup, err := c.Store().Matchup(homeLeague, homeName, awayLeague, awayName)
+		if err != nil {
+			serveModelError(w, err)
+			return
+		}
+
+		serveJSON(w, http.StatusOK, matchup)
+	}
+}
+
+// teamParam splits a query parameter of the form league/team
+func teamParam(r *http.Request, name string) (league, team string, err error) {
+	parts := strings.SplitN(r.FormValue(name), "/", 2)
+	if len(parts) != 2 || len(strings.TrimSpace(parts[0])) == 0 || len(strings.TrimSpace(parts[1])) == 0 {
+		return "", "", fmt.Errorf("%s must be a team in the form league/team", name)
+	}
+
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+func TestGetMatchup(t *testing.T) {
+	runWithSetupAndTeardown(t, func() {
+		res, body := getBody("/matchup?home=nfl/buffalo%20bills&away=nhl/buffalo-sabres", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(body).Should(gomega.MatchJSON(`{
+			"home": {
+				"team": "Buffalo Bills",
+				"league": "NFL",
+				"color": { "name": "Scarlet Red", "hex": "#C8102E" },
+				"text": { "name": "White", "hex": "#FFFFFF" },
+				"ratio": 5.88,
+				"_link": "/leagues/nfl/buffalo-bills"
+			},
+			"away": {
+				"team": "Buffalo Sabres",
+				"league": "NHL",
+				"color": { "name": "Navy", "hex": "#041E42" },
+				"text": { "name": "White", "hex": "#FFFFFF" },
+				"ratio": 16.54,
+				"_link": "/leagues/nhl/buffalo-sabres"
+			},
+			"distance": 43.92,
+			"clash": false
+		}`))
+
+		res, body = getBody("/matchup?home=nfl/buffalo-bills&away=nhl/buffalo-bandits", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusNotFound))
+		g.Expect(body).Should(gomega.Equal(`{"message":"team not found"}` + "\n"))
+
+		res, body = getBody("/matchup?home=xfl/buffalo-bills&away=nhl/buffalo-sabres", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusNotFound))
+		g.Expect(body).Should(gomega.Equal(`{"message":"league not found"}` + "\n"))
+
+		res, body = getBody("/matchup?home=nfl/buffalo-bills", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusBadRequest))
+		g.Expect(body).Should(gomega.Equal(`{"message":"away must be a team in the form league/team"}` + "\n"))
+
+		for _, query := range []string{"", "home=buffalo-bills&away=nhl/buffalo-sabres", "home=nfl/&away=nhl/buffalo-sabres", "home=/buffalo-bills&away=nhl/buffalo-sabres"} {
+			res, _ = getBody("/matchup?"+query, nil)
+			g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusBadRequest), query)
+		}
+	})
+}
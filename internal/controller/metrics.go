@@ -0,0 +1,179 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// unmatchedRoute is the route label of requests that matched no route
+const unmatchedRoute = "unmatched"
+
+// routeVariablePattern matches the pattern of a route variable, e.g. the
+// :[^/]+ in {league:[^/]+}
+var routeVariablePattern = regexp.MustCompile(`\{([^:}]+):[^}]*\}`)
+
+// controllerMetrics are the metrics served at /metrics. Each controller has
+// its own registry so that more than one can be created.
+type controllerMetrics struct {
+	handler      http.Handler
+	requests     *prometheus.CounterVec
+	durations    *prometheus.HistogramVec
+	inFlight     prometheus.Gauge
+	loadDuration prometheus.Gauge
+	reloads      *prometheus.CounterVec
+}
+
+func newControllerMetrics(c *Controller) *controllerMetrics {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(prometheus.NewGoCollector(), prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}))
+
+	factory := promauto.With(registry)
+	m := &controllerMetrics{
+		handler: promhttp.HandlerFor(registry, promhttp.HandlerOpts{}),
+		requests: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "teamhex_http_requests_total",
+			Help: "HTTP requests served, by route template, method and status.",
+		}, []string{"route", "method", "status"}),
+		durations: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "teamhex_http_request_duration_seconds",
+			Help:    "Time taken to serve HTTP requests, by route template, method and status.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		inFlight: factory.NewGauge(prometheus.GaugeOpts{
+			Name: "teamhex_http_requests_in_flight",
+			Help: "HTTP requests currently being served.",
+		}),
+		loadDuration: factory.NewGauge(prometheus.GaugeOpts{
+			Name: "teamhex_model_load_duration_seconds",
+			Help: "Time taken to load the data that is being served.",
+		}),
+		reloads: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "teamhex_reloads_total",
+			Help: "Attempts to reload the data, by result (success or failure).",
+		}, []string{"result"}),
+	}
+
+	factory.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "teamhex_teams",
+		Help: "Teams in the data being served.",
+	}, func() float64 {
+		return float64(len(c.Store().AllTeams()))
+	})
+	factory.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "teamhex_leagues",
+		Help: "Leagues in the data being served.",
+	}, func() float64 {
+		return float64(len(c.Store().Leagues()))
+	})
+	factory.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "teamhex_data_generated_timestamp_seconds",
+		Help: "When the data being served was generated, as a Unix timestamp.",
+	}, func() float64 {
+		return float64(c.Store().GenerationDate().Unix())
+	})
+	factory.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "teamhex_data_age_seconds",
+		Help: "Time since the data being served was generated.",
+	}, func() float64 {
+		return time.Since(c.Store().GenerationDate()).Seconds()
+	})
+
+	return m
+}
+
+//EnableMetrics serves the metrics at /metrics alongside the API
+func (c *Controller) EnableMetrics() {
+	c.Router.Methods(http.MethodGet).Path("/metrics").Handler(c.metrics.handler)
+}
+
+//MetricsHandler returns a handler that serves the metrics, for serving them
+//on a different address than the API
+func (c *Controller) MetricsHandler() http.Handler {
+	return c.metrics.handler
+}
+
+//ObserveLoad records how long it took to load the data being served
+func (c *Controller) ObserveLoad(d time.Duration) {
+	c.metrics.loadDuration.Set(d.Seconds())
+}
+
+//ObserveReload records the result of an attempt to reload the data
+func (c *Controller) ObserveReload(err error) {
+	if err != nil {
+		c.metrics.reloads.WithLabelValues("failure").Inc()
+		return
+	}
+
+	c.metrics.reloads.WithLabelValues("success").Inc()
+}
+
+// statusRecorder remembers the status code written to a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusRecorder) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusRecorder) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// instrument counts and times requests by their route template, such as
+// /leagues/{league}, rather than their path so that the number of series
+// stays small
+func (c *Controller) instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		c.metrics.inFlight.Inc()
+		defer c.metrics.inFlight.Dec()
+
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+
+		route := unmatchedRoute
+		if current := mux.CurrentRoute(r); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = routeVariablePattern.ReplaceAllString(template, "{$1}")
+			}
+		}
+
+		status := strconv.Itoa(rec.status)
+		c.metrics.requests.WithLabelValues(route, r.Method, status).Inc()
+		c.metrics.durations.WithLabelValues(route, r.Method, status).Observe(time.Since(start).Seconds())
+	})
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+)
+
+func TestGetMetrics(t *testing.T) {
+	runWithSetupAndTeardown(t, func() {
+		c := New(s, "v1.0.0")
+		c.EnableMetrics()
+		ts.Config.Handler = c
+		c.ObserveLoad(1500 * time.Millisecond)
+		c.ObserveReload(nil)
+		c.ObserveReload(errors.New("invalid data file"))
+		c.ObserveReload(nil)
+
+		getBody("/leagues/nfl", nil)
+		getBody("/leagues/nhl", nil)
+		getBody("/leagues/nba", nil)
+		getBody("/does/not/exist", nil)
+		res, err := http.Post(ts.URL+"/teams", "application/json", nil)
+		must(err)
+		res.Body.Close()
+
+		res, body := getBody("/metrics", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(res.Header.Get("Content-Type")).Should(gomega.Equal("text/plain; version=0.0.4; charset=utf-8"))
+		g.Expect(body).Should(gomega.ContainSubstring("# TYPE teamhex_http_requests_total counter\n"))
+		g.Expect(body).Should(gomega.ContainSubstring(`teamhex_http_requests_total{method="GET",route="/leagues/{league}",status="200"} 2` + "\n"))
+		g.Expect(body).Should(gomega.ContainSubstring(`teamhex_http_requests_total{method="GET",route="/leagues/{league}",status="404"} 1` + "\n"))
+		g.Expect(body).Should(gomega.ContainSubstring(`teamhex_http_requests_total{method="GET",route="unmatched",status="404"} 1` + "\n"))
+		g.Expect(body).Should(gomega.ContainSubstring(`teamhex_http_requests_total{method="POST",route="unmatched",status="405"} 1` + "\n"))
+		g.Expect(body).Should(gomega.ContainSubstring(`teamhex_http_request_duration_seconds_count{method="GET",route="/leagues/{league}",status="200"} 2` + "\n"))
+		g.Expect(body).Should(gomega.ContainSubstring(`teamhex_http_request_duration_seconds_bucket{method="GET",route="/leagues/{league}",status="200",le="+Inf"} 2` + "\n"))
+		// this request is in flight
+		g.Expect(body).Should(gomega.ContainSubstring("teamhex_http_requests_in_flight 1\n"))
+		g.Expect(body).Should(gomega.ContainSubstring("teamhex_model_load_duration_seconds 1.5\n"))
+		g.Expect(body).Should(gomega.ContainSubstring(`teamhex_reloads_total{result="failure"} 1` + "\n"))
+		g.Expect(body).Should(gomega.ContainSubstring(`teamhex_reloads_total{result="success"} 2` + "\n"))
+		g.Expect(body).Should(gomega.ContainSubstring("teamhex_teams 4\n"))
+		g.Expect(body).Should(gomega.ContainSubstring("teamhex_leagues 3\n"))
+		g.Expect(body).Should(gomega.ContainSubstring("teamhex_data_generated_timestamp_seconds 1.5823728e+09\n"))
+		g.Expect(body).Should(gomega.MatchRegexp(`teamhex_data_age_seconds \d`))
+		g.Expect(body).Should(gomega.ContainSubstring("# TYPE go_goroutines gauge\n"))
+
+		// metrics are never served from the response cache
+		res, body = getBody("/metrics", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(body).Should(gomega.ContainSubstring(`teamhex_http_requests_total{method="GET",route="/metrics",status="200"} 1` + "\n"))
+		g.Expect(res.Header.Get("ETag")).Should(gomega.BeEmpty())
+	})
+}
+
+func TestRouteLabels(t *testing.T) {
+	runWithSetupAndTeardown(t, func() {
+		c := New(s, "v1.0.0")
+		c.EnableMetrics()
+		for _, path := range []string{"/leagues/nfl/buffalo%20bills/swatch.svg", "/leagues/nfl/buffalo%20bills.css"} {
+			c.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		}
+
+		res := httptest.NewRecorder()
+		c.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		g.Expect(res.Body.String()).Should(gomega.ContainSubstring(`route="/leagues/{league}/{team}/swatch.svg"`))
+		g.Expect(res.Body.String()).Should(gomega.ContainSubstring(`route="/leagues/{league}/{team}.{format}"`))
+	})
+}
+
+func TestMetricsHandler(t *testing.T) {
+	runWithSetupAndTeardown(t, func() {
+		c := New(s, "v1.0.0")
+		c.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/leagues/nfl", nil))
+
+		res := httptest.NewRecorder()
+		c.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		g.Expect(res.Code).Should(gomega.Equal(http.StatusNotFound))
+
+		res = httptest.NewRecorder()
+		c.MetricsHandler().ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		g.Expect(res.Code).Should(gomega.Equal(http.StatusOK))
+		g.Expect(res.Body.String()).Should(gomega.ContainSubstring(`teamhex_http_requests_total{method="GET",route="/leagues/{league}",status="200"} 1` + "\n"))
+	})
+}
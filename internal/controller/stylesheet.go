@@ -0,0 +1,361 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/weters/teamhex/internal/model"
+)
+
+const (
+	formatCSS      = "css"
+	formatSCSS     = "scss"
+	formatTailwind = "tailwind.json"
+)
+
+// stylesheetFormats matches the file suffixes for each format
+const stylesheetFormats = `css|scss|tailwind\.json`
+
+var stylesheetContentTypes = map[string]string{
+	formatCSS:      "text/css; charset=utf-8",
+	formatSCSS:     "text/x-scss; charset=utf-8",
+	formatTailwind: "application/json",
+}
+
+// palette is a team's colors for one era with stable variable names
+type palette struct {
+	team   *model.Team
+	slug   string
+	colors []paletteColor
+}
+
+type paletteColor struct {
+	slug string
+	hex  string
+}
+
+func newPalette(team *model.Team, era *model.Era) *palette {
+	p := &palette{
+		team:   team,
+		slug:   model.Slugify(team.Name),
+		colors: make([]paletteColor, len(era.Colors)),
+	}
+
+	seen := make(map[string]int)
+	for i, color := range era.Colors {
+		slug := model.Slugify(color.Name)
+		seen[slug]++
+		if n := seen[slug]; n > 1 {
+			slug = fmt.Sprintf("%s-%d", slug, n)
+		}
+
+		p.colors[i] = paletteColor{slug: slug, hex: color.Hex}
+	}
+
+	return p
+}
+
+// negotiateStylesheet returns the stylesheet format the Accept header
+// prefers, or an empty string if JSON should be served. Formats with a
+// higher q-value are preferred, then those matched by a more specific media
+// range, then those listed first. JSON is served if no format is acceptable.
+func negotiateStylesheet(r *http.Request) string {
+	accept := r.Header.Get("Accept")
+	best, bestPref := "", acceptPreference(accept, "application/json")
+	for _, format := range []string{formatCSS, formatSCSS} {
+		mediaType := strings.SplitN(stylesheetContentTypes[format], ";", 2)[0]
+		if pref := acceptPreference(accept, mediaType); pref.q > 0 && pref.better(bestPref) {
+			best, bestPref = format, pref
+		}
+	}
+
+	return best
+}
+
+// preference is how much an Accept header wants a media type
+type preference struct {
+	q float64
+	// specificity is 2 for an exact match, 1 for type/* and 0 for */*
+	specificity int
+	// position is the index of the matching media range in the header
+	position int
+}
+
+func (p preference) better(other preference) bool {
+	if p.q != other.q {
+		return p.q > other.q
+	}
+	if p.specificity != other.specificity {
+		return p.specificity > other.specificity
+	}
+
+	return p.position < other.position
+}
+
+// acceptPreference returns the q-value of the most specific media range in
+// the Accept header matching the media type. The q-value is 0 if none match.
+func acceptPreference(header, mediaType string) preference {
+	pref := preference{specificity: -1}
+	for i, accept := range strings.Split(header, ",") {
+		mediaRange, params, err := mime.ParseMediaType(accept)
+		if err != nil {
+			continue
+		}
+
+		specificity := -1
+		switch {
+		case mediaRange == mediaType:
+			specificity = 2
+		case mediaRange == strings.SplitN(mediaType, "/", 2)[0]+"/*":
+			specificity = 1
+		case mediaRange == "*/*":
+			specificity = 0
+		}
+		if specificity <= pref.specificity {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+
+		pref = preference{q: q, specificity: specificity, position: i}
+	}
+
+	return pref
+}
+
+// swagger:operation GET /leagues/{league}.{format} leagues getLeagueStylesheet
+//
+// Get a stylesheet for all teams in a league
+//
+// This endpoint returns every team's current colors as CSS custom properties, SCSS variables or a Tailwind CSS theme
+// extension, named like --arizona-cardinals-cardinal-red.
+//
+// ---
+// produces:
+// - text/css
+// - text/x-scss
+// - application/json
+// parameters:
+// - in: path
+//   name: league
+//   required: true
+//   type: string
+// - in: path
+//   name: format
+//   required: true
+//   type: string
+//   enum: [css, scss, tailwind.json]
+// responses:
+//   '200':
+//     description: The stylesheet
+//   '404':
+//     '$ref': '#/responses/errorResponse'
+func (c *Controller) getLeaguesLeagueStylesheet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		teams, err := c.Store().TeamsByLeague(mux.Vars(r)["league"])
+		if err != nil {
+			serveModelError(w, err)
+			return
+		}
+
+		serveLeagueStylesheet(w, mux.Vars(r)["format"], teams)
+	}
+}
+
+// serveLeagueStylesheet renders every team's current colors
+func serveLeagueStylesheet(w http.ResponseWriter, format string, teams model.Teams) {
+	palettes := make([]*palette, len(teams))
+	for i, team := range teams {
+		palettes[i] = newPalette(team, team.CurrentEra())
+	}
+
+	serveStylesheet(w, format, palettes)
+}
+
+// swagger:operation GET /leagues/{league}/{team}.{format} leagues getTeamStylesheet
+//
+// Get a stylesheet for a single team
+//
+// This endpoint returns the team's current colors, or the colors in effect for the year if provided, as CSS custom
+// properties, SCSS variables or a Tailwind CSS theme extension, named like --arizona-cardinals-cardinal-red.
+//
+// ---
+// produces:
+// - text/css
+// - text/x-scss
+// - application/json
+// parameters:
+// - in: path
+//   name: league
+//   required: true
+//   type: string
+// - in: path
+//   name: team
+//   required: true
+//   type: string
+// - in: path
+//   name: format
+//   required: true
+//   type: string
+//   enum: [css, scss, tailwind.json]
+// - name: year
+//   in: query
+//   description: Use the colors in effect for the year
+//   required: false
+//   type: integer
+// - name: date
+//   in: query
+//   description: Use the colors in effect on the day, as YYYY-MM-DD
+//   required: false
+//   type: string
+// - name: label
+//   in: query
+//   description: Use the colors of the era with the label, such as primary, alternate, throwback or city edition
+//   required: false
+//   type: string
+// - name: role
+//   in: query
+//   description: Only use colors with the role
+//   required: false
+//   type: string
+//   enum: [primary, secondary, accent, text, background]
+// responses:
+//   '200':
+//     description: The stylesheet
+//   '301':
+//     description: The team was asked for by its name or a former name. Location is its canonical URL.
+//   '400':
+//     '$ref': '#/responses/errorResponse'
+//   '404':
+//     '$ref': '#/responses/errorResponse'
+func (c *Controller) getLeaguesLeagueTeamStylesheet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		team, err := c.Store().TeamByLeagueAndName(mux.Vars(r)["league"], mux.Vars(r)["team"])
+		if err != nil {
+			serveModelError(w, err)
+			return
+		}
+
+		serveTeamStylesheet(w, r, mux.Vars(r)["format"], team)
+	}
+}
+
+// serveTeamStylesheet renders the colors of the era asked for, or the
+// current era. See eraForRequest and eraWithRole.
+func serveTeamStylesheet(w http.ResponseWriter, r *http.Request, format string, team *model.Team) {
+	era, err := eraForRequest(r, team)
+	if err == nil {
+		era, err = eraWithRole(r, era)
+	}
+	if err != nil {
+		serveModelError(w, err)
+		return
+	}
+
+	serveStylesheet(w, format, []*palette{newPalette(team, era)})
+}
+
+// serveStylesheet renders the palettes in the format requested
+func serveStylesheet(w http.ResponseWriter, format string, palettes []*palette) {
+	var buf bytes.Buffer
+	switch format {
+	case formatCSS:
+		writeCSS(&buf, palettes)
+	case formatSCSS:
+		writeSCSS(&buf, palettes)
+	case formatTailwind:
+		writeTailwind(&buf, palettes)
+	default:
+		serveJSONError(w, http.StatusNotAcceptable, fmt.Errorf("unsupported format %q", format))
+		return
+	}
+
+	w.Header().Set("Content-Type", stylesheetContentTypes[format])
+	w.WriteHeader(http.StatusOK)
+	if _, err := buf.WriteTo(w); err != nil {
+		logrus.WithError(err).Error("could not write stylesheet")
+	}
+}
+
+func writeCSS(buf *bytes.Buffer, palettes []*palette) {
+	buf.WriteString(":root {\n")
+	for i, p := range palettes {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+
+		fmt.Fprintf(buf, "  /* %s (%s) */\n", p.team.Name, p.team.League)
+		for _, c := range p.colors {
+			fmt.Fprintf(buf, "  --%s-%s: %s;\n", p.slug, c.slug, c.hex)
+		}
+	}
+	buf.WriteString("}\n")
+}
+
+func writeSCSS(buf *bytes.Buffer, palettes []*palette) {
+	for i, p := range palettes {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+
+		fmt.Fprintf(buf, "// %s (%s)\n", p.team.Name, p.team.League)
+		for _, c := range p.colors {
+			fmt.Fprintf(buf, "$%s-%s: %s;\n", p.slug, c.slug, c.hex)
+		}
+	}
+}
+
+// writeTailwind writes a Tailwind CSS theme extension, e.g. the class
+// bg-arizona-cardinals-cardinal-red
+func writeTailwind(buf *bytes.Buffer, palettes []*palette) {
+	colors := make(map[string]map[string]string, len(palettes))
+	for _, p := range palettes {
+		teamColors := make(map[string]string, len(p.colors))
+		for _, c := range p.colors {
+			teamColors[c.slug] = c.hex
+		}
+		colors[p.slug] = teamColors
+	}
+
+	config := map[string]interface{}{
+		"theme": map[string]interface{}{
+			"extend": map[string]interface{}{
+				"colors": colors,
+			},
+		},
+	}
+
+	enc := json.NewEncoder(buf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(config); err != nil {
+		logrus.WithError(err).Error("could not encode Tailwind config")
+	}
+}
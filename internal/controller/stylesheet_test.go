@@ -0,0 +1,142 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+func getBody(path string, header http.Header) (*http.Response, string) {
+	req, err := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+	must(err)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	must(err)
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	must(err)
+	return res, string(body)
+}
+
+func TestGetTeamStylesheet(t *testing.T) {
+	runWithSetupAndTeardown(t, func() {
+		res, body := getBody("/leagues/nfl/buffalo%20bills.css", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(res.Header.Get("Content-Type")).Should(gomega.Equal("text/css; charset=utf-8"))
+		g.Expect(body).Should(gomega.Equal(`:root {
+  /* Buffalo Bills (NFL) */
+  --buffalo-bills-royal-blue: #003087;
+  --buffalo-bills-scarlet-red: #C8102E;
+}
+`))
+
+		res, body = getBody("/leagues/nfl/buffalo%20bills.scss?year=2005", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(res.Header.Get("Content-Type")).Should(gomega.Equal("text/x-scss; charset=utf-8"))
+		g.Expect(body).Should(gomega.Equal("// Buffalo Bills (NFL)\n$buffalo-bills-midnight-navy: #091F2C;\n"))
+
+		res, body = getBody("/leagues/nfl/buffalo%20bills.tailwind.json", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(res.Header.Get("Content-Type")).Should(gomega.Equal("application/json"))
+		g.Expect(body).Should(gomega.MatchJSON(`{"theme": {"extend": {"colors": {
+			"buffalo-bills": {"royal-blue": "#003087", "scarlet-red": "#C8102E"}
+		}}}}`))
+
+		res, body = getBody("/leagues/nfl/buffalo%20bills", http.Header{"Accept": {"text/x-scss, text/css;q=0.9"}})
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(body).Should(gomega.HavePrefix("// Buffalo Bills (NFL)\n"))
+		g.Expect(res.Header["Vary"]).Should(gomega.ContainElement("Accept"))
+
+		res, body = getBody("/leagues/nfl/buffalo%20bills", http.Header{"Accept": {"text/css;q=0.5, text/x-scss;q=0.9"}})
+		g.Expect(body).Should(gomega.HavePrefix("// Buffalo Bills (NFL)\n"))
+
+		res, _ = getBody("/leagues/nfl/buffalo%20bills", http.Header{"Accept": {"text/css;q=0"}})
+		g.Expect(res.Header.Get("Content-Type")).Should(gomega.Equal("application/json"))
+		g.Expect(res.Header["Vary"]).Should(gomega.ContainElement("Accept"))
+
+		// a format suffix doesn't depend on Accept
+		res, _ = getBody("/leagues/nfl/buffalo%20bills.css", nil)
+		g.Expect(res.Header["Vary"]).ShouldNot(gomega.ContainElement("Accept"))
+
+		res, _ = getBody("/leagues/nfl/oakland%20raiders.css", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusNotFound))
+	})
+}
+
+func TestGetLeagueStylesheet(t *testing.T) {
+	runWithSetupAndTeardown(t, func() {
+		res, body := getBody("/leagues/ncaa.css", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(body).Should(gomega.Equal(`:root {
+  /* The Ohio State University (NCAA) */
+  --the-ohio-state-university-scarlet: #BA0C2F;
+
+  /* University At Buffalo, The State University Of New York (NCAA) */
+  --university-at-buffalo-the-state-university-of-new-york-royal-blue: #0057B7;
+  --university-at-buffalo-the-state-university-of-new-york-white: #FFFFFF;
+}
+`))
+
+		res, body = getBody("/leagues/ncaa", http.Header{"Accept": {"text/css"}})
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(body).Should(gomega.HavePrefix(":root {\n"))
+
+		res, body = getBody("/leagues/ncaa", http.Header{"Accept": {"application/json, text/css"}})
+		g.Expect(res.Header.Get("Content-Type")).Should(gomega.Equal("application/json"))
+		g.Expect(res.Header["Vary"]).Should(gomega.ContainElement("Accept"))
+
+		res, _ = getBody("/leagues/bad.scss", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusNotFound))
+	})
+}
+
+func TestNegotiateStylesheet(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	tests := []struct {
+		accept   string
+		expected string
+	}{
+		{"", ""},
+		{"*/*", ""},
+		{"text/css", formatCSS},
+		{"text/css;q=0", ""},
+		{"text/css;q=0, */*", ""},
+		{"text/css, */*", formatCSS},
+		{"text/css, application/json", formatCSS},
+		{"application/json, text/css", ""},
+		{"text/css;q=0.8, application/json", ""},
+		{"text/x-scss, text/css", formatSCSS},
+		{"text/x-scss;q=0.5, text/css;q=0.6", formatCSS},
+		{"text/*, application/json;q=0.9", formatCSS},
+		{"text/*;q=0.5, text/x-scss", formatSCSS},
+		{"image/png", ""},
+	}
+
+	for _, test := range tests {
+		r := &http.Request{Header: http.Header{"Accept": {test.accept}}}
+		g.Expect(negotiateStylesheet(r)).Should(gomega.Equal(test.expected), test.accept)
+	}
+}
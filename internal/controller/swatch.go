@@ -0,0 +1,212 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/weters/teamhex/internal/model"
+)
+
+const (
+	layoutStrip = "strip"
+	layoutGrid  = "grid"
+
+	defaultSwatchSize = 120
+	minSwatchSize     = 16
+	maxSwatchSize     = 512
+)
+
+// swatchOptions controls how a swatch is rendered
+type swatchOptions struct {
+	layout string
+	size   int
+	labels bool
+}
+
+func parseSwatchOptions(r *http.Request) (*swatchOptions, error) {
+	opts := &swatchOptions{
+		layout: layoutStrip,
+		size:   defaultSwatchSize,
+		labels: true,
+	}
+
+	switch layout := r.FormValue("layout"); layout {
+	case "":
+	case layoutStrip, layoutGrid:
+		opts.layout = layout
+	default:
+		return nil, fmt.Errorf("layout must be %q or %q", layoutStrip, layoutGrid)
+	}
+
+	if s := r.FormValue("size"); len(s) > 0 {
+		size, err := strconv.Atoi(s)
+		if err != nil || size < minSwatchSize || size > maxSwatchSize {
+			return nil, fmt.Errorf("size must be a number from %d to %d", minSwatchSize, maxSwatchSize)
+		}
+		opts.size = size
+	}
+
+	if l := r.FormValue("labels"); len(l) > 0 {
+		labels, err := strconv.ParseBool(l)
+		if err != nil {
+			return nil, errors.New("labels must be true or false")
+		}
+		opts.labels = labels
+	}
+
+	return opts, nil
+}
+
+// swagger:operation GET /leagues/{league}/{team}/swatch.svg leagues getTeamSwatch
+//
+// Get an SVG swatch of a team's colors
+//
+// This endpoint renders the team's current colors, or the colors in effect for the year if provided, as an SVG
+// image. Each swatch is labeled with the color's name and hex value in black or white, whichever is more readable.
+//
+// ---
+// produces:
+// - image/svg+xml
+// parameters:
+// - in: path
+//   name: league
+//   required: true
+//   type: string
+// - in: path
+//   name: team
+//   required: true
+//   type: string
+// - name: layout
+//   in: query
+//   description: Lay the swatches out in a single row (strip) or a square grid
+//   required: false
+//   type: string
+//   enum: [strip, grid]
+//   default: strip
+// - name: size
+//   in: query
+//   description: The width and height of each swatch in pixels
+//   required: false
+//   type: integer
+//   minimum: 16
+//   maximum: 512
+//   default: 120
+// - name: labels
+//   in: query
+//   description: Whether to draw the color's name and hex value on each swatch
+//   required: false
+//   type: boolean
+//   default: true
+// - name: year
+//   in: query
+//   description: Use the colors in effect for the year
+//   required: false
+//   type: integer
+// responses:
+//   '200':
+//     description: The SVG image
+//   '301':
+//     description: The team was asked for by its name or a former name. Location is its canonical URL.
+//   '400':
+//     '$ref': '#/responses/errorResponse'
+//   '404':
+//     '$ref': '#/responses/errorResponse'
+func (c *Controller) getLeaguesLeagueTeamSwatch() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		opts, err := parseSwatchOptions(r)
+		if err != nil {
+			serveJSONError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		team, err := c.Store().TeamByLeagueAndName(mux.Vars(r)["league"], mux.Vars(r)["team"])
+		if err != nil {
+			serveModelError(w, err)
+			return
+		}
+
+		era, err := eraForRequest(r, team)
+		if err != nil {
+			serveModelError(w, err)
+			return
+		}
+
+		var buf bytes.Buffer
+		if err := writeSwatch(&buf, team, era, opts); err != nil {
+			serveJSONError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "image/svg+xml")
+		w.WriteHeader(http.StatusOK)
+		if _, err := buf.WriteTo(w); err != nil {
+			logrus.WithError(err).Error("could not write swatch")
+		}
+	}
+}
+
+func writeSwatch(buf *bytes.Buffer, team *model.Team, era *model.Era, opts *swatchOptions) error {
+	n := len(era.Colors)
+	columns := n
+	if opts.layout == layoutGrid {
+		columns = int(math.Ceil(math.Sqrt(float64(n))))
+	}
+	rows := (n + columns - 1) / columns
+
+	width, height := columns*opts.size, rows*opts.size
+	fontSize := opts.size / 10
+	if fontSize < 8 {
+		fontSize = 8
+	}
+
+	fmt.Fprintf(buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", width, height, width, height)
+	fmt.Fprintf(buf, "  <title>%s (%d)</title>\n", html.EscapeString(team.Name), era.Year)
+
+	for i, color := range era.Colors {
+		rgb, err := model.ParseHex(color.Hex)
+		if err != nil {
+			return err
+		}
+
+		x, y := (i%columns)*opts.size, (i/columns)*opts.size
+		fmt.Fprintf(buf, `  <rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`+"\n", x, y, opts.size, opts.size, rgb.Hex())
+
+		if !opts.labels {
+			continue
+		}
+
+		textX := x + opts.size/2
+		textY := y + opts.size/2
+		fmt.Fprintf(buf, `  <text x="%d" y="%d" fill="%s" font-family="sans-serif" font-size="%d" text-anchor="middle">`+"\n",
+			textX, textY, model.TextColor(rgb).Hex(), fontSize)
+		fmt.Fprintf(buf, "    <tspan x=\"%d\">%s</tspan>\n", textX, html.EscapeString(color.Name))
+		fmt.Fprintf(buf, "    <tspan x=\"%d\" dy=\"1.2em\">%s</tspan>\n", textX, rgb.Hex())
+		buf.WriteString("  </text>\n")
+	}
+
+	buf.WriteString("</svg>\n")
+	return nil
+}
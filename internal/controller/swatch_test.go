@@ -0,0 +1,66 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+func TestGetTeamSwatch(t *testing.T) {
+	runWithSetupAndTeardown(t, func() {
+		res, body := getBody("/leagues/ncaa/university%20at%20buffalo%2C%20the%20state%20university%20of%20new%20york/swatch.svg", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(res.Header.Get("Content-Type")).Should(gomega.Equal("image/svg+xml"))
+		g.Expect(body).Should(gomega.Equal(`<svg xmlns="http://www.w3.org/2000/svg" width="240" height="120" viewBox="0 0 240 120">
+  <title>University At Buffalo, The State University Of New York (2016)</title>
+  <rect x="0" y="0" width="120" height="120" fill="#0057B7"/>
+  <text x="60" y="60" fill="#FFFFFF" font-family="sans-serif" font-size="12" text-anchor="middle">
+    <tspan x="60">Royal Blue</tspan>
+    <tspan x="60" dy="1.2em">#0057B7</tspan>
+  </text>
+  <rect x="120" y="0" width="120" height="120" fill="#FFFFFF"/>
+  <text x="180" y="60" fill="#000000" font-family="sans-serif" font-size="12" text-anchor="middle">
+    <tspan x="180">White</tspan>
+    <tspan x="180" dy="1.2em">#FFFFFF</tspan>
+  </text>
+</svg>
+`))
+	})
+}
+
+func TestGetTeamSwatchOptions(t *testing.T) {
+	runWithSetupAndTeardown(t, func() {
+		res, body := getBody("/leagues/nfl/buffalo%20bills/swatch.svg?layout=grid&size=50&labels=false&year=2002", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(body).Should(gomega.Equal(`<svg xmlns="http://www.w3.org/2000/svg" width="50" height="50" viewBox="0 0 50 50">
+  <title>Buffalo Bills (2002)</title>
+  <rect x="0" y="0" width="50" height="50" fill="#091F2C"/>
+</svg>
+`))
+
+		for _, query := range []string{"layout=circle", "size=8", "size=big", "labels=maybe", "year=abc"} {
+			res, _ = getBody("/leagues/nfl/buffalo%20bills/swatch.svg?"+query, nil)
+			g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusBadRequest), query)
+		}
+
+		res, _ = getBody("/leagues/nfl/buffalo%20bills/swatch.svg?year=1990", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusNotFound))
+	})
+}
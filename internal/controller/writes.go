@@ -0,0 +1,942 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/weters/teamhex/internal/model"
+)
+
+// maxWriteBodySize limits how large a write request body can be
+const maxWriteBodySize = 1 << 20
+
+// APIKey is a key that may change teams. Name is recorded as the actor of
+// every change made with the key.
+type APIKey struct {
+	Name string
+	Key  string
+}
+
+// EnableWrites registers the endpoints that create, change and delete teams,
+// eras and colors. Changes are made with the updater and the resulting store
+// replaces the current one. Every request must authenticate with one of the
+// keys, either as a bearer token or in the X-API-Key header, and give a
+// reason for the change in the X-Change-Reason header.
+func (c *Controller) EnableWrites(u model.Updater, keys []APIKey) {
+	c.updater = u
+	auth := newAuthenticator(keys)
+
+	team := "/leagues/{league:[^/]+}/{team:[^/]+}"
+	c.Router.Methods(http.MethodPost).Path(team).Handler(auth.wrap(c.putTeam(false)))
+	c.Router.Methods(http.MethodPut).Path(team).Handler(auth.wrap(c.putTeam(true)))
+	c.Router.Methods(http.MethodPatch).Path(team).Handler(auth.wrap(c.patchTeam()))
+	c.Router.Methods(http.MethodDelete).Path(team).Handler(auth.wrap(c.deleteTeam()))
+
+	era := team + "/eras/{start:[0-9]+(?:-[0-9]{2}-[0-9]{2})?}"
+	c.Router.Methods(http.MethodPost).Path(era).Handler(auth.wrap(c.putEra(false)))
+	c.Router.Methods(http.MethodPut).Path(era).Handler(auth.wrap(c.putEra(true)))
+	c.Router.Methods(http.MethodPatch).Path(era).Handler(auth.wrap(c.patchEra()))
+	c.Router.Methods(http.MethodDelete).Path(era).Handler(auth.wrap(c.deleteEra()))
+}
+
+// authenticator checks API keys. Only hashes of the keys are kept so that
+// comparisons take the same time regardless of the key's length.
+type authenticator struct {
+	keys  [][sha256.Size]byte
+	names []string
+}
+
+func newAuthenticator(keys []APIKey) *authenticator {
+	a := &authenticator{}
+	for _, key := range keys {
+		if k := strings.TrimSpace(key.Key); k != "" {
+			a.keys = append(a.keys, sha256.Sum256([]byte(k)))
+			a.names = append(a.names, key.Name)
+		}
+	}
+
+	return a
+}
+
+// name returns the name of the key, or false if the key is not valid
+func (a *authenticator) name(key string) (string, bool) {
+	if key == "" {
+		return "", false
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	found := -1
+	for i, k := range a.keys {
+		if subtle.ConstantTimeCompare(sum[:], k[:]) == 1 {
+			found = i
+		}
+	}
+
+	if found < 0 {
+		return "", false
+	}
+
+	return a.names[found], true
+}
+
+type actorKey struct{}
+
+// actor returns the name of the API key the request authenticated with
+func actor(r *http.Request) string {
+	name, _ := r.Context().Value(actorKey{}).(string)
+	return name
+}
+
+func (a *authenticator) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("X-API-Key")
+		if auth := r.Header.Get("Authorization"); len(auth) > 7 && strings.EqualFold(auth[:7], "Bearer ") {
+			key = strings.TrimSpace(auth[7:])
+		}
+
+		name, ok := a.name(key)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="teamhex"`)
+			serveJSONError(w, http.StatusUnauthorized, errors.New("a valid API key is required"))
+			return
+		}
+
+		if strings.TrimSpace(r.Header.Get("X-Change-Reason")) == "" {
+			serveJSONError(w, http.StatusBadRequest, errors.New("the X-Change-Reason header is required"))
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), actorKey{}, name)))
+	})
+}
+
+// errBadRequest wraps problems with the request itself
+type errBadRequest struct {
+	err error
+}
+
+func (e *errBadRequest) Error() string {
+	return e.err.Error()
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxWriteBodySize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return &errBadRequest{fmt.Errorf("invalid request body: %v", err)}
+	}
+
+	return nil
+}
+
+// A team to create or replace
+// swagger:parameters createTeam replaceTeam
+type teamRequestParams struct {
+	// in: body
+	// required: true
+	Body teamRequest
+}
+
+type teamRequest struct {
+	// ID defaults to the next unused ID for a new team, which can't have one another team has had, or the team's ID
+	// when replacing one, which it must match
+	ID int `json:"id"`
+	// Name must match the team in the URL, ignoring case and punctuation
+	Name string `json:"name"`
+	// League defaults to the league in the URL, which it must match ignoring case
+	League   string       `json:"league"`
+	Division string       `json:"division"`
+	Aliases  []string     `json:"aliases"`
+	Eras     []*model.Era `json:"eras"`
+	// Predecessors default to the team's predecessors when replacing one
+	Predecessors []*model.Identity `json:"predecessors"`
+}
+
+// Changes to a team. Only the fields provided are changed.
+// swagger:parameters updateTeam
+type teamPatchParams struct {
+	// in: body
+	// required: true
+	Body teamPatch
+}
+
+type teamPatch struct {
+	// ID must be the team's ID, which never changes
+	ID *int `json:"id"`
+	// Name renames the team. The old name is added to its former names.
+	Name     *string   `json:"name"`
+	Division *string   `json:"division"`
+	Aliases  *[]string `json:"aliases"`
+	// Predecessors replaces the franchise's earlier identities, oldest first
+	Predecessors *[]*model.Identity `json:"predecessors"`
+}
+
+// An era to create, replace or update
+// swagger:parameters createEra replaceEra updateEra
+type eraRequestParams struct {
+	// in: body
+	// required: true
+	Body eraRequest
+}
+
+type eraRequest struct {
+	// Year defaults to the year in the URL, which it must match
+	Year int `json:"year"`
+	// Start defaults to January 1 of the year
+	Start model.Date `json:"start"`
+	// End is the last day of the era, if it has ended
+	End *model.Date `json:"end"`
+	// Label defaults to the label in the URL, which it must match
+	Label  string         `json:"label"`
+	Colors []*model.Color `json:"colors"`
+}
+
+// eraKey returns the key of the era in the URL, which gives the year or the
+// day the era started
+func eraKey(r *http.Request, label string) (model.EraKey, error) {
+	key, err := model.ParseEraKey(mux.Vars(r)["start"], label)
+	if err != nil {
+		return key, errInvalidDate
+	}
+
+	return key, nil
+}
+
+// label returns the era's label from the request body or URL
+func (req *eraRequest) label(r *http.Request) (string, error) {
+	label := r.URL.Query().Get("label")
+	if req.Label == "" {
+		return label, nil
+	}
+
+	if label != "" && !strings.EqualFold(req.Label, label) {
+		return "", errors.New("label must match the label in the URL")
+	}
+
+	return req.Label, nil
+}
+
+// swagger:operation POST /leagues/{league}/{team} write createTeam
+//
+// Create a team
+//
+// ---
+// consumes:
+// - application/json
+// produces:
+// - application/json
+// security:
+// - bearer: []
+// - apiKey: []
+// parameters:
+// - in: header
+//   name: X-Change-Reason
+//   description: Why the change is being made, which is recorded in the team's history
+//   required: true
+//   type: string
+// - in: path
+//   name: league
+//   required: true
+//   type: string
+// - in: path
+//   name: team
+//   required: true
+//   type: string
+// responses:
+//   '201':
+//     '$ref': '#/responses/teamResponse'
+//   '400':
+//     '$ref': '#/responses/errorResponse'
+//   '401':
+//     '$ref': '#/responses/errorResponse'
+//   '409':
+//     '$ref': '#/responses/errorResponse'
+//   '422':
+//     '$ref': '#/responses/validationErrorResponse'
+
+// swagger:operation PUT /leagues/{league}/{team} write replaceTeam
+//
+// Create or replace a team
+//
+// A replaced team keeps its place in the data file.
+//
+// ---
+// consumes:
+// - application/json
+// produces:
+// - application/json
+// security:
+// - bearer: []
+// - apiKey: []
+// parameters:
+// - in: header
+//   name: X-Change-Reason
+//   description: Why the change is being made, which is recorded in the team's history
+//   required: true
+//   type: string
+// - in: path
+//   name: league
+//   required: true
+//   type: string
+// - in: path
+//   name: team
+//   required: true
+//   type: string
+// responses:
+//   '200':
+//     '$ref': '#/responses/teamResponse'
+//   '201':
+//     '$ref': '#/responses/teamResponse'
+//   '400':
+//     '$ref': '#/responses/errorResponse'
+//   '401':
+//     '$ref': '#/responses/errorResponse'
+//   '409':
+//     '$ref': '#/responses/errorResponse'
+//   '422':
+//     '$ref': '#/responses/validationErrorResponse'
+func (c *Controller) putTeam(replace bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		leagueName, teamName := mux.Vars(r)["league"], mux.Vars(r)["team"]
+
+		var req teamRequest
+		if err := decodeBody(w, r, &req); err != nil {
+			serveWriteError(w, err)
+			return
+		}
+
+		if model.Slugify(req.Name) != model.Slugify(teamName) {
+			serveJSONError(w, http.StatusBadRequest, errors.New("name must match the team in the URL"))
+			return
+		}
+
+		if req.League == "" {
+			req.League = leagueName
+		} else if !strings.EqualFold(req.League, leagueName) {
+			serveJSONError(w, http.StatusBadRequest, errors.New("league must match the league in the URL"))
+			return
+		}
+
+		team := &model.Team{
+			ID:           req.ID,
+			Name:         req.Name,
+			Eras:         req.Eras,
+			League:       req.League,
+			Division:     req.Division,
+			Aliases:      req.Aliases,
+			Predecessors: req.Predecessors,
+		}
+
+		created := true
+		s, err := c.update(r, func(data *model.DataFile) (err error) {
+			if replace {
+				created, err = data.PutTeam(team)
+				return err
+			}
+
+			return data.AddTeam(team)
+		})
+		if err != nil {
+			serveWriteError(w, err)
+			return
+		}
+
+		serveTeamWritten(w, s, leagueName, req.Name, created)
+	}
+}
+
+// swagger:operation PATCH /leagues/{league}/{team} write updateTeam
+//
+// Rename a team or change its division, aliases or predecessors
+//
+// Links to the team's old name redirect to it after a rename.
+//
+// ---
+// consumes:
+// - application/json
+// produces:
+// - application/json
+// security:
+// - bearer: []
+// - apiKey: []
+// parameters:
+// - in: header
+//   name: X-Change-Reason
+//   description: Why the change is being made, which is recorded in the team's history
+//   required: true
+//   type: string
+// - in: path
+//   name: league
+//   required: true
+//   type: string
+// - in: path
+//   name: team
+//   required: true
+//   type: string
+// responses:
+//   '200':
+//     '$ref': '#/responses/teamResponse'
+//   '400':
+//     '$ref': '#/responses/errorResponse'
+//   '401':
+//     '$ref': '#/responses/errorResponse'
+//   '404':
+//     '$ref': '#/responses/errorResponse'
+//   '409':
+//     '$ref': '#/responses/errorResponse'
+//   '422':
+//     '$ref': '#/responses/validationErrorResponse'
+func (c *Controller) patchTeam() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		leagueName, teamName := mux.Vars(r)["league"], mux.Vars(r)["team"]
+
+		var patch teamPatch
+		if err := decodeBody(w, r, &patch); err != nil {
+			serveWriteError(w, err)
+			return
+		}
+
+		s, err := c.update(r, func(data *model.DataFile) error {
+			team, err := data.Team(leagueName, teamName)
+			if err != nil {
+				return err
+			}
+
+			if patch.Name != nil {
+				if err := data.RenameTeam(leagueName, teamName, *patch.Name); err != nil {
+					return err
+				}
+				teamName = *patch.Name
+			}
+
+			if patch.ID != nil && *patch.ID != team.ID {
+				return model.ErrIDChanged
+			}
+
+			if patch.Division != nil {
+				team.Division = *patch.Division
+			}
+
+			if patch.Aliases != nil {
+				team.Aliases = *patch.Aliases
+			}
+
+			if patch.Predecessors != nil {
+				team.Predecessors = *patch.Predecessors
+			}
+
+			return nil
+		})
+		if err != nil {
+			serveWriteError(w, err)
+			return
+		}
+
+		serveTeamWritten(w, s, leagueName, teamName, false)
+	}
+}
+
+// swagger:operation DELETE /leagues/{league}/{team} write deleteTeam
+//
+// Delete a team
+//
+// ---
+// security:
+// - bearer: []
+// - apiKey: []
+// parameters:
+// - in: header
+//   name: X-Change-Reason
+//   description: Why the change is being made, which is recorded in the team's history
+//   required: true
+//   type: string
+// - in: path
+//   name: league
+//   required: true
+//   type: string
+// - in: path
+//   name: team
+//   required: true
+//   type: string
+// responses:
+//   '204':
+//     description: The team was deleted
+//   '401':
+//     '$ref': '#/responses/errorResponse'
+//   '404':
+//     '$ref': '#/responses/errorResponse'
+//   '422':
+//     '$ref': '#/responses/validationErrorResponse'
+func (c *Controller) deleteTeam() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		leagueName, teamName := mux.Vars(r)["league"], mux.Vars(r)["team"]
+
+		if _, err := c.update(r, func(data *model.DataFile) error {
+			return data.RemoveTeam(leagueName, teamName)
+		}); err != nil {
+			serveWriteError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// swagger:operation POST /leagues/{league}/{team}/eras/{start} write createEra
+//
+// Add an era to a team
+//
+// ---
+// consumes:
+// - application/json
+// produces:
+// - application/json
+// security:
+// - bearer: []
+// - apiKey: []
+// parameters:
+// - in: header
+//   name: X-Change-Reason
+//   description: Why the change is being made, which is recorded in the team's history
+//   required: true
+//   type: string
+// - in: path
+//   name: league
+//   required: true
+//   type: string
+// - in: path
+//   name: team
+//   required: true
+//   type: string
+// - in: path
+//   name: start
+//   description: The year the era started, or the day it started (YYYY-MM-DD) if more than one era with the label started that year
+//   required: true
+//   type: string
+// - name: label
+//   in: query
+//   description: The era's label, such as alternate or throwback, if it is not primary
+//   required: false
+//   type: string
+// responses:
+//   '201':
+//     '$ref': '#/responses/eraResponse'
+//   '400':
+//     '$ref': '#/responses/errorResponse'
+//   '401':
+//     '$ref': '#/responses/errorResponse'
+//   '404':
+//     '$ref': '#/responses/errorResponse'
+//   '409':
+//     '$ref': '#/responses/errorResponse'
+//   '422':
+//     '$ref': '#/responses/validationErrorResponse'
+
+// swagger:operation PUT /leagues/{league}/{team}/eras/{start} write replaceEra
+//
+// Create or replace the colors of a team's era
+//
+// ---
+// consumes:
+// - application/json
+// produces:
+// - application/json
+// security:
+// - bearer: []
+// - apiKey: []
+// parameters:
+// - in: header
+//   name: X-Change-Reason
+//   description: Why the change is being made, which is recorded in the team's history
+//   required: true
+//   type: string
+// - in: path
+//   name: league
+//   required: true
+//   type: string
+// - in: path
+//   name: team
+//   required: true
+//   type: string
+// - in: path
+//   name: start
+//   description: The year the era started, or the day it started (YYYY-MM-DD) if more than one era with the label started that year
+//   required: true
+//   type: string
+// - name: label
+//   in: query
+//   description: The era's label, such as alternate or throwback, if it is not primary
+//   required: false
+//   type: string
+// responses:
+//   '200':
+//     '$ref': '#/responses/eraResponse'
+//   '201':
+//     '$ref': '#/responses/eraResponse'
+//   '400':
+//     '$ref': '#/responses/errorResponse'
+//   '401':
+//     '$ref': '#/responses/errorResponse'
+//   '404':
+//     '$ref': '#/responses/errorResponse'
+//   '409':
+//     '$ref': '#/responses/errorResponse'
+//   '422':
+//     '$ref': '#/responses/validationErrorResponse'
+func (c *Controller) putEra(replace bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		leagueName, teamName := mux.Vars(r)["league"], mux.Vars(r)["team"]
+		var req eraRequest
+		if err := decodeBody(w, r, &req); err != nil {
+			serveWriteError(w, err)
+			return
+		}
+
+		label, err := req.label(r)
+		if err != nil {
+			serveJSONError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		key, err := eraKey(r, label)
+		if err != nil {
+			serveModelError(w, err)
+			return
+		}
+
+		if req.Year != 0 && req.Year != key.Year {
+			serveJSONError(w, http.StatusBadRequest, errors.New("year must match the year in the URL"))
+			return
+		}
+
+		start := req.Start
+		if !key.Start.IsZero() {
+			if !start.IsZero() && !start.Equal(key.Start.Time) {
+				serveJSONError(w, http.StatusBadRequest, errors.New("start must match the day in the URL"))
+				return
+			}
+			start = key.Start
+		}
+
+		era := &model.Era{Year: key.Year, Start: start, End: req.End, Label: label, Colors: req.Colors}
+		written := model.EraKey{Year: era.Year, Start: era.StartDate(), Label: label}
+		created := true
+		s, err := c.update(r, func(data *model.DataFile) (err error) {
+			if replace {
+				created, err = data.PutEra(leagueName, teamName, key, era)
+				return err
+			}
+
+			return data.AddEra(leagueName, teamName, era)
+		})
+		if err != nil {
+			serveWriteError(w, err)
+			return
+		}
+
+		serveEraWritten(w, r, s, leagueName, teamName, written, created)
+	}
+}
+
+// swagger:operation PATCH /leagues/{league}/{team}/eras/{start} write updateEra
+//
+// Change or add colors in a team's era
+//
+// Each color's hex value is changed if the era has a color with the same name, otherwise the color is added.
+//
+// ---
+// consumes:
+// - application/json
+// produces:
+// - application/json
+// security:
+// - bearer: []
+// - apiKey: []
+// parameters:
+// - in: header
+//   name: X-Change-Reason
+//   description: Why the change is being made, which is recorded in the team's history
+//   required: true
+//   type: string
+// - in: path
+//   name: league
+//   required: true
+//   type: string
+// - in: path
+//   name: team
+//   required: true
+//   type: string
+// - in: path
+//   name: start
+//   description: The year the era started, or the day it started (YYYY-MM-DD) if more than one era with the label started that year
+//   required: true
+//   type: string
+// - name: label
+//   in: query
+//   description: The era's label, such as alternate or throwback, if it is not primary
+//   required: false
+//   type: string
+// responses:
+//   '200':
+//     '$ref': '#/responses/eraResponse'
+//   '400':
+//     '$ref': '#/responses/errorResponse'
+//   '401':
+//     '$ref': '#/responses/errorResponse'
+//   '404':
+//     '$ref': '#/responses/errorResponse'
+//   '409':
+//     '$ref': '#/responses/errorResponse'
+//   '422':
+//     '$ref': '#/responses/validationErrorResponse'
+func (c *Controller) patchEra() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		leagueName, teamName := mux.Vars(r)["league"], mux.Vars(r)["team"]
+		var req eraRequest
+		if err := decodeBody(w, r, &req); err != nil {
+			serveWriteError(w, err)
+			return
+		}
+
+		label, err := req.label(r)
+		if err != nil {
+			serveJSONError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		key, err := eraKey(r, label)
+		if err != nil {
+			serveModelError(w, err)
+			return
+		}
+
+		if req.Year != 0 && req.Year != key.Year {
+			serveJSONError(w, http.StatusBadRequest, errors.New("year must match the year in the URL"))
+			return
+		}
+
+		s, err := c.update(r, func(data *model.DataFile) error {
+			if req.End != nil {
+				team, err := data.Team(leagueName, teamName)
+				if err != nil {
+					return err
+				}
+
+				era, err := team.Era(key)
+				if err != nil {
+					return err
+				}
+				era.End = req.End
+			}
+
+			for _, color := range req.Colors {
+				if color == nil {
+					return &errBadRequest{errors.New("colors must not contain null")}
+				}
+
+				if err := data.SetColor(leagueName, teamName, key, color, true); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			serveWriteError(w, err)
+			return
+		}
+
+		serveEraWritten(w, r, s, leagueName, teamName, key, false)
+	}
+}
+
+// swagger:operation DELETE /leagues/{league}/{team}/eras/{start} write deleteEra
+//
+// Delete a team's era
+//
+// ---
+// security:
+// - bearer: []
+// - apiKey: []
+// parameters:
+// - in: header
+//   name: X-Change-Reason
+//   description: Why the change is being made, which is recorded in the team's history
+//   required: true
+//   type: string
+// - in: path
+//   name: league
+//   required: true
+//   type: string
+// - in: path
+//   name: team
+//   required: true
+//   type: string
+// - in: path
+//   name: start
+//   description: The year the era started, or the day it started (YYYY-MM-DD) if more than one era with the label started that year
+//   required: true
+//   type: string
+// - name: label
+//   in: query
+//   description: The era's label, such as alternate or throwback, if it is not primary
+//   required: false
+//   type: string
+// responses:
+//   '204':
+//     description: The era was deleted
+//   '401':
+//     '$ref': '#/responses/errorResponse'
+//   '404':
+//     '$ref': '#/responses/errorResponse'
+//   '409':
+//     '$ref': '#/responses/errorResponse'
+//   '422':
+//     '$ref': '#/responses/validationErrorResponse'
+func (c *Controller) deleteEra() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		leagueName, teamName := mux.Vars(r)["league"], mux.Vars(r)["team"]
+		key, err := eraKey(r, r.URL.Query().Get("label"))
+		if err != nil {
+			serveModelError(w, err)
+			return
+		}
+
+		if _, err := c.update(r, func(data *model.DataFile) error {
+			return data.RemoveEra(leagueName, teamName, key)
+		}); err != nil {
+			serveWriteError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// update applies fn with the updater and serves the resulting store. The
+// change is recorded as made by the request's API key for the reason given.
+// See ReplaceStore.
+func (c *Controller) update(r *http.Request, fn func(data *model.DataFile) error) (model.Store, error) {
+	return c.ReplaceStore(func() (model.Store, error) {
+		return c.updater.Update(model.Edit{
+			Actor:  actor(r),
+			Reason: strings.TrimSpace(r.Header.Get("X-Change-Reason")),
+		}, fn)
+	})
+}
+
+func serveTeamWritten(w http.ResponseWriter, s model.Store, leagueName, teamName string, created bool) {
+	team, err := s.TeamByLeagueAndName(leagueName, teamName)
+	if err != nil {
+		serveModelError(w, err)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		w.Header().Set("Location", team.Link)
+		status = http.StatusCreated
+	}
+
+	serveJSON(w, status, team)
+}
+
+// Successful response
+// swagger:response eraResponse
+type eraResponse struct {
+	// in: body
+	Body model.Era
+}
+
+func serveEraWritten(w http.ResponseWriter, r *http.Request, s model.Store, leagueName, teamName string, key model.EraKey, created bool) {
+	team, err := s.TeamByLeagueAndName(leagueName, teamName)
+	if err != nil {
+		serveModelError(w, err)
+		return
+	}
+
+	era, err := team.Era(key)
+	if err != nil {
+		serveModelError(w, err)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		// the era is linked by its year unless another era with its label
+		// started that year
+		start := strconv.Itoa(era.Year)
+		if _, err := team.EraByYear(era.Year, key.Label); err == model.ErrEraAmbiguous {
+			start = era.StartDate().String()
+		}
+
+		location := &url.URL{Path: path.Join(path.Dir(r.URL.Path), start)}
+		if key.Label != "" {
+			location.RawQuery = url.Values{"label": {key.Label}}.Encode()
+		}
+		w.Header().Set("Location", location.String())
+		status = http.StatusCreated
+	}
+
+	serveJSON(w, status, era)
+}
+
+// The change would leave the data invalid
+// swagger:response validationErrorResponse
+type validationErrorResponse struct {
+	Message  string               `json:"message"`
+	Problems []*validationProblem `json:"problems"`
+}
+
+type validationProblem struct {
+	// Path is the JSON path of the offending value in the data file
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// serveWriteError is serveModelError for errors from changing the data
+func serveWriteError(w http.ResponseWriter, err error) {
+	var validationErrs model.ValidationErrors
+	var badRequest *errBadRequest
+	switch {
+	case errors.As(err, &validationErrs):
+		res := validationErrorResponse{
+			Message:  fmt.Sprintf("the change would leave %d problem(s) in the data", len(validationErrs)),
+			Problems: make([]*validationProblem, len(validationErrs)),
+		}
+		for i, e := range validationErrs {
+			res.Problems[i] = &validationProblem{Path: e.Path, Message: e.Message}
+		}
+		serveJSON(w, http.StatusUnprocessableEntity, res)
+	case errors.As(err, &badRequest):
+		serveJSONError(w, http.StatusBadRequest, badRequest)
+	default:
+		serveModelError(w, err)
+	}
+}
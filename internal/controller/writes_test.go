@@ -0,0 +1,349 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/onsi/gomega"
+	"github.com/weters/teamhex/internal/model"
+)
+
+const testAPIKey = "secret"
+
+// runWithWrites is runWithSetupAndTeardown with writes enabled against a
+// copy of the test teams saved to a temporary file
+func runWithWrites(t *testing.T, tests func(c *Controller, filename string)) {
+	g = gomega.NewWithT(t)
+
+	dir, err := ioutil.TempDir("", "teamhex")
+	must(err)
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "teamhex.json")
+	data := &model.DataFile{Generated: testGenerated, Teams: newTestTeams()}
+	must(data.Save(filename))
+
+	history := model.NewFileHistory(model.HistoryFilename(filename))
+	u, err := model.NewUpdater(model.BackendJSON, filename, history)
+	must(err)
+
+	s, err = model.NewMemoryStore(testGenerated, newTestTeams()...)
+	g.Expect(err).ShouldNot(gomega.HaveOccurred())
+
+	c := New(s, "v1.0.0")
+	c.EnableWrites(u, []APIKey{{Name: "tester", Key: testAPIKey}, {Name: "other", Key: "other"}})
+	c.EnableHistory(history)
+	ts = httptest.NewServer(c)
+	defer ts.Close()
+
+	tests(c, filename)
+}
+
+func write(method, path, body string, headers map[string]string) (*http.Response, string) {
+	req, err := http.NewRequest(method, ts.URL+path, bytes.NewBufferString(body))
+	must(err)
+
+	req.Header.Set("Authorization", "Bearer "+testAPIKey)
+	req.Header.Set("X-Change-Reason", "testing")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	must(err)
+	defer res.Body.Close()
+
+	b, err := ioutil.ReadAll(res.Body)
+	must(err)
+
+	return res, string(b)
+}
+
+func TestWritesRequireAPIKey(t *testing.T) {
+	runWithWrites(t, func(c *Controller, filename string) {
+		for _, auth := range []string{"", "Bearer", "Bearer wrong", "Basic c2VjcmV0"} {
+			res, body := write(http.MethodDelete, "/leagues/nhl/buffalo%20sabres", "", map[string]string{"Authorization": auth})
+			g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusUnauthorized), auth)
+			g.Expect(res.Header.Get("WWW-Authenticate")).Should(gomega.HavePrefix("Bearer"))
+			g.Expect(body).Should(gomega.Equal(`{"message":"a valid API key is required"}` + "\n"))
+		}
+
+		res, body := write(http.MethodDelete, "/leagues/nhl/buffalo%20sabres", "", map[string]string{"X-Change-Reason": " "})
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusBadRequest))
+		g.Expect(body).Should(gomega.Equal(`{"message":"the X-Change-Reason header is required"}` + "\n"))
+
+		res, _ = write(http.MethodDelete, "/leagues/nhl/buffalo%20sabres", "", map[string]string{"Authorization": "", "X-API-Key": "other"})
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusNoContent))
+	})
+}
+
+func TestWritesDisabled(t *testing.T) {
+	runWithSetupAndTeardown(t, func() {
+		res, _ := write(http.MethodDelete, "/leagues/nhl/buffalo%20sabres", "", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusMethodNotAllowed))
+	})
+}
+
+func TestWriteTeam(t *testing.T) {
+	runWithWrites(t, func(c *Controller, filename string) {
+		team := `{
+			"name": "Buffalo Bandits",
+			"league": "NLL",
+			"aliases": ["BUF"],
+			"eras": [ { "year": 1992, "colors": [ { "name": "Orange", "hex": "#F47A38" } ] } ]
+		}`
+
+		res, body := write(http.MethodPost, "/leagues/nll/buffalo%20bandits", team, nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusCreated))
+		g.Expect(res.Header.Get("Location")).Should(gomega.Equal("/leagues/nll/buffalo-bandits"))
+		g.Expect(body).Should(gomega.MatchJSON(`{
+			"id": 20,
+			"name": "Buffalo Bandits",
+			"slug": "buffalo-bandits",
+			"league": "NLL",
+			"aliases": ["BUF"],
+			"eras": [ { "year": 1992, "start": "1992-01-01", "colors": [ { "name": "Orange", "hex": "#F47A38" } ] } ],
+			"_link": "/leagues/nll/buffalo-bandits"
+		}`))
+
+		// the change is served and saved
+		_, err := c.Store().TeamByLeagueAndName("nll", "buffalo bandits")
+		g.Expect(err).Should(gomega.BeNil())
+		data, err := model.Load(filename)
+		must(err)
+		g.Expect(data.Teams).Should(gomega.HaveLen(5))
+		g.Expect(data.Generated).ShouldNot(gomega.Equal(testGenerated))
+
+		res, body = write(http.MethodPost, "/leagues/nll/buffalo%20bandits", team, nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusConflict))
+		g.Expect(body).Should(gomega.Equal(`{"message":"team already exists"}` + "\n"))
+
+		res, body = write(http.MethodPut, "/leagues/nll/buffalo-bandits", team, nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(body).Should(gomega.ContainSubstring(`"id":20,`))
+
+		// a team's id never changes
+		res, body = write(http.MethodPut, "/leagues/nll/buffalo-bandits", `{"id": 21, "name": "Buffalo Bandits", "eras": []}`, nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusBadRequest))
+		g.Expect(body).Should(gomega.Equal(`{"message":"a team's id cannot be changed"}` + "\n"))
+		res, _ = write(http.MethodPatch, "/leagues/nll/buffalo-bandits", `{"id": 21}`, nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusBadRequest))
+		res, _ = write(http.MethodPatch, "/leagues/nll/buffalo-bandits", `{"id": 20}`, nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+
+		res, body = write(http.MethodPatch, "/leagues/nll/buffalo%20bandits", `{"name": "Buffalo Bisons", "division": "East"}`, nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(body).Should(gomega.ContainSubstring(`"name":"Buffalo Bisons"`))
+		g.Expect(body).Should(gomega.ContainSubstring(`"formerNames":["Buffalo Bandits"]`))
+		g.Expect(body).Should(gomega.ContainSubstring(`"division":"East"`))
+		g.Expect(body).Should(gomega.ContainSubstring(`"aliases":["BUF"]`))
+
+		res, _ = write(http.MethodDelete, "/leagues/nll/buffalo%20bisons", "", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusNoContent))
+
+		res, body = write(http.MethodDelete, "/leagues/nll/buffalo%20bisons", "", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusNotFound))
+		g.Expect(body).Should(gomega.Equal(`{"message":"league not found"}` + "\n"))
+	})
+}
+
+func TestWriteTeamInvalid(t *testing.T) {
+	runWithWrites(t, func(c *Controller, filename string) {
+		before, err := ioutil.ReadFile(filename)
+		must(err)
+
+		res, body := write(http.MethodPut, "/leagues/nhl/buffalo%20sabres", `{"name": "Buffalo Sabres", "eras": []}`, nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusUnprocessableEntity))
+		g.Expect(body).Should(gomega.MatchJSON(`{
+			"message": "the change would leave 1 problem(s) in the data",
+			"problems": [ { "path": "teams[3].eras", "message": "at least one era is required" } ]
+		}`))
+
+		for _, test := range []struct{ body, message string }{
+			{`{"name": "Buffalo Bills"}`, "name must match the team in the URL"},
+			{`{"name": "Buffalo Sabres", "league": "NFL"}`, "league must match the league in the URL"},
+		} {
+			res, body = write(http.MethodPut, "/leagues/nhl/buffalo%20sabres", test.body, nil)
+			g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusBadRequest))
+			g.Expect(body).Should(gomega.Equal(`{"message":"` + test.message + `"}` + "\n"))
+		}
+
+		for _, body := range []string{`{`, `{"colour": "red"}`, `[]`} {
+			res, _ = write(http.MethodPatch, "/leagues/nhl/buffalo%20sabres", body, nil)
+			g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusBadRequest), body)
+		}
+
+		after, err := ioutil.ReadFile(filename)
+		must(err)
+		g.Expect(after).Should(gomega.Equal(before))
+		g.Expect(c.Store()).Should(gomega.Equal(s))
+	})
+}
+
+func TestWriteEra(t *testing.T) {
+	runWithWrites(t, func(c *Controller, filename string) {
+		res, body := write(http.MethodPost, "/leagues/nfl/buffalo%20bills/eras/2021", `{"colors": [{"name": "Royal Blue", "hex": "#00338D"}]}`, nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusCreated))
+		g.Expect(res.Header.Get("Location")).Should(gomega.Equal("/leagues/nfl/buffalo%20bills/eras/2021"))
+		g.Expect(body).Should(gomega.MatchJSON(`{"year": 2021, "start": "2021-01-01", "colors": [{"name": "Royal Blue", "hex": "#00338D"}]}`))
+
+		res, _ = write(http.MethodPost, "/leagues/nfl/buffalo%20bills/eras/2021", `{"colors": []}`, nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusConflict))
+
+		res, body = write(http.MethodPatch, "/leagues/nfl/buffalo%20bills/eras/2021", `{"colors": [{"name": "royal blue", "hex": "#00338E"}, {"name": "Red", "hex": "#C60C30"}]}`, nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(body).Should(gomega.MatchJSON(`{"year": 2021, "start": "2021-01-01", "colors": [{"name": "Royal Blue", "hex": "#00338E"}, {"name": "Red", "hex": "#C60C30"}]}`))
+
+		res, body = write(http.MethodPut, "/leagues/nfl/buffalo%20bills/eras/2021", `{"year": 2021, "colors": [{"name": "Red", "hex": "#C60C30"}]}`, nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(body).Should(gomega.MatchJSON(`{"year": 2021, "start": "2021-01-01", "colors": [{"name": "Red", "hex": "#C60C30"}]}`))
+
+		team, err := c.Store().TeamByLeagueAndName("nfl", "buffalo bills")
+		must(err)
+		g.Expect(team.Eras[0].Year).Should(gomega.Equal(2021))
+
+		res, _ = write(http.MethodPut, "/leagues/nfl/buffalo%20bills/eras/2021", `{"year": 2020, "colors": []}`, nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusBadRequest))
+
+		res, body = write(http.MethodPatch, "/leagues/nfl/buffalo%20bills/eras/2021", `{"colors": [{"name": "Red", "hex": "red"}]}`, nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusUnprocessableEntity))
+		g.Expect(body).Should(gomega.ContainSubstring(`invalid hex color \"red\"`))
+
+		res, _ = write(http.MethodDelete, "/leagues/nfl/buffalo%20bills/eras/2021", "", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusNoContent))
+
+		res, body = write(http.MethodDelete, "/leagues/nfl/buffalo%20bills/eras/2021", "", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusNotFound))
+		g.Expect(body).Should(gomega.Equal(`{"message":"era not found"}` + "\n"))
+	})
+}
+
+func TestWriteLabelledEra(t *testing.T) {
+	runWithWrites(t, func(c *Controller, filename string) {
+		res, body := write(http.MethodPost, "/leagues/nfl/buffalo%20bills/eras/2011?label=throwback",
+			`{"start": "2011-09-11", "end": "2011-12-31", "colors": [{"name": "Royal Blue", "hex": "#00338D"}]}`, nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusCreated))
+		g.Expect(res.Header.Get("Location")).Should(gomega.Equal("/leagues/nfl/buffalo%20bills/eras/2011?label=throwback"))
+		g.Expect(body).Should(gomega.MatchJSON(`{
+			"year": 2011,
+			"start": "2011-09-11",
+			"end": "2011-12-31",
+			"label": "throwback",
+			"colors": [{"name": "Royal Blue", "hex": "#00338D"}]
+		}`))
+
+		// the primary era of the same year is unchanged
+		team, err := c.Store().TeamByLeagueAndName("nfl", "buffalo bills")
+		must(err)
+		g.Expect(team.Eras).Should(gomega.HaveLen(3))
+		g.Expect(team.Eras[0].Label).Should(gomega.Equal("throwback"))
+		g.Expect(team.CurrentEra().Colors).Should(gomega.HaveLen(2))
+
+		res, body = write(http.MethodPatch, "/leagues/nfl/buffalo%20bills/eras/2011?label=throwback", `{"end": "2012-01-31"}`, nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(body).Should(gomega.ContainSubstring(`"end":"2012-01-31"`))
+
+		res, body = write(http.MethodPatch, "/leagues/nfl/buffalo%20bills/eras/2011?label=throwback", `{"end": "2010-01-31"}`, nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusUnprocessableEntity))
+		g.Expect(body).Should(gomega.ContainSubstring(`end 2010-01-31 is before start 2011-09-11`))
+
+		res, _ = write(http.MethodPut, "/leagues/nfl/buffalo%20bills/eras/2011?label=throwback", `{"label": "alternate", "colors": []}`, nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusBadRequest))
+
+		res, _ = write(http.MethodPost, "/leagues/nfl/buffalo%20bills/eras/2011", `{"start": "2011-31-01", "colors": []}`, nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusBadRequest))
+
+		res, _ = write(http.MethodDelete, "/leagues/nfl/buffalo%20bills/eras/2011?label=Throwback", "", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusNoContent))
+
+		team, err = c.Store().TeamByLeagueAndName("nfl", "buffalo bills")
+		must(err)
+		g.Expect(team.Eras).Should(gomega.HaveLen(2))
+	})
+}
+
+func TestWriteColorReferences(t *testing.T) {
+	runWithWrites(t, func(c *Controller, filename string) {
+		res, body := write(http.MethodPatch, "/leagues/nfl/buffalo%20bills/eras/2011", `{"colors": [{
+			"name": "Royal Blue",
+			"hex": "#00338D",
+			"pantone": "PMS 288 C",
+			"cmyk": {"c": 100, "m": 80, "y": 6, "k": 32},
+			"rgb": {"r": 0, "g": 51, "b": 141},
+			"source": "https://www.buffalobills.com/brand"
+		}]}`, nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(body).Should(gomega.ContainSubstring(`{"name":"Royal Blue","hex":"#00338D","pantone":"PMS 288 C","cmyk":{"c":100,"m":80,"y":6,"k":32},"rgb":{"r":0,"g":51,"b":141},"source":"https://www.buffalobills.com/brand"}`))
+
+		data, err := model.Load(filename)
+		must(err)
+		team, err := data.Team("nfl", "buffalo bills")
+		must(err)
+		g.Expect(team.Eras[0].Colors[0].Pantone).Should(gomega.Equal("PMS 288 C"))
+
+		res, body = write(http.MethodPatch, "/leagues/nfl/buffalo%20bills/eras/2011", `{"colors": [{"name": "Royal Blue", "hex": "#00338D", "cmyk": {"c": 101, "m": 0, "y": 0, "k": 0}}]}`, nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusUnprocessableEntity))
+		g.Expect(body).Should(gomega.ContainSubstring(`invalid CMYK value 101`))
+
+		res, body = write(http.MethodPatch, "/leagues/nfl/buffalo%20bills/eras/2011", `{"colors": [{"name": "White", "hex": "#FFFFFF", "formats": {"rgb": {"r": 255, "g": 255, "b": 255}}}]}`, nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusUnprocessableEntity))
+		g.Expect(body).Should(gomega.ContainSubstring(`formats are computed from hex and cannot be set`))
+	})
+}
+
+func TestWriteErasStartedInTheSameYear(t *testing.T) {
+	runWithWrites(t, func(c *Controller, filename string) {
+		res, body := write(http.MethodPost, "/leagues/nfl/buffalo%20bills/eras/2011", `{"start": "2011-09-11", "colors": [{"name": "Royal Blue", "hex": "#00338D"}]}`, nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusCreated))
+		g.Expect(res.Header.Get("Location")).Should(gomega.Equal("/leagues/nfl/buffalo%20bills/eras/2011-09-11"))
+		g.Expect(body).Should(gomega.ContainSubstring(`"start":"2011-09-11"`))
+
+		// the year no longer identifies one era
+		res, body = write(http.MethodPatch, "/leagues/nfl/buffalo%20bills/eras/2011", `{"colors": [{"name": "Red", "hex": "#C60C30"}]}`, nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusConflict))
+		g.Expect(body).Should(gomega.ContainSubstring("more than one era started in the year"))
+		res, _ = write(http.MethodDelete, "/leagues/nfl/buffalo%20bills/eras/2011", "", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusConflict))
+
+		res, body = write(http.MethodPatch, "/leagues/nfl/buffalo%20bills/eras/2011-09-11", `{"colors": [{"name": "Red", "hex": "#C60C30"}]}`, nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(body).Should(gomega.ContainSubstring(`"start":"2011-09-11"`))
+		g.Expect(body).Should(gomega.ContainSubstring(`"name":"Red"`))
+
+		res, _ = write(http.MethodPut, "/leagues/nfl/buffalo%20bills/eras/2011-09-11", `{"start": "2011-09-12", "colors": []}`, nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusBadRequest))
+		res, _ = write(http.MethodDelete, "/leagues/nfl/buffalo%20bills/eras/2011-13-01", "", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusBadRequest))
+
+		res, _ = write(http.MethodDelete, "/leagues/nfl/buffalo%20bills/eras/2011-01-01", "", nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusNoContent))
+		res, body = write(http.MethodPut, "/leagues/nfl/buffalo%20bills/eras/2011", `{"start": "2011-09-11", "colors": [{"name": "Navy", "hex": "#00274C"}]}`, nil)
+		g.Expect(res.StatusCode).Should(gomega.Equal(http.StatusOK))
+		g.Expect(body).Should(gomega.ContainSubstring(`"name":"Navy"`))
+
+		team, err := c.Store().TeamByLeagueAndName("nfl", "buffalo bills")
+		must(err)
+		g.Expect(team.Eras).Should(gomega.HaveLen(2))
+	})
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import "math"
+
+// WCAG 2.1 minimum contrast ratios
+const (
+	contrastAA       = 4.5
+	contrastAALarge  = 3
+	contrastAAA      = 7
+	contrastAAALarge = 4.5
+)
+
+// AccessibilityReport describes which of a team's colors can be used together
+type AccessibilityReport struct {
+	// Team is the name of the team
+	Team string `json:"team"`
+	// League is the league the team plays in
+	League string `json:"league"`
+	// Eras has a report for each of the team's eras, newest first
+	Eras []*EraAccessibility `json:"eras"`
+	// Link is a link to retrieve the team
+	Link string `json:"_link"`
+}
+
+// EraAccessibility describes which colors in an era can be used together
+type EraAccessibility struct {
+	Year int `json:"year"`
+	// Label is the era's label, if it has one
+	Label string `json:"label,omitempty"`
+	// Pairs has the contrast of every pair of colors in the era
+	Pairs []*ContrastPair `json:"pairs"`
+	// Backgrounds has the recommended text color for each color in the era
+	Backgrounds []*TextRecommendation `json:"backgrounds"`
+}
+
+// ContrastPair is the WCAG 2.1 contrast between two colors. Contrast is
+// symmetric, so either color may be used as the background.
+type ContrastPair struct {
+	Colors [2]*Color `json:"colors"`
+	// Ratio is the contrast ratio, from 1 to 21
+	Ratio float64 `json:"ratio"`
+	// AA is whether the pair meets level AA for normal text
+	AA bool `json:"aa"`
+	// AALarge is whether the pair meets level AA for large text
+	AALarge bool `json:"aaLarge"`
+	// AAA is whether the pair meets level AAA for normal text
+	AAA bool `json:"aaa"`
+	// AAALarge is whether the pair meets level AAA for large text
+	AAALarge bool `json:"aaaLarge"`
+}
+
+// TextRecommendation is the most readable text color for a background
+type TextRecommendation struct {
+	Background *Color `json:"background"`
+	// Text is a team color if one meets level AA, otherwise black or white
+	Text  *Color  `json:"text"`
+	Ratio float64 `json:"ratio"`
+}
+
+var blackText = &Color{Name: "Black", Hex: Black.Hex()}
+var whiteText = &Color{Name: "White", Hex: White.Hex()}
+
+// NewContrastPair returns the contrast between two colors
+func NewContrastPair(a, b *Color) (*ContrastPair, error) {
+	ratio, err := contrast(a, b)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ContrastPair{
+		Colors:   [2]*Color{a, b},
+		Ratio:    floorRatio(ratio),
+		AA:       ratio >= contrastAA,
+		AALarge:  ratio >= contrastAALarge,
+		AAA:      ratio >= contrastAAA,
+		AAALarge: ratio >= contrastAAALarge,
+	}, nil
+}
+
+// NewEraAccessibility reports on the contrast between the colors in an era
+func NewEraAccessibility(era *Era) (*EraAccessibility, error) {
+	report := &EraAccessibility{
+		Year:        era.Year,
+		Label:       era.Label,
+		Pairs:       make([]*ContrastPair, 0, len(era.Colors)*(len(era.Colors)-1)/2),
+		Backgrounds: make([]*TextRecommendation, len(era.Colors)),
+	}
+
+	for i, a := range era.Colors {
+		for _, b := range era.Colors[i+1:] {
+			pair, err := NewContrastPair(a, b)
+			if err != nil {
+				return nil, err
+			}
+			report.Pairs = append(report.Pairs, pair)
+		}
+
+		rec, err := recommendText(a, era.Colors)
+		if err != nil {
+			return nil, err
+		}
+		report.Backgrounds[i] = rec
+	}
+
+	return report, nil
+}
+
+// recommendText picks the team color with the most contrast on the
+// background, falling back to black or white if none meets level AA
+func recommendText(background *Color, colors []*Color) (*TextRecommendation, error) {
+	best := &TextRecommendation{Background: background}
+	for _, text := range colors {
+		if text == background {
+			continue
+		}
+
+		ratio, err := contrast(background, text)
+		if err != nil {
+			return nil, err
+		}
+
+		if ratio > best.Ratio {
+			best.Text, best.Ratio = text, ratio
+		}
+	}
+
+	if best.Ratio < contrastAA {
+		for _, text := range []*Color{blackText, whiteText} {
+			ratio, err := contrast(background, text)
+			if err != nil {
+				return nil, err
+			}
+
+			if ratio > best.Ratio {
+				best.Text, best.Ratio = text, ratio
+			}
+		}
+	}
+
+	best.Ratio = floorRatio(best.Ratio)
+	return best, nil
+}
+
+// Accessibility reports on the contrast between a team's colors in every era
+func (m *Model) Accessibility(leagueName, name string) (*AccessibilityReport, error) {
+	team, err := m.TeamByLeagueAndName(leagueName, name)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &AccessibilityReport{
+		Team:   team.Name,
+		League: team.League,
+		Eras:   make([]*EraAccessibility, len(team.Eras)),
+		Link:   team.Link,
+	}
+
+	for i, era := range team.Eras {
+		if report.Eras[i], err = NewEraAccessibility(era); err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+func contrast(a, b *Color) (float64, error) {
+	rgbA, err := ParseHex(a.Hex)
+	if err != nil {
+		return 0, err
+	}
+
+	rgbB, err := ParseHex(b.Hex)
+	if err != nil {
+		return 0, err
+	}
+
+	return ContrastRatio(rgbA, rgbB), nil
+}
+
+// floorRatio truncates a ratio to two decimals. WCAG thresholds must not be
+// rounded up, so 4.499 is shown as 4.49 rather than a passing 4.50.
+func floorRatio(ratio float64) float64 {
+	return math.Floor(ratio*100) / 100
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+func TestNewContrastPair(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	white := &Color{Name: "White", Hex: "#FFFFFF"}
+	gray := &Color{Name: "Gray", Hex: "#767676"}
+	pair, err := NewContrastPair(gray, white)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(pair).Should(gomega.Equal(&ContrastPair{
+		Colors:   [2]*Color{gray, white},
+		Ratio:    4.54,
+		AA:       true,
+		AALarge:  true,
+		AAA:      false,
+		AAALarge: true,
+	}))
+
+	lighterGray := &Color{Name: "Lighter Gray", Hex: "#777777"}
+	pair, _ = NewContrastPair(lighterGray, white)
+	g.Expect(pair.Ratio).Should(gomega.Equal(4.47))
+	g.Expect(pair.AA).Should(gomega.BeFalse())
+	g.Expect(pair.AALarge).Should(gomega.BeTrue())
+	g.Expect(pair.AAALarge).Should(gomega.BeFalse())
+
+	_, err = NewContrastPair(white, &Color{Hex: "#FFF"})
+	g.Expect(err).ShouldNot(gomega.BeNil())
+}
+
+func TestAccessibility(t *testing.T) {
+	g := gomega.NewWithT(t)
+	m, _ := New(testFile)
+
+	_, err := m.Accessibility("nfl", "bad")
+	g.Expect(err).Should(gomega.MatchError(ErrTeamNotFound))
+
+	report, err := m.Accessibility("NFL", "buffalo bills")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(report.Team).Should(gomega.Equal("Buffalo Bills"))
+	g.Expect(report.Link).Should(gomega.Equal("/leagues/nfl/buffalo-bills"))
+	g.Expect(len(report.Eras)).Should(gomega.Equal(2))
+
+	current := report.Eras[0]
+	g.Expect(current.Year).Should(gomega.Equal(2011))
+	g.Expect(len(current.Pairs)).Should(gomega.Equal(1))
+	g.Expect(current.Pairs[0].AA).Should(gomega.BeFalse())
+	g.Expect(current.Pairs[0].AALarge).Should(gomega.BeFalse())
+
+	// royal blue and scarlet red clash, so black or white is recommended
+	g.Expect(len(current.Backgrounds)).Should(gomega.Equal(2))
+	g.Expect(current.Backgrounds[0].Background.Name).Should(gomega.Equal("Royal Blue"))
+	g.Expect(current.Backgrounds[0].Text).Should(gomega.Equal(&Color{Name: "White", Hex: "#FFFFFF"}))
+	g.Expect(current.Backgrounds[1].Background.Name).Should(gomega.Equal("Scarlet Red"))
+	g.Expect(current.Backgrounds[1].Text).Should(gomega.Equal(&Color{Name: "White", Hex: "#FFFFFF"}))
+
+	// a single color has no pairs
+	g.Expect(report.Eras[1].Pairs).Should(gomega.BeEmpty())
+	g.Expect(report.Eras[1].Backgrounds[0].Text.Name).Should(gomega.Equal("White"))
+
+	report, _ = m.Accessibility("ncaa", "university at buffalo, the state university of new york")
+	backgrounds := report.Eras[0].Backgrounds
+	g.Expect(backgrounds[0].Text.Name).Should(gomega.Equal("White"))
+	g.Expect(backgrounds[0].Text).Should(gomega.BeIdenticalTo(backgrounds[1].Background))
+	g.Expect(backgrounds[1].Text.Name).Should(gomega.Equal("Royal Blue"))
+	g.Expect(backgrounds[1].Ratio).Should(gomega.Equal(report.Eras[0].Pairs[0].Ratio))
+}
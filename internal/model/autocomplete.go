@@ -0,0 +1,150 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"sort"
+	"strings"
+)
+
+// Suggestion types, in the order they are suggested when equally good
+const (
+	SuggestionLeague   = "league"
+	SuggestionDivision = "division"
+	SuggestionTeam     = "team"
+)
+
+var suggestionTypeOrder = map[string]int{
+	SuggestionLeague:   0,
+	SuggestionDivision: 1,
+	SuggestionTeam:     2,
+}
+
+// Suggestion is a league, division or team whose name starts with an
+// autocomplete query
+type Suggestion struct {
+	// Name is the name of the league, division or team
+	Name string `json:"name"`
+	// Type is league, division or team
+	Type string `json:"type"`
+	// League is the league the suggestion is in, or the league itself
+	League string `json:"league"`
+	// Link is a link to retrieve the suggestion
+	Link string `json:"_link"`
+}
+
+// prefixEntry is a normalized key that leads to a suggestion. Every word of
+// a name is a key, so "car" finds Arizona Cardinals, but matches from the
+// start of a name or alias are preferred.
+type prefixEntry struct {
+	key        string
+	suggestion *Suggestion
+	fromStart  bool
+}
+
+// prefixIndex is sorted by key so that every key with a prefix is adjacent
+type prefixIndex []*prefixEntry
+
+func (idx *prefixIndex) add(s *Suggestion, names ...string) {
+	for _, name := range names {
+		key := normalize(name)
+		for offset := 0; len(key) > 0; offset++ {
+			*idx = append(*idx, &prefixEntry{key: key, suggestion: s, fromStart: offset == 0})
+
+			i := strings.IndexByte(key, ' ')
+			if i < 0 {
+				break
+			}
+			key = key[i+1:]
+		}
+	}
+}
+
+func newPrefixIndex(m *Model) prefixIndex {
+	var idx prefixIndex
+	for _, league := range m.leagues {
+		idx.add(&Suggestion{Name: league.League, Type: SuggestionLeague, League: league.League, Link: league.Link}, league.League)
+	}
+
+	for _, ld := range m.teamsByLeague {
+		for _, division := range ld.divisions {
+			idx.add(&Suggestion{Name: division.Division, Type: SuggestionDivision, League: division.League, Link: division.Link}, division.Division)
+		}
+	}
+
+	for _, team := range m.sortedTeams {
+		idx.add(&Suggestion{Name: team.Name, Type: SuggestionTeam, League: team.League, Link: team.Link},
+			append([]string{team.Name}, team.Aliases...)...)
+	}
+
+	sort.Slice(idx, func(i, j int) bool {
+		return idx[i].key < idx[j].key
+	})
+
+	return idx
+}
+
+// Autocomplete returns up to limit leagues, divisions and teams with a name,
+// alias or word in their name that starts with the query. Matches from the
+// start of a name come first, then leagues before divisions before teams,
+// then by name.
+func (m *Model) Autocomplete(query string, limit int) []*Suggestion {
+	suggestions := make([]*Suggestion, 0)
+	query = normalize(query)
+	if query == "" || limit < 1 {
+		return suggestions
+	}
+
+	fromStart := make(map[*Suggestion]bool)
+	start := sort.Search(len(m.prefixIndex), func(i int) bool {
+		return m.prefixIndex[i].key >= query
+	})
+	for _, entry := range m.prefixIndex[start:] {
+		if !strings.HasPrefix(entry.key, query) {
+			break
+		}
+
+		best, seen := fromStart[entry.suggestion]
+		if !seen {
+			suggestions = append(suggestions, entry.suggestion)
+		}
+		fromStart[entry.suggestion] = best || entry.fromStart
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if fromStart[a] != fromStart[b] {
+			return fromStart[a]
+		}
+
+		if ta, tb := suggestionTypeOrder[a.Type], suggestionTypeOrder[b.Type]; ta != tb {
+			return ta < tb
+		}
+
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+
+		return a.League < b.League
+	})
+
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+
+	return suggestions
+}
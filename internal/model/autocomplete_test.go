@@ -0,0 +1,76 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+func suggestionNames(suggestions []*Suggestion) []string {
+	names := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		names[i] = s.Name
+	}
+
+	return names
+}
+
+func TestAutocomplete(t *testing.T) {
+	g := gomega.NewWithT(t)
+	m, _ := New(testFile)
+
+	g.Expect(m.Autocomplete("Buffalo S", 8)).Should(gomega.Equal([]*Suggestion{{
+		Name:   "Buffalo Sabres",
+		Type:   SuggestionTeam,
+		League: "NHL",
+		Link:   "/leagues/nhl/buffalo-sabres",
+	}}))
+
+	// matches from the start of a name come before matches on a later word
+	g.Expect(suggestionNames(m.Autocomplete("buf", 8))).Should(gomega.Equal([]string{
+		"Buffalo Bills",
+		"Buffalo Sabres",
+		"University At Buffalo, The State University Of New York",
+	}))
+
+	// leagues come before divisions come before teams
+	g.Expect(suggestionNames(m.Autocomplete("n", 8))).Should(gomega.Equal([]string{
+		"NCAA",
+		"NFL",
+		"NHL",
+		"University At Buffalo, The State University Of New York",
+	}))
+	g.Expect(suggestionNames(m.Autocomplete("a", 8))).Should(gomega.Equal([]string{"AFC", "Mid-American Conference", "University At Buffalo, The State University Of New York"}))
+	g.Expect(m.Autocomplete("mid-am", 8)[0].Link).Should(gomega.Equal("/leagues/ncaa/divisions/mid-american-conference"))
+
+	g.Expect(m.Autocomplete("u", 1)).Should(gomega.HaveLen(1))
+	g.Expect(m.Autocomplete("xyz", 8)).Should(gomega.BeEmpty())
+	g.Expect(m.Autocomplete(" ", 8)).Should(gomega.BeEmpty())
+}
+
+func BenchmarkAutocomplete(b *testing.B) {
+	m, err := New("../../configs/teamhex.json")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for i := 0; i < b.N; i++ {
+		m.Autocomplete("ca", 8)
+	}
+}
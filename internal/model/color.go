@@ -0,0 +1,221 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Black is the darkest possible text color
+var Black = RGB{0, 0, 0}
+
+// White is the lightest possible text color
+var White = RGB{255, 255, 255}
+
+// RGB is a color in the sRGB color space
+type RGB struct {
+	R uint8 `json:"r"`
+	G uint8 `json:"g"`
+	B uint8 `json:"b"`
+}
+
+// ParseHex parses a color in the form #RRGGBB
+func ParseHex(hex string) (RGB, error) {
+	if !hexPattern.MatchString(hex) {
+		return RGB{}, fmt.Errorf("model: invalid hex color %q", hex)
+	}
+
+	v, err := strconv.ParseUint(hex[1:], 16, 32)
+	if err != nil {
+		return RGB{}, err
+	}
+
+	return RGB{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v)}, nil
+}
+
+// Hex returns the color in the form #RRGGBB
+func (c RGB) Hex() string {
+	return fmt.Sprintf("#%02X%02X%02X", c.R, c.G, c.B)
+}
+
+// linear converts an 8-bit sRGB channel to linear light
+func linear(channel uint8) float64 {
+	v := float64(channel) / 255
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// RelativeLuminance returns the relative luminance of the color as defined
+// by WCAG 2.1, from 0 for black to 1 for white
+func (c RGB) RelativeLuminance() float64 {
+	return 0.2126*linear(c.R) + 0.7152*linear(c.G) + 0.0722*linear(c.B)
+}
+
+// ContrastRatio returns the WCAG 2.1 contrast ratio between two colors, from
+// 1 for identical colors to 21 for black on white
+func ContrastRatio(a, b RGB) float64 {
+	l1, l2 := a.RelativeLuminance(), b.RelativeLuminance()
+	if l1 < l2 {
+		l1, l2 = l2, l1
+	}
+
+	return (l1 + 0.05) / (l2 + 0.05)
+}
+
+// TextColor returns black or white, whichever is more readable on the background
+func TextColor(background RGB) RGB {
+	if ContrastRatio(background, Black) >= ContrastRatio(background, White) {
+		return Black
+	}
+
+	return White
+}
+
+// Lab is a color in the CIE L*a*b* color space with a D65 white point
+type Lab struct {
+	L float64 `json:"l"`
+	A float64 `json:"a"`
+	B float64 `json:"b"`
+}
+
+// D65 reference white
+const (
+	whiteX = 0.95047
+	whiteY = 1.0
+	whiteZ = 1.08883
+)
+
+// XYZ returns the color in the CIE 1931 XYZ color space, scaled so that white
+// has a Y of 1
+func (c RGB) XYZ() (x, y, z float64) {
+	r, g, b := linear(c.R), linear(c.G), linear(c.B)
+	x = 0.4124564*r + 0.3575761*g + 0.1804375*b
+	y = 0.2126729*r + 0.7151522*g + 0.0721750*b
+	z = 0.0193339*r + 0.1191920*g + 0.9503041*b
+	return x, y, z
+}
+
+// Lab returns the color in the CIE L*a*b* color space
+func (c RGB) Lab() Lab {
+	x, y, z := c.XYZ()
+	fx, fy, fz := labF(x/whiteX), labF(y/whiteY), labF(z/whiteZ)
+	return Lab{
+		L: 116*fy - 16,
+		A: 500 * (fx - fy),
+		B: 200 * (fy - fz),
+	}
+}
+
+func labF(t float64) float64 {
+	const delta = 6.0 / 29
+	if t > delta*delta*delta {
+		return math.Cbrt(t)
+	}
+
+	return t/(3*delta*delta) + 4.0/29
+}
+
+// DeltaE2000 returns the CIEDE2000 difference between two colors. A
+// difference under about 2 is hard to notice, and one over 10 means the
+// colors are clearly distinct.
+func DeltaE2000(x, y Lab) float64 {
+	const pow25to7 = 6103515625 // 25^7
+
+	c1 := math.Hypot(x.A, x.B)
+	c2 := math.Hypot(y.A, y.B)
+	cBar7 := math.Pow((c1+c2)/2, 7)
+	gFactor := 0.5 * (1 - math.Sqrt(cBar7/(cBar7+pow25to7)))
+
+	a1, a2 := (1+gFactor)*x.A, (1+gFactor)*y.A
+	c1, c2 = math.Hypot(a1, x.B), math.Hypot(a2, y.B)
+	h1, h2 := hueAngle(x.B, a1), hueAngle(y.B, a2)
+
+	dL := y.L - x.L
+	dC := c2 - c1
+
+	var dh float64
+	switch {
+	case c1*c2 == 0:
+		dh = 0
+	case math.Abs(h2-h1) <= 180:
+		dh = h2 - h1
+	case h2-h1 > 180:
+		dh = h2 - h1 - 360
+	default:
+		dh = h2 - h1 + 360
+	}
+	dH := 2 * math.Sqrt(c1*c2) * math.Sin(radians(dh/2))
+
+	lBar := (x.L + y.L) / 2
+	cBar := (c1 + c2) / 2
+
+	var hBar float64
+	switch {
+	case c1*c2 == 0:
+		hBar = h1 + h2
+	case math.Abs(h1-h2) <= 180:
+		hBar = (h1 + h2) / 2
+	case h1+h2 < 360:
+		hBar = (h1 + h2 + 360) / 2
+	default:
+		hBar = (h1 + h2 - 360) / 2
+	}
+
+	t := 1 -
+		0.17*math.Cos(radians(hBar-30)) +
+		0.24*math.Cos(radians(2*hBar)) +
+		0.32*math.Cos(radians(3*hBar+6)) -
+		0.20*math.Cos(radians(4*hBar-63))
+
+	lBar50 := (lBar - 50) * (lBar - 50)
+	sL := 1 + 0.015*lBar50/math.Sqrt(20+lBar50)
+	sC := 1 + 0.045*cBar
+	sH := 1 + 0.015*cBar*t
+
+	cBar7 = math.Pow(cBar, 7)
+	dTheta := 30 * math.Exp(-math.Pow((hBar-275)/25, 2))
+	rT := -2 * math.Sqrt(cBar7/(cBar7+pow25to7)) * math.Sin(radians(2*dTheta))
+
+	return math.Sqrt(
+		math.Pow(dL/sL, 2) +
+			math.Pow(dC/sC, 2) +
+			math.Pow(dH/sH, 2) +
+			rT*(dC/sC)*(dH/sH))
+}
+
+// hueAngle returns the angle of (a, b) in degrees from 0 to 360
+func hueAngle(b, a float64) float64 {
+	if a == 0 && b == 0 {
+		return 0
+	}
+
+	h := math.Atan2(b, a) * 180 / math.Pi
+	if h < 0 {
+		h += 360
+	}
+
+	return h
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+func TestParseHex(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	c, err := ParseHex("#9B2743")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(c).Should(gomega.Equal(RGB{R: 0x9B, G: 0x27, B: 0x43}))
+	g.Expect(c.Hex()).Should(gomega.Equal("#9B2743"))
+
+	c, err = ParseHex("#ffffff")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(c).Should(gomega.Equal(White))
+
+	_, err = ParseHex("#9B274")
+	g.Expect(err).Should(gomega.MatchError(`model: invalid hex color "#9B274"`))
+}
+
+func TestContrastRatio(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	g.Expect(ContrastRatio(Black, White)).Should(gomega.BeNumerically("~", 21, 0.001))
+	g.Expect(ContrastRatio(White, White)).Should(gomega.BeNumerically("~", 1, 0.001))
+
+	// the lightest grays that do and do not pass WCAG AA on white
+	gray, _ := ParseHex("#767676")
+	g.Expect(ContrastRatio(gray, White)).Should(gomega.BeNumerically("~", 4.54, 0.01))
+	gray, _ = ParseHex("#777777")
+	g.Expect(ContrastRatio(White, gray)).Should(gomega.BeNumerically("~", 4.48, 0.01))
+
+	navy, _ := ParseHex("#041E42")
+
+	g.Expect(TextColor(navy)).Should(gomega.Equal(White))
+	yellow, _ := ParseHex("#FFB612")
+	g.Expect(TextColor(yellow)).Should(gomega.Equal(Black))
+}
+
+func TestLab(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	lab := White.Lab()
+	g.Expect(lab.L).Should(gomega.BeNumerically("~", 100, 0.01))
+	g.Expect(lab.A).Should(gomega.BeNumerically("~", 0, 0.01))
+	g.Expect(lab.B).Should(gomega.BeNumerically("~", 0, 0.01))
+
+	lab = RGB{R: 255}.Lab()
+	g.Expect(lab.L).Should(gomega.BeNumerically("~", 53.24, 0.01))
+	g.Expect(lab.A).Should(gomega.BeNumerically("~", 80.09, 0.01))
+	g.Expect(lab.B).Should(gomega.BeNumerically("~", 67.20, 0.01))
+}
+
+func TestDeltaE2000(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	// test data from Sharma, Wu and Dalal, "The CIEDE2000 Color-Difference Formula"
+	tests := []struct {
+		x, y     Lab
+		expected float64
+	}{
+		{Lab{50, 2.6772, -79.7751}, Lab{50, 0, -82.7485}, 2.0425},
+		{Lab{50, 0, 0}, Lab{50, -1, 2}, 2.3669},
+		{Lab{50, 2.49, -0.001}, Lab{50, -2.49, 0.0009}, 7.1792},
+		{Lab{50, 2.5, 0}, Lab{73, 25, -18}, 27.1492},
+		{Lab{60.2574, -34.0099, 36.2677}, Lab{60.4626, -34.1751, 39.4387}, 1.2644},
+		{Lab{22.7233, 20.0904, -46.6940}, Lab{23.0331, 14.9730, -42.5619}, 2.0373},
+	}
+
+	for _, test := range tests {
+		g.Expect(DeltaE2000(test.x, test.y)).Should(gomega.BeNumerically("~", test.expected, 0.0001), "%v %v", test.x, test.y)
+		g.Expect(DeltaE2000(test.y, test.x)).Should(gomega.BeNumerically("~", test.expected, 0.0001), "%v %v", test.y, test.x)
+	}
+
+	g.Expect(DeltaE2000(White.Lab(), White.Lab())).Should(gomega.BeNumerically("==", 0))
+}
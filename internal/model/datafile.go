@@ -0,0 +1,466 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrTeamExists represents an error when a team with the same name is already in the league
+var ErrTeamExists = errors.New("model: team already exists")
+
+// ErrEraExists represents an error when the team already has an era for the year
+var ErrEraExists = errors.New("model: era already exists")
+
+// ErrIDChanged represents an error when a change would give a team a different ID
+var ErrIDChanged = errors.New("model: a team's id cannot be changed")
+
+// ErrIDUsed represents an error when a new team would be given an ID another team has had
+var ErrIDUsed = errors.New("model: id has already been used")
+
+// ErrColorNotFound represents an error when the era has no color with the name
+var ErrColorNotFound = errors.New("model: color not found")
+
+// DataFile represents how the file is stored on disk
+type DataFile struct {
+	Generated time.Time `json:"generated"`
+	Teams     Teams     `json:"teams"`
+	// NextID is the ID the next new team is given. It only ever increases,
+	// so the ID of a deleted team is never given to another.
+	NextID int `json:"nextId,omitempty"`
+}
+
+// fileLayout mirrors DataFile in the order the file is authored. Computed
+// fields such as Team.Slug, Team.Link and Color.Formats are left out.
+type fileLayout struct {
+	Teams     []*fileTeam `json:"teams"`
+	NextID    int         `json:"nextId"`
+	Generated time.Time   `json:"generated"`
+}
+
+type fileTeam struct {
+	ID           int         `json:"id"`
+	Name         string      `json:"name"`
+	Eras         []*Era      `json:"eras"`
+	League       string      `json:"league"`
+	Division     string      `json:"division,omitempty"`
+	Aliases      []string    `json:"aliases,omitempty"`
+	FormerNames  []string    `json:"formerNames,omitempty"`
+	Predecessors []*Identity `json:"predecessors,omitempty"`
+}
+
+// Migrate brings data written by older versions up to date. Eras without a
+// start date are given January 1 of their year, and NextID is raised above
+// every team's ID.
+func (d *DataFile) Migrate() {
+	d.raiseNextID()
+	for _, team := range d.Teams {
+		if team == nil {
+			continue
+		}
+
+		migrateEras(team.Eras)
+		for _, identity := range team.Predecessors {
+			if identity != nil {
+				migrateEras(identity.Eras)
+			}
+		}
+	}
+}
+
+func migrateEras(eras []*Era) {
+	for _, era := range eras {
+		if era != nil && era.Start.IsZero() && era.Year > 0 {
+			era.Start = era.StartDate()
+		}
+	}
+}
+
+// Encode writes the data file in the same layout it is authored in, so that
+// re-encoding an unchanged file produces identical output. The data is
+// migrated first.
+func (d *DataFile) Encode(w io.Writer) error {
+	d.Migrate()
+	layout := fileLayout{
+		Teams:     make([]*fileTeam, len(d.Teams)),
+		NextID:    d.NextID,
+		Generated: d.Generated,
+	}
+
+	for i, team := range d.Teams {
+		layout.Teams[i] = &fileTeam{
+			ID:          team.ID,
+			Name:        team.Name,
+			Eras:        authoredEras(team.Eras),
+			League:      team.League,
+			Division:    team.Division,
+			Aliases:     team.Aliases,
+			FormerNames: team.FormerNames,
+		}
+
+		for _, identity := range team.Predecessors {
+			if identity != nil {
+				copied := *identity
+				copied.Eras = authoredEras(identity.Eras)
+				identity = &copied
+			}
+			layout.Teams[i].Predecessors = append(layout.Teams[i].Predecessors, identity)
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(layout)
+}
+
+// authoredEras returns the eras without any computed color formats
+func authoredEras(eras []*Era) []*Era {
+	authored := make([]*Era, len(eras))
+	for i, era := range eras {
+		if era == nil {
+			continue
+		}
+
+		copied := *era
+		copied.Colors = make([]*Color, len(era.Colors))
+		for j, color := range era.Colors {
+			if color != nil {
+				c := *color
+				c.Formats = nil
+				copied.Colors[j] = &c
+			}
+		}
+		authored[i] = &copied
+	}
+
+	return authored
+}
+
+// Save writes the data file to filename. The file is replaced atomically so
+// a running server never reads a partially written file.
+func (d *DataFile) Save(filename string) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(filename); err == nil {
+		mode = info.Mode()
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(filename), "."+filepath.Base(filename)+".")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := d.Encode(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filename)
+}
+
+// Team returns the team in the league with the name or slug. Former names
+// are not matched.
+func (d *DataFile) Team(league, name string) (*Team, error) {
+	leagueFound := false
+	slug := Slugify(name)
+	for _, team := range d.Teams {
+		if !strings.EqualFold(team.League, league) {
+			continue
+		}
+
+		leagueFound = true
+		if Slugify(team.Name) == slug {
+			return team, nil
+		}
+	}
+
+	if !leagueFound {
+		return nil, ErrLeagueNotFound
+	}
+
+	return nil, ErrTeamNotFound
+}
+
+// newID returns an ID that no team in the file has ever had
+func (d *DataFile) newID() int {
+	d.raiseNextID()
+	id := d.NextID
+	d.NextID++
+	return id
+}
+
+// raiseNextID makes NextID greater than every team's ID, which files written
+// before it was kept need
+func (d *DataFile) raiseNextID() {
+	if d.NextID < 1 {
+		d.NextID = 1
+	}
+
+	for _, team := range d.Teams {
+		if team != nil && team.ID >= d.NextID {
+			d.NextID = team.ID + 1
+		}
+	}
+}
+
+// AddTeam adds a team after the last team in the same league, or at the end
+// if the league is new. The league is spelled the same as the existing teams
+// in it. A team without an ID is given the next one; ErrIDUsed is returned
+// if its ID is one that has already been given out.
+func (d *DataFile) AddTeam(team *Team) error {
+	if _, err := d.Team(team.League, team.Name); err == nil {
+		return ErrTeamExists
+	}
+
+	d.raiseNextID()
+	if team.ID == 0 {
+		team.ID = d.newID()
+	} else if team.ID < d.NextID {
+		return ErrIDUsed
+	} else {
+		d.NextID = team.ID + 1
+	}
+
+	i := len(d.Teams)
+	for j, t := range d.Teams {
+		if strings.EqualFold(t.League, team.League) {
+			team.League = t.League
+			i = j + 1
+		}
+	}
+
+	d.Teams = append(d.Teams, nil)
+	copy(d.Teams[i+1:], d.Teams[i:])
+	d.Teams[i] = team
+	return nil
+}
+
+// AddEra adds an era to a team, keeping the eras ordered newest first. An
+// era without a start date starts on January 1 of its year.
+func (d *DataFile) AddEra(league, name string, era *Era) error {
+	team, err := d.Team(league, name)
+	if err != nil {
+		return err
+	}
+
+	migrateEras([]*Era{era})
+	i := len(team.Eras)
+	for j, e := range team.Eras {
+		if eraKey(e) == eraKey(era) {
+			return ErrEraExists
+		}
+
+		if e.StartDate().Before(era.StartDate().Time) && j < i {
+			i = j
+		}
+	}
+
+	team.Eras = append(team.Eras, nil)
+	copy(team.Eras[i+1:], team.Eras[i:])
+	team.Eras[i] = era
+	return nil
+}
+
+// SetColor changes the hex value of the named color in a team's era, along
+// with its role and any published values such as Pantone that color has. The
+// era is the one the key identifies; see Team.Era. If add is true and the era
+// has no color with that name, the color is appended.
+func (d *DataFile) SetColor(league, name string, key EraKey, color *Color, add bool) error {
+	team, err := d.Team(league, name)
+	if err != nil {
+		return err
+	}
+
+	era, err := team.Era(key)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range era.Colors {
+		if strings.EqualFold(c.Name, color.Name) {
+			c.Hex = color.Hex
+			if color.Pantone != "" {
+				c.Pantone = color.Pantone
+			}
+			if color.CMYK != nil {
+				c.CMYK = color.CMYK
+			}
+			if color.RGB != nil {
+				c.RGB = color.RGB
+			}
+			if color.Source != "" {
+				c.Source = color.Source
+			}
+			if color.Role != "" {
+				c.Role = color.Role
+			}
+			return nil
+		}
+	}
+
+	if !add {
+		return ErrColorNotFound
+	}
+
+	era.Colors = append(era.Colors, color)
+	return nil
+}
+
+// RenameTeam changes the name of a team. Unless only its spelling changed,
+// the old name is added to the team's former names so links to it keep
+// working.
+func (d *DataFile) RenameTeam(league, name, newName string) error {
+	team, err := d.Team(league, name)
+	if err != nil {
+		return err
+	}
+
+	if existing, err := d.Team(league, newName); err == nil && existing != team {
+		return ErrTeamExists
+	}
+
+	oldName := team.Name
+	team.Name = newName
+	if Slugify(oldName) == Slugify(newName) {
+		return nil
+	}
+
+	// a team renamed back to a former name no longer needs it
+	var formerNames []string
+	for _, formerName := range team.FormerNames {
+		if Slugify(formerName) != Slugify(newName) && Slugify(formerName) != Slugify(oldName) {
+			formerNames = append(formerNames, formerName)
+		}
+	}
+	team.FormerNames = append(formerNames, oldName)
+
+	return nil
+}
+
+// PutTeam replaces the team with the same name in the league, keeping its
+// place in the file, or adds it if there is none. created reports whether
+// the team was added. A replaced team keeps its ID, former names and
+// predecessors unless the new team has them; ErrIDChanged is returned if its
+// ID is different.
+func (d *DataFile) PutTeam(team *Team) (created bool, err error) {
+	slug := Slugify(team.Name)
+	for i, t := range d.Teams {
+		if strings.EqualFold(t.League, team.League) && Slugify(t.Name) == slug {
+			if team.ID == 0 {
+				team.ID = t.ID
+			} else if team.ID != t.ID {
+				return false, ErrIDChanged
+			}
+
+			if team.FormerNames == nil {
+				team.FormerNames = t.FormerNames
+			}
+
+			if team.Predecessors == nil {
+				team.Predecessors = t.Predecessors
+			}
+
+			team.League = t.League
+			d.Teams[i] = team
+			return false, nil
+		}
+	}
+
+	if err := d.AddTeam(team); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// RemoveTeam removes a team
+func (d *DataFile) RemoveTeam(league, name string) error {
+	team, err := d.Team(league, name)
+	if err != nil {
+		return err
+	}
+
+	for i, t := range d.Teams {
+		if t == team {
+			d.Teams = append(d.Teams[:i], d.Teams[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+// PutEra replaces the team's era the key identifies, or adds the era if
+// there is none. created reports whether the era was added.
+func (d *DataFile) PutEra(league, name string, key EraKey, era *Era) (created bool, err error) {
+	team, err := d.Team(league, name)
+	if err != nil {
+		return false, err
+	}
+
+	existing, err := team.Era(key)
+	if err == ErrEraNotFound {
+		return true, d.AddEra(league, name, era)
+	} else if err != nil {
+		return false, err
+	}
+
+	// the era is added again in case its start date moved
+	removeEra(team, existing)
+	return false, d.AddEra(league, name, era)
+}
+
+// RemoveEra removes the team's era the key identifies
+func (d *DataFile) RemoveEra(league, name string, key EraKey) error {
+	team, err := d.Team(league, name)
+	if err != nil {
+		return err
+	}
+
+	era, err := team.Era(key)
+	if err != nil {
+		return err
+	}
+
+	removeEra(team, era)
+	return nil
+}
+
+func removeEra(team *Team, era *Era) {
+	for i, e := range team.Eras {
+		if e == era {
+			team.Eras = append(team.Eras[:i], team.Eras[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,238 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+)
+
+func TestEncode(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	data, err := Load(testFile)
+	g.Expect(err).Should(gomega.BeNil())
+
+	// loading a model must not reorder the file
+	NewFromDataFile(data)
+	g.Expect(data.Teams[0].Name).Should(gomega.Equal("The Ohio State University"))
+
+	var buf bytes.Buffer
+	g.Expect(data.Encode(&buf)).Should(gomega.Succeed())
+	encoded := buf.String()
+	g.Expect(encoded).Should(gomega.HavePrefix("{\n  \"teams\": [\n    {\n      \"id\": 1,\n      \"name\": \"The Ohio State University\",\n"))
+	g.Expect(encoded).Should(gomega.HaveSuffix("  \"nextId\": 20,\n  \"generated\": \"2020-02-22T12:00:00Z\"\n}\n"))
+	g.Expect(encoded).Should(gomega.ContainSubstring("\"id\": 19,"))
+	g.Expect(encoded).ShouldNot(gomega.ContainSubstring("_link"))
+	g.Expect(encoded).ShouldNot(gomega.ContainSubstring("slug"))
+
+	reparsed, err := Parse(buf.Bytes())
+	g.Expect(err).Should(gomega.BeNil())
+
+	buf.Reset()
+	g.Expect(reparsed.Encode(&buf)).Should(gomega.Succeed())
+	g.Expect(buf.String()).Should(gomega.Equal(encoded))
+
+	// computed color formats are not saved
+	reparsed.Teams[0], err = reparsed.Teams[0].WithColorFormats(ColorFormatNames)
+	g.Expect(err).Should(gomega.BeNil())
+	buf.Reset()
+	g.Expect(reparsed.Encode(&buf)).Should(gomega.Succeed())
+	g.Expect(buf.String()).Should(gomega.Equal(encoded))
+}
+
+func TestEncodeMigrates(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	data := &DataFile{Generated: time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC), Teams: Teams{
+		{ID: 1, Name: "Buffalo Bills", League: "NFL", Eras: []*Era{{Year: 2002, Colors: []*Color{{Name: "Midnight Navy", Hex: "#091F2C"}}}}},
+	}}
+
+	var buf bytes.Buffer
+	g.Expect(data.Encode(&buf)).Should(gomega.Succeed())
+	g.Expect(buf.String()).Should(gomega.ContainSubstring(`"year": 2002,
+          "start": "2002-01-01",
+          "colors": [`))
+	g.Expect(buf.String()).ShouldNot(gomega.ContainSubstring(`"end"`))
+	g.Expect(buf.String()).ShouldNot(gomega.ContainSubstring(`"label"`))
+}
+
+func TestEncodePredecessors(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	data, _ := Load(testFile)
+	data.Teams[1].Predecessors = []*Identity{{Name: "Buffalo Bulls", City: "Buffalo", From: 1946, To: 1946, Eras: []*Era{
+		{Year: 1946, Colors: []*Color{{Name: "Blue", Hex: "#00338D"}}},
+	}}}
+	team, err := data.Teams[1].WithColorFormats(ColorFormatNames)
+	g.Expect(err).Should(gomega.BeNil())
+	data.Teams[1] = team
+
+	var buf bytes.Buffer
+	g.Expect(data.Encode(&buf)).Should(gomega.Succeed())
+	g.Expect(buf.String()).Should(gomega.ContainSubstring(`"predecessors": [`))
+	g.Expect(buf.String()).ShouldNot(gomega.ContainSubstring(`"formats"`))
+
+	reparsed, err := Parse(buf.Bytes())
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(reparsed.Teams[1].Predecessors[0].Name).Should(gomega.Equal("Buffalo Bulls"))
+	g.Expect(reparsed.Teams[1].Predecessors[0].Eras[0].Colors[0].Hex).Should(gomega.Equal("#00338D"))
+}
+
+func TestSave(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	dir, err := ioutil.TempDir("", "teamhex")
+	g.Expect(err).Should(gomega.BeNil())
+	defer os.RemoveAll(dir)
+
+	data, _ := Load(testFile)
+	filename := filepath.Join(dir, "teamhex.json")
+	g.Expect(data.Save(filename)).Should(gomega.Succeed())
+
+	saved, err := Load(filename)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(saved).Should(gomega.Equal(data))
+
+	files, _ := ioutil.ReadDir(dir)
+	g.Expect(len(files)).Should(gomega.Equal(1))
+}
+
+func TestDataFileEdits(t *testing.T) {
+	g := gomega.NewWithT(t)
+	data, _ := Load(testFile)
+
+	g.Expect(data.AddTeam(&Team{Name: "buffalo bills", League: "nfl"})).Should(gomega.MatchError(ErrTeamExists))
+	g.Expect(data.AddTeam(&Team{Name: "Kent State University", League: "ncaa"})).Should(gomega.Succeed())
+	g.Expect(data.Teams[3].Name).Should(gomega.Equal("Kent State University"))
+	g.Expect(data.Teams[3].League).Should(gomega.Equal("NCAA"))
+	g.Expect(data.Teams[3].ID).Should(gomega.Equal(20))
+	g.Expect(data.AddTeam(&Team{Name: "Buffalo-Bills", League: "nfl"})).Should(gomega.MatchError(ErrTeamExists))
+
+	g.Expect(data.AddEra("NFL", "Buffalo Bills", &Era{Year: 2011})).Should(gomega.MatchError(ErrEraExists))
+	g.Expect(data.AddEra("NFL", "Buffalo Bills", &Era{Year: 2005})).Should(gomega.Succeed())
+	g.Expect(data.AddEra("NFL", "Buffalo Bills", &Era{Year: 2020})).Should(gomega.Succeed())
+	g.Expect(data.AddEra("NFL", "Buffalo Bills", &Era{Year: 1960})).Should(gomega.Succeed())
+	team, _ := data.Team("nfl", "buffalo bills")
+	years := make([]int, len(team.Eras))
+	for i, era := range team.Eras {
+		years[i] = era.Year
+	}
+	g.Expect(years).Should(gomega.Equal([]int{2020, 2011, 2005, 2002, 1960}))
+
+	g.Expect(data.SetColor("NFL", "Buffalo Bills", EraKey{Year: 1999}, &Color{Name: "Royal Blue"}, false)).Should(gomega.MatchError(ErrEraNotFound))
+	g.Expect(data.SetColor("NFL", "Buffalo Bills", EraKey{Year: 2011}, &Color{Name: "Purple", Hex: "#800080"}, false)).Should(gomega.MatchError(ErrColorNotFound))
+	g.Expect(data.SetColor("NFL", "Buffalo Bills", EraKey{Year: 2011}, &Color{Name: "royal blue", Hex: "#00338D", Pantone: "PMS 661 C"}, false)).Should(gomega.Succeed())
+	// published values are kept unless they are given
+	g.Expect(data.SetColor("NFL", "Buffalo Bills", EraKey{Year: 2011}, &Color{Name: "royal blue", Hex: "#00338D"}, false)).Should(gomega.Succeed())
+	g.Expect(data.SetColor("NFL", "Buffalo Bills", EraKey{Year: 2011}, &Color{Name: "White", Hex: "#FFFFFF"}, true)).Should(gomega.Succeed())
+	g.Expect(team.Eras[1].Colors).Should(gomega.Equal([]*Color{
+		{Name: "Royal Blue", Hex: "#00338D", Pantone: "PMS 661 C"},
+		{Name: "Scarlet Red", Hex: "#C8102E"},
+		{Name: "White", Hex: "#FFFFFF"},
+	}))
+
+	g.Expect(data.RenameTeam("NHL", "Buffalo Bills", "Buffalo Bisons")).Should(gomega.MatchError(ErrTeamNotFound))
+	g.Expect(data.RenameTeam("NCAA", "Kent State University", "The Ohio State University")).Should(gomega.MatchError(ErrTeamExists))
+	g.Expect(data.RenameTeam("NHL", "buffalo sabres", "Buffalo Sabres")).Should(gomega.Succeed())
+	sabres, _ := data.Team("nhl", "buffalo-sabres")
+	g.Expect(sabres.FormerNames).Should(gomega.BeNil())
+
+	g.Expect(data.RenameTeam("NFL", "buffalo-bills", "Buffalo Bisons")).Should(gomega.Succeed())
+	g.Expect(team.Name).Should(gomega.Equal("Buffalo Bisons"))
+	g.Expect(data.RenameTeam("NFL", "Buffalo Bisons", "Buffalo Braves")).Should(gomega.Succeed())
+	g.Expect(team.FormerNames).Should(gomega.Equal([]string{"Buffalo Bills", "Buffalo Bisons"}))
+	g.Expect(data.RenameTeam("NFL", "Buffalo Braves", "Buffalo Bills")).Should(gomega.Succeed())
+	g.Expect(team.FormerNames).Should(gomega.Equal([]string{"Buffalo Bisons", "Buffalo Braves"}))
+
+	_, err := data.Team("MLS", "Buffalo Bills")
+	g.Expect(err).Should(gomega.MatchError(ErrLeagueNotFound))
+}
+
+func TestDataFilePutAndRemove(t *testing.T) {
+	g := gomega.NewWithT(t)
+	data, _ := Load(testFile)
+
+	created, err := data.PutTeam(&Team{Name: "buffalo bills", League: "nfl", Eras: []*Era{{Year: 2020}}})
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(created).Should(gomega.BeFalse())
+	g.Expect(data.Teams[1].Name).Should(gomega.Equal("buffalo bills"))
+	g.Expect(data.Teams[1].League).Should(gomega.Equal("NFL"))
+	g.Expect(data.Teams[1].Eras).Should(gomega.HaveLen(1))
+	g.Expect(data.Teams[1].ID).Should(gomega.Equal(2))
+
+	predecessors := []*Identity{{Name: "Buffalo Bulls", From: 1946, To: 1946}}
+	data.Teams[1].Predecessors = predecessors
+	_, err = data.PutTeam(&Team{Name: "Buffalo Bills", League: "NFL", Eras: data.Teams[1].Eras})
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(data.Teams[1].Predecessors).Should(gomega.Equal(predecessors))
+
+	created, err = data.PutTeam(&Team{ID: 3, Name: "Buffalo Bills", League: "NFL"})
+	g.Expect(err).Should(gomega.MatchError(ErrIDChanged))
+	g.Expect(created).Should(gomega.BeFalse())
+
+	created, err = data.PutTeam(&Team{Name: "Buffalo Bandits", League: "NLL"})
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(created).Should(gomega.BeTrue())
+	g.Expect(data.Teams[4].ID).Should(gomega.Equal(20))
+	g.Expect(data.Teams).Should(gomega.HaveLen(5))
+
+	g.Expect(data.RemoveTeam("nll", "buffalo bandits")).Should(gomega.Succeed())
+	g.Expect(data.Teams).Should(gomega.HaveLen(4))
+	g.Expect(data.RemoveTeam("nll", "buffalo bandits")).Should(gomega.MatchError(ErrLeagueNotFound))
+
+	// the removed team's ID is never given to another team
+	g.Expect(data.NextID).Should(gomega.Equal(21))
+	created, err = data.PutTeam(&Team{ID: 20, Name: "Buffalo Bandits", League: "NLL"})
+	g.Expect(err).Should(gomega.MatchError(ErrIDUsed))
+	g.Expect(created).Should(gomega.BeFalse())
+	g.Expect(data.Teams).Should(gomega.HaveLen(4))
+
+	created, err = data.PutTeam(&Team{Name: "Buffalo Bandits", League: "NLL"})
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(created).Should(gomega.BeTrue())
+	g.Expect(data.Teams[4].ID).Should(gomega.Equal(21))
+	g.Expect(data.RemoveTeam("nll", "buffalo bandits")).Should(gomega.Succeed())
+
+	created, err = data.PutTeam(&Team{ID: 30, Name: "Buffalo Bandits", League: "NLL"})
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(created).Should(gomega.BeTrue())
+	g.Expect(data.NextID).Should(gomega.Equal(31))
+	g.Expect(data.RemoveTeam("nll", "buffalo bandits")).Should(gomega.Succeed())
+
+	created, err = data.PutEra("NFL", "Buffalo Bills", EraKey{Year: 2020}, &Era{Year: 2020, Colors: []*Color{{Name: "Royal Blue", Hex: "#00338D"}}})
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(created).Should(gomega.BeFalse())
+
+	created, err = data.PutEra("NFL", "Buffalo Bills", EraKey{Year: 1960}, &Era{Year: 1960})
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(created).Should(gomega.BeTrue())
+
+	team, _ := data.Team("nfl", "buffalo bills")
+	g.Expect(team.Eras).Should(gomega.HaveLen(2))
+	g.Expect(team.Eras[0].Colors[0].Hex).Should(gomega.Equal("#00338D"))
+
+	g.Expect(data.RemoveEra("NFL", "Buffalo Bills", EraKey{Year: 1960})).Should(gomega.Succeed())
+	g.Expect(data.RemoveEra("NFL", "Buffalo Bills", EraKey{Year: 1960})).Should(gomega.MatchError(ErrEraNotFound))
+	g.Expect(team.Eras).Should(gomega.HaveLen(1))
+}
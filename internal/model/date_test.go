@@ -0,0 +1,45 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+)
+
+func TestDateJSON(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	b, err := json.Marshal(struct {
+		Start Date  `json:"start"`
+		End   *Date `json:"end"`
+		None  Date  `json:"none"`
+	}{Start: NewDate(2020, time.October, 15)})
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(string(b)).Should(gomega.Equal(`{"start":"2020-10-15","end":null,"none":null}`))
+
+	var era Era
+	g.Expect(json.Unmarshal([]byte(`{"year": 2020, "start": "2020-10-15", "end": "2021-06-30"}`), &era)).Should(gomega.Succeed())
+	g.Expect(era.Start).Should(gomega.Equal(NewDate(2020, time.October, 15)))
+	g.Expect(*era.End).Should(gomega.Equal(NewDate(2021, time.June, 30)))
+
+	g.Expect(json.Unmarshal([]byte(`{"start": "2020-13-01"}`), &era)).Should(gomega.MatchError(`model: invalid date "2020-13-01", expected the form YYYY-MM-DD`))
+	g.Expect(json.Unmarshal([]byte(`{"start": 2020}`), &era)).ShouldNot(gomega.Succeed())
+}
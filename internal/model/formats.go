@@ -0,0 +1,253 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// The color formats that can be computed from a hex color
+const (
+	FormatRGB   = "rgb"
+	FormatHSL   = "hsl"
+	FormatCMYK  = "cmyk"
+	FormatLab   = "lab"
+	FormatOKLCH = "oklch"
+)
+
+// ColorFormatNames lists every color format in the order they are reported
+var ColorFormatNames = []string{FormatRGB, FormatHSL, FormatCMYK, FormatLab, FormatOKLCH}
+
+// HSL is a color as hue, saturation and lightness. Hue is in degrees and
+// saturation and lightness are percentages.
+type HSL struct {
+	H float64 `json:"h"`
+	S float64 `json:"s"`
+	L float64 `json:"l"`
+}
+
+// CMYK is a color as the percentages of cyan, magenta, yellow and black ink
+type CMYK struct {
+	C float64 `json:"c"`
+	M float64 `json:"m"`
+	Y float64 `json:"y"`
+	K float64 `json:"k"`
+}
+
+// OKLCH is a color in the OKLCH color space, the polar form of Oklab.
+// Lightness is from 0 to 1 and hue is in degrees.
+type OKLCH struct {
+	L float64 `json:"l"`
+	C float64 `json:"c"`
+	H float64 `json:"h"`
+}
+
+// ColorFormats is a color in each of the requested formats. Formats that
+// were not requested are left out.
+type ColorFormats struct {
+	RGB   *RGB   `json:"rgb,omitempty"`
+	HSL   *HSL   `json:"hsl,omitempty"`
+	CMYK  *CMYK  `json:"cmyk,omitempty"`
+	Lab   *Lab   `json:"lab,omitempty"`
+	OKLCH *OKLCH `json:"oklch,omitempty"`
+}
+
+// ParseColorFormats parses a comma-separated list of color formats, e.g.
+// rgb,hsl. "all" selects every format.
+func ParseColorFormats(s string) ([]string, error) {
+	var formats []string
+	seen := make(map[string]bool)
+	for _, format := range strings.Split(s, ",") {
+		format = strings.ToLower(strings.TrimSpace(format))
+		if format == "all" {
+			return ColorFormatNames, nil
+		}
+
+		if !isColorFormat(format) {
+			return nil, fmt.Errorf("model: unknown color format %q, expected one of %s", format, strings.Join(ColorFormatNames, ", "))
+		}
+
+		if !seen[format] {
+			seen[format] = true
+			formats = append(formats, format)
+		}
+	}
+
+	return formats, nil
+}
+
+func isColorFormat(format string) bool {
+	for _, name := range ColorFormatNames {
+		if format == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HSL returns the color as hue, saturation and lightness
+func (c RGB) HSL() HSL {
+	r, g, b := float64(c.R)/255, float64(c.G)/255, float64(c.B)/255
+	max, min := math.Max(r, math.Max(g, b)), math.Min(r, math.Min(g, b))
+	d := max - min
+
+	hsl := HSL{L: (max + min) / 2 * 100}
+	if d == 0 {
+		return hsl
+	}
+
+	hsl.S = d / (1 - math.Abs(max+min-1)) * 100
+	switch max {
+	case r:
+		hsl.H = math.Mod((g-b)/d+6, 6)
+	case g:
+		hsl.H = (b-r)/d + 2
+	default:
+		hsl.H = (r-g)/d + 4
+	}
+	hsl.H *= 60
+
+	return hsl
+}
+
+// CMYK returns the color as percentages of cyan, magenta, yellow and black.
+// It is a naive conversion without an ICC profile, so print vendors may
+// adjust it for their press.
+func (c RGB) CMYK() CMYK {
+	r, g, b := float64(c.R)/255, float64(c.G)/255, float64(c.B)/255
+	max := math.Max(r, math.Max(g, b))
+	if max == 0 {
+		return CMYK{K: 100}
+	}
+
+	return CMYK{
+		C: (max - r) / max * 100,
+		M: (max - g) / max * 100,
+		Y: (max - b) / max * 100,
+		K: (1 - max) * 100,
+	}
+}
+
+// OKLCH returns the color in the OKLCH color space
+func (c RGB) OKLCH() OKLCH {
+	r, g, b := linear(c.R), linear(c.G), linear(c.B)
+	l := math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*b)
+	m := math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*b)
+	s := math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*b)
+
+	okA := 1.9779984951*l - 2.4285922050*m + 0.4505937099*s
+	okB := 0.0259040371*l + 0.7827717662*m - 0.8086757660*s
+
+	lch := OKLCH{
+		L: 0.2104542553*l + 0.7936177850*m - 0.0040720468*s,
+		C: math.Hypot(okA, okB),
+	}
+
+	// the hue of a gray is meaningless and only reflects rounding errors
+	if lch.C >= 0.0001 {
+		lch.H = math.Mod(math.Atan2(okB, okA)*180/math.Pi+360, 360)
+	}
+
+	return lch
+}
+
+func round(v float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(v*p) / p
+}
+
+// NewColorFormats returns the hex color in each of the formats, rounded to
+// a useful precision
+func NewColorFormats(hex string, formats []string) (*ColorFormats, error) {
+	c, err := ParseHex(hex)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &ColorFormats{}
+	for _, format := range formats {
+		switch format {
+		case FormatRGB:
+			f.RGB = &c
+		case FormatHSL:
+			hsl := c.HSL()
+			f.HSL = &HSL{H: round(hsl.H, 1), S: round(hsl.S, 1), L: round(hsl.L, 1)}
+		case FormatCMYK:
+			cmyk := c.CMYK()
+			f.CMYK = &CMYK{C: round(cmyk.C, 1), M: round(cmyk.M, 1), Y: round(cmyk.Y, 1), K: round(cmyk.K, 1)}
+		case FormatLab:
+			lab := c.Lab()
+			f.Lab = &Lab{L: round(lab.L, 2), A: round(lab.A, 2), B: round(lab.B, 2)}
+		case FormatOKLCH:
+			lch := c.OKLCH()
+			f.OKLCH = &OKLCH{L: round(lch.L, 4), C: round(lch.C, 4), H: round(lch.H, 2)}
+		default:
+			return nil, fmt.Errorf("model: unknown color format %q", format)
+		}
+	}
+
+	return f, nil
+}
+
+// WithColorFormats returns a copy of the team with each color's formats
+// filled in. The team itself is not changed, since it may be shared by
+// other requests.
+func (t *Team) WithColorFormats(formats []string) (*Team, error) {
+	team := *t
+	eras, err := erasWithColorFormats(t.Eras, formats)
+	if err != nil {
+		return nil, err
+	}
+	team.Eras = eras
+
+	if t.Predecessors != nil {
+		team.Predecessors = make([]*Identity, len(t.Predecessors))
+		for i, identity := range t.Predecessors {
+			copied := *identity
+			if copied.Eras, err = erasWithColorFormats(identity.Eras, formats); err != nil {
+				return nil, err
+			}
+			team.Predecessors[i] = &copied
+		}
+	}
+
+	return &team, nil
+}
+
+func erasWithColorFormats(eras []*Era, formats []string) ([]*Era, error) {
+	copies := make([]*Era, len(eras))
+	for i, era := range eras {
+		copied := *era
+		copied.Colors = make([]*Color, len(era.Colors))
+		for j, color := range era.Colors {
+			c := *color
+			f, err := NewColorFormats(c.Hex, formats)
+			if err != nil {
+				return nil, err
+			}
+
+			c.Formats = f
+			copied.Colors[j] = &c
+		}
+		copies[i] = &copied
+	}
+
+	return copies, nil
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+func TestHSL(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	tests := []struct {
+		hex      string
+		expected HSL
+	}{
+		{"#000000", HSL{0, 0, 0}},
+		{"#FFFFFF", HSL{0, 0, 100}},
+		{"#FF0000", HSL{0, 100, 50}},
+		{"#00FF00", HSL{120, 100, 50}},
+		{"#0000FF", HSL{240, 100, 50}},
+		{"#808080", HSL{0, 0, 50.2}},
+		{"#9B2743", HSL{345.5, 59.8, 38.0}},
+		{"#FFB612", HSL{41.5, 100, 53.5}},
+	}
+
+	for _, test := range tests {
+		c, err := ParseHex(test.hex)
+		g.Expect(err).Should(gomega.BeNil())
+		hsl := c.HSL()
+		g.Expect(hsl.H).Should(gomega.BeNumerically("~", test.expected.H, 0.05), test.hex)
+		g.Expect(hsl.S).Should(gomega.BeNumerically("~", test.expected.S, 0.05), test.hex)
+		g.Expect(hsl.L).Should(gomega.BeNumerically("~", test.expected.L, 0.05), test.hex)
+	}
+}
+
+func TestCMYK(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	tests := []struct {
+		hex      string
+		expected CMYK
+	}{
+		{"#000000", CMYK{0, 0, 0, 100}},
+		{"#FFFFFF", CMYK{0, 0, 0, 0}},
+		{"#FF0000", CMYK{0, 100, 100, 0}},
+		{"#00FFFF", CMYK{100, 0, 0, 0}},
+		{"#9B2743", CMYK{0, 74.8, 56.8, 39.2}},
+		{"#041E42", CMYK{93.9, 54.5, 0, 74.1}},
+	}
+
+	for _, test := range tests {
+		c, err := ParseHex(test.hex)
+		g.Expect(err).Should(gomega.BeNil())
+		cmyk := c.CMYK()
+		g.Expect(cmyk.C).Should(gomega.BeNumerically("~", test.expected.C, 0.05), test.hex)
+		g.Expect(cmyk.M).Should(gomega.BeNumerically("~", test.expected.M, 0.05), test.hex)
+		g.Expect(cmyk.Y).Should(gomega.BeNumerically("~", test.expected.Y, 0.05), test.hex)
+		g.Expect(cmyk.K).Should(gomega.BeNumerically("~", test.expected.K, 0.05), test.hex)
+	}
+}
+
+func TestOKLCH(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	// reference values from CSS Color Module Level 4
+	tests := []struct {
+		hex      string
+		expected OKLCH
+	}{
+		{"#000000", OKLCH{0, 0, 0}},
+		{"#FFFFFF", OKLCH{1, 0, 0}},
+		{"#FF0000", OKLCH{0.6280, 0.2577, 29.23}},
+		{"#00FF00", OKLCH{0.8664, 0.2948, 142.50}},
+		{"#0000FF", OKLCH{0.4520, 0.3132, 264.05}},
+	}
+
+	for _, test := range tests {
+		c, err := ParseHex(test.hex)
+		g.Expect(err).Should(gomega.BeNil())
+		lch := c.OKLCH()
+		g.Expect(lch.L).Should(gomega.BeNumerically("~", test.expected.L, 0.0005), test.hex)
+		g.Expect(lch.C).Should(gomega.BeNumerically("~", test.expected.C, 0.0005), test.hex)
+		g.Expect(lch.H).Should(gomega.BeNumerically("~", test.expected.H, 0.05), test.hex)
+	}
+}
+
+func TestParseColorFormats(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	formats, err := ParseColorFormats("hsl, RGB,hsl")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(formats).Should(gomega.Equal([]string{FormatHSL, FormatRGB}))
+
+	formats, err = ParseColorFormats("all")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(formats).Should(gomega.Equal(ColorFormatNames))
+
+	_, err = ParseColorFormats("rgb,pms")
+	g.Expect(err).Should(gomega.MatchError(`model: unknown color format "pms", expected one of rgb, hsl, cmyk, lab, oklch`))
+}
+
+func TestTeamWithColorFormats(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	team := &Team{Name: "Arizona Cardinals", Eras: []*Era{{Year: 2005, Colors: []*Color{{Name: "Cardinal Red", Hex: "#9B2743"}}}}}
+	withFormats, err := team.WithColorFormats(ColorFormatNames)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(team.Eras[0].Colors[0].Formats).Should(gomega.BeNil())
+	g.Expect(withFormats.Eras[0].Colors[0].Formats).Should(gomega.Equal(&ColorFormats{
+		RGB:   &RGB{R: 155, G: 39, B: 67},
+		HSL:   &HSL{H: 345.5, S: 59.8, L: 38},
+		CMYK:  &CMYK{C: 0, M: 74.8, Y: 56.8, K: 39.2},
+		Lab:   &Lab{L: 35.65, A: 49.11, B: 12.07},
+		OKLCH: &OKLCH{L: 0.4646, C: 0.1516, H: 11.52},
+	}))
+}
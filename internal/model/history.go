@@ -0,0 +1,520 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Change actions
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// Edit describes who is making a change and why
+type Edit struct {
+	Actor  string
+	Reason string
+}
+
+// Change is a history entry recording how one team was changed
+type Change struct {
+	// Time is when the change was saved
+	Time time.Time `json:"time"`
+	// Actor is who made the change
+	Actor string `json:"actor"`
+	// Reason is why the change was made
+	Reason string `json:"reason,omitempty"`
+	// Action is create, update or delete
+	Action string `json:"action"`
+	// League is the team's league
+	League string `json:"league"`
+	// Team is the team's name after the change, or before if it was deleted
+	Team string `json:"team"`
+	// Summary describes each difference between Before and After
+	Summary []string `json:"summary"`
+	// Before is the team before the change, or null if it was created
+	Before *TeamSnapshot `json:"before"`
+	// After is the team after the change, or null if it was deleted
+	After *TeamSnapshot `json:"after"`
+}
+
+// teamID returns the ID of the changed team, or 0 if it was changed before
+// teams had IDs
+func (c *Change) teamID() int {
+	if c.After != nil {
+		return c.After.ID
+	}
+	if c.Before != nil {
+		return c.Before.ID
+	}
+
+	return 0
+}
+
+// TeamSnapshot is a copy of a team as it is stored in the data file
+type TeamSnapshot struct {
+	ID           int         `json:"id,omitempty"`
+	Name         string      `json:"name"`
+	Eras         []*Era      `json:"eras"`
+	League       string      `json:"league"`
+	Division     string      `json:"division,omitempty"`
+	Aliases      []string    `json:"aliases,omitempty"`
+	FormerNames  []string    `json:"formerNames,omitempty"`
+	Predecessors []*Identity `json:"predecessors,omitempty"`
+}
+
+func snapshot(team *Team) *TeamSnapshot {
+	s := &TeamSnapshot{
+		ID:       team.ID,
+		Name:     team.Name,
+		League:   team.League,
+		Division: team.Division,
+		Eras:     snapshotEras(team.Eras),
+	}
+
+	if team.Aliases != nil {
+		s.Aliases = append([]string{}, team.Aliases...)
+	}
+
+	if team.FormerNames != nil {
+		s.FormerNames = append([]string{}, team.FormerNames...)
+	}
+
+	for _, identity := range team.Predecessors {
+		if identity != nil {
+			copied := *identity
+			copied.Eras = snapshotEras(identity.Eras)
+			s.Predecessors = append(s.Predecessors, &copied)
+		}
+	}
+
+	return s
+}
+
+func snapshotEras(eras []*Era) []*Era {
+	copies := make([]*Era, 0, len(eras))
+	for _, era := range eras {
+		if era == nil {
+			continue
+		}
+
+		copied := &Era{Year: era.Year, Start: era.Start, Label: era.Label, Colors: make([]*Color, 0, len(era.Colors))}
+		if era.End != nil {
+			end := *era.End
+			copied.End = &end
+		}
+		for _, color := range era.Colors {
+			if color != nil {
+				c := *color
+				copied.Colors = append(copied.Colors, &c)
+			}
+		}
+		copies = append(copies, copied)
+	}
+
+	return copies
+}
+
+// History is an append-only log of changes
+type History interface {
+	// Append records the changes
+	Append(changes ...*Change) error
+	// TeamHistory returns every change to the team with the ID since it was
+	// created, newest first. The team's name is only matched against changes
+	// recorded before teams had IDs, following renames to its earlier names.
+	// A team that is no longer in the data file has an ID of 0, and is found
+	// by the name it was last changed under.
+	TeamHistory(league string, id int, name string) ([]*Change, error)
+}
+
+// HistoryFilename returns the default history file for a data file, e.g.
+// configs/teamhex.history.jsonl for configs/teamhex.json
+func HistoryFilename(dataFilename string) string {
+	return strings.TrimSuffix(dataFilename, filepath.Ext(dataFilename)) + ".history.jsonl"
+}
+
+// FileHistory keeps history in a file with one JSON change per line. Lines
+// are only ever appended.
+type FileHistory struct {
+	filename string
+	mu       sync.RWMutex
+}
+
+// NewFileHistory returns a history kept in the file, which is created on the
+// first change
+func NewFileHistory(filename string) *FileHistory {
+	return &FileHistory{filename: filename}
+}
+
+// Append records the changes
+func (h *FileHistory) Append(changes ...*Change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	f, err := os.OpenFile(h.filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+
+	// write every change at once so a failure can't leave half of an update
+	var b []byte
+	for _, change := range changes {
+		line, err := json.Marshal(change)
+		if err != nil {
+			f.Close()
+			return err
+		}
+		b = append(append(b, line...), '\n')
+	}
+
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// TeamHistory returns every change to a team, newest first
+func (h *FileHistory) TeamHistory(league string, id int, name string) ([]*Change, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	changes := make([]*Change, 0)
+	f, err := os.Open(h.filename)
+	if os.IsNotExist(err) {
+		return changes, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var all []*Change
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+
+		change := &Change{}
+		if err := json.Unmarshal(scanner.Bytes(), change); err != nil {
+			return nil, fmt.Errorf("model: %s: line %d: %w", h.filename, line, err)
+		}
+		all = append(all, change)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// walk back in time to when the team was created. Changes are matched by
+	// the team's ID, or by name, following renames to the team's earlier
+	// names, for changes recorded before teams had IDs.
+	names := map[string]bool{Slugify(name): true}
+	for i := len(all) - 1; i >= 0; i-- {
+		change := all[i]
+		changeID := change.teamID()
+		if id != 0 && changeID != 0 {
+			if changeID != id {
+				continue
+			}
+		} else if !strings.EqualFold(change.League, league) || !names[Slugify(change.Team)] {
+			continue
+		}
+
+		if id == 0 {
+			id = changeID
+		}
+
+		changes = append(changes, change)
+		if change.Before == nil {
+			break
+		}
+		names[Slugify(change.Before.Name)] = true
+	}
+
+	return changes, nil
+}
+
+// teamsSnapshot remembers every team in a data file before it is changed
+type teamsSnapshot struct {
+	order []*Team
+	teams map[*Team]*TeamSnapshot
+}
+
+func newTeamsSnapshot(data *DataFile) *teamsSnapshot {
+	s := &teamsSnapshot{
+		order: append([]*Team{}, data.Teams...),
+		teams: make(map[*Team]*TeamSnapshot, len(data.Teams)),
+	}
+
+	for _, team := range data.Teams {
+		if team != nil {
+			s.teams[team] = snapshot(team)
+		}
+	}
+
+	return s
+}
+
+// changes compares the snapshot to the data file after it was changed. A
+// team replaced by one with the same league and slug is an update.
+func (s *teamsSnapshot) changes(data *DataFile, edit Edit, at time.Time) []*Change {
+	newChange := func(action string, before, after *TeamSnapshot) *Change {
+		current := after
+		if current == nil {
+			current = before
+		}
+
+		return &Change{
+			Time:    at,
+			Actor:   edit.Actor,
+			Reason:  edit.Reason,
+			Action:  action,
+			League:  current.League,
+			Team:    current.Name,
+			Summary: summarize(before, after),
+			Before:  before,
+			After:   after,
+		}
+	}
+
+	remaining := make(map[*Team]bool, len(s.teams))
+	for team := range s.teams {
+		remaining[team] = true
+	}
+	for _, team := range data.Teams {
+		delete(remaining, team)
+	}
+
+	var changes []*Change
+	for _, team := range data.Teams {
+		after := snapshot(team)
+		if before, ok := s.teams[team]; ok {
+			if !reflect.DeepEqual(before, after) {
+				changes = append(changes, newChange(ActionUpdate, before, after))
+			}
+			continue
+		}
+
+		var replaced *Team
+		for _, old := range s.order {
+			if remaining[old] && strings.EqualFold(old.League, team.League) && Slugify(s.teams[old].Name) == Slugify(team.Name) {
+				replaced = old
+				break
+			}
+		}
+
+		if replaced == nil {
+			changes = append(changes, newChange(ActionCreate, nil, after))
+			continue
+		}
+
+		delete(remaining, replaced)
+		if before := s.teams[replaced]; !reflect.DeepEqual(before, after) {
+			changes = append(changes, newChange(ActionUpdate, before, after))
+		}
+	}
+
+	for _, old := range s.order {
+		if remaining[old] {
+			changes = append(changes, newChange(ActionDelete, s.teams[old], nil))
+		}
+	}
+
+	return changes
+}
+
+// summarize describes the differences between two versions of a team
+func summarize(before, after *TeamSnapshot) []string {
+	switch {
+	case before == nil:
+		return []string{"created"}
+	case after == nil:
+		return []string{"deleted"}
+	}
+
+	var summary []string
+	if before.Name != after.Name {
+		summary = append(summary, fmt.Sprintf("renamed from %q to %q", before.Name, after.Name))
+	}
+
+	if before.ID != after.ID {
+		summary = append(summary, fmt.Sprintf("id changed from %d to %d", before.ID, after.ID))
+	}
+
+	if before.Division != after.Division {
+		summary = append(summary, fmt.Sprintf("division changed from %q to %q", before.Division, after.Division))
+	}
+
+	if !reflect.DeepEqual(before.Aliases, after.Aliases) {
+		summary = append(summary, fmt.Sprintf("aliases changed from %q to %q", before.Aliases, after.Aliases))
+	}
+
+	// a rename adds the old name to the former names, which needs no summary of its own
+	if before.Name == after.Name && !reflect.DeepEqual(before.FormerNames, after.FormerNames) {
+		summary = append(summary, fmt.Sprintf("former names changed from %q to %q", before.FormerNames, after.FormerNames))
+	}
+
+	if !reflect.DeepEqual(before.Predecessors, after.Predecessors) {
+		summary = append(summary, "predecessors changed")
+	}
+
+	beforeEras := make(map[string]*Era)
+	for _, era := range before.Eras {
+		beforeEras[eraKey(era)] = era
+	}
+
+	afterEras := make(map[string]*Era)
+	for _, era := range after.Eras {
+		afterEras[eraKey(era)] = era
+	}
+
+	// eras are summarized newest first
+	eras := make([]*Era, 0, len(beforeEras)+len(afterEras))
+	eras = append(eras, before.Eras...)
+	for _, era := range after.Eras {
+		if _, ok := beforeEras[eraKey(era)]; !ok {
+			eras = append(eras, era)
+		}
+	}
+	sort.SliceStable(eras, func(i, j int) bool {
+		return eras[i].StartDate().After(eras[j].StartDate().Time)
+	})
+
+	for _, era := range eras {
+		name := eraName(era)
+		b, a := beforeEras[eraKey(era)], afterEras[eraKey(era)]
+		switch {
+		case b == nil:
+			summary = append(summary, fmt.Sprintf("added %s", name))
+		case a == nil:
+			summary = append(summary, fmt.Sprintf("removed %s", name))
+		default:
+			if !reflect.DeepEqual(b.End, a.End) {
+				summary = append(summary, fmt.Sprintf("%s: end changed from %s to %s", name, describeEnd(b.End), describeEnd(a.End)))
+			}
+			summary = append(summary, summarizeColors(name, b.Colors, a.Colors)...)
+		}
+	}
+
+	return summary
+}
+
+// eraName describes an era by when it started and its label, e.g. "2011 era"
+// or "2020-10-15 city edition era". The start date is only given if it is
+// not January 1.
+func eraName(era *Era) string {
+	start := strconv.Itoa(era.Year)
+	if !era.StartDate().Equal(NewDate(era.Year, time.January, 1).Time) {
+		start = era.StartDate().String()
+	}
+
+	if era.HasLabel(PrimaryLabel) {
+		return start + " era"
+	}
+
+	return start + " " + era.Label + " era"
+}
+
+func describeEnd(end *Date) string {
+	if end == nil {
+		return "none"
+	}
+
+	return end.String()
+}
+
+// changedReferences returns the names of the published values and role that
+// differ between the colors
+func changedReferences(before, after *Color) []string {
+	var changed []string
+	if before.Pantone != after.Pantone {
+		changed = append(changed, "pantone")
+	}
+	if !reflect.DeepEqual(before.CMYK, after.CMYK) {
+		changed = append(changed, "cmyk")
+	}
+	if !reflect.DeepEqual(before.RGB, after.RGB) {
+		changed = append(changed, "rgb")
+	}
+	if before.Source != after.Source {
+		changed = append(changed, "source")
+	}
+	if before.Role != after.Role {
+		changed = append(changed, "role")
+	}
+
+	return changed
+}
+
+func summarizeColors(name string, before, after []*Color) []string {
+	var summary []string
+	beforeColors := make(map[string]*Color)
+	for _, color := range before {
+		beforeColors[strings.ToLower(color.Name)] = color
+	}
+
+	afterColors := make(map[string]*Color)
+	for _, color := range after {
+		afterColors[strings.ToLower(color.Name)] = color
+		b, ok := beforeColors[strings.ToLower(color.Name)]
+		if !ok {
+			summary = append(summary, fmt.Sprintf("%s: added %s %s", name, color.Name, color.Hex))
+			continue
+		}
+
+		switch refs := changedReferences(b, color); {
+		case b.Hex != color.Hex:
+			summary = append(summary, fmt.Sprintf("%s: %s changed from %s to %s", name, color.Name, b.Hex, color.Hex))
+		case b.Name != color.Name:
+			summary = append(summary, fmt.Sprintf("%s: %s renamed to %s", name, b.Name, color.Name))
+		case len(refs) > 0:
+			summary = append(summary, fmt.Sprintf("%s: %s %s changed", name, color.Name, strings.Join(refs, ", ")))
+		}
+	}
+
+	for _, color := range before {
+		if _, ok := afterColors[strings.ToLower(color.Name)]; !ok {
+			summary = append(summary, fmt.Sprintf("%s: removed %s %s", name, color.Name, color.Hex))
+		}
+	}
+
+	if len(summary) == 0 && !reflect.DeepEqual(before, after) {
+		summary = append(summary, fmt.Sprintf("%s: colors reordered", name))
+	}
+
+	return summary
+}
@@ -0,0 +1,246 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+)
+
+func TestHistoryFilename(t *testing.T) {
+	g := gomega.NewWithT(t)
+	g.Expect(HistoryFilename("configs/teamhex.json")).Should(gomega.Equal("configs/teamhex.history.jsonl"))
+	g.Expect(HistoryFilename("teamhex.db")).Should(gomega.Equal("teamhex.history.jsonl"))
+}
+
+func TestTeamsSnapshotChanges(t *testing.T) {
+	g := gomega.NewWithT(t)
+	data, _ := Load(testFile)
+	before := newTeamsSnapshot(data)
+	edit := Edit{Actor: "editor", Reason: "rebrand"}
+
+	g.Expect(before.changes(data, edit, time.Time{})).Should(gomega.BeEmpty())
+
+	must := func(err error) {
+		g.Expect(err).Should(gomega.BeNil())
+	}
+	must(data.SetColor("nfl", "buffalo bills", EraKey{Year: 2011}, &Color{Name: "Royal Blue", Hex: "#00338D"}, false))
+	must(data.SetColor("nfl", "buffalo bills", EraKey{Year: 2011}, &Color{Name: "Scarlet Red", Hex: "#C8102E", Pantone: "PMS 186 C", Source: "style guide", Role: RoleSecondary}, false))
+	must(data.SetColor("nfl", "buffalo bills", EraKey{Year: 2011}, &Color{Name: "White", Hex: "#FFFFFF"}, true))
+	must(data.RemoveEra("nfl", "buffalo bills", EraKey{Year: 2002}))
+	must(data.RenameTeam("nfl", "buffalo bills", "Buffalo Bisons"))
+	must(data.RemoveTeam("nhl", "buffalo sabres"))
+	_, err := data.PutTeam(&Team{Name: "the ohio state university", League: "NCAA", Division: "Big Ten Conference", Eras: []*Era{
+		{Year: 2004, Colors: []*Color{{Name: "Scarlet", Hex: "#BA0C2F"}}},
+	}})
+	must(err)
+	must(data.AddTeam(&Team{Name: "Buffalo Bandits", League: "NLL"}))
+
+	changes := before.changes(data, edit, time.Time{})
+	g.Expect(changes).Should(gomega.HaveLen(4))
+
+	g.Expect(changes[0].Action).Should(gomega.Equal(ActionUpdate))
+	g.Expect(changes[0].Team).Should(gomega.Equal("the ohio state university"))
+	g.Expect(changes[0].Summary).Should(gomega.Equal([]string{`renamed from "The Ohio State University" to "the ohio state university"`}))
+
+	g.Expect(changes[1].Action).Should(gomega.Equal(ActionUpdate))
+	g.Expect(changes[1].Actor).Should(gomega.Equal("editor"))
+	g.Expect(changes[1].Reason).Should(gomega.Equal("rebrand"))
+	g.Expect(changes[1].League).Should(gomega.Equal("NFL"))
+	g.Expect(changes[1].Team).Should(gomega.Equal("Buffalo Bisons"))
+	g.Expect(changes[1].Before.Name).Should(gomega.Equal("Buffalo Bills"))
+	g.Expect(changes[1].Before.Eras).Should(gomega.HaveLen(2))
+	g.Expect(changes[1].After.Eras).Should(gomega.HaveLen(1))
+	g.Expect(changes[1].After.FormerNames).Should(gomega.Equal([]string{"Buffalo Bills"}))
+	g.Expect(changes[1].Summary).Should(gomega.Equal([]string{
+		`renamed from "Buffalo Bills" to "Buffalo Bisons"`,
+		"2011 era: Royal Blue changed from #003087 to #00338D",
+		"2011 era: Scarlet Red pantone, source, role changed",
+		"2011 era: added White #FFFFFF",
+		"removed 2002 era",
+	}))
+
+	g.Expect(changes[2].Action).Should(gomega.Equal(ActionCreate))
+	g.Expect(changes[2].Team).Should(gomega.Equal("Buffalo Bandits"))
+	g.Expect(changes[2].Before).Should(gomega.BeNil())
+
+	g.Expect(changes[3].Action).Should(gomega.Equal(ActionDelete))
+	g.Expect(changes[3].Team).Should(gomega.Equal("Buffalo Sabres"))
+	g.Expect(changes[3].After).Should(gomega.BeNil())
+	g.Expect(changes[3].Summary).Should(gomega.Equal([]string{"deleted"}))
+}
+
+func TestSummarizeEras(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	team := &Team{Name: "Buffalo Bills", League: "NFL", Eras: []*Era{
+		{Year: 2011, Start: NewDate(2011, time.September, 11), Colors: []*Color{{Name: "Royal Blue", Hex: "#00338D"}}},
+		{Year: 2002, Colors: []*Color{{Name: "Midnight Navy", Hex: "#091F2C"}}},
+	}}
+	before := snapshot(team)
+
+	end := NewDate(2011, time.September, 10)
+	team.Eras[1].End = &end
+	team.Eras = append([]*Era{{Year: 2020, Label: "city edition", Colors: []*Color{{Name: "Red", Hex: "#C60C30"}}}}, team.Eras...)
+	team.Eras[1].Colors[0].Hex = "#00338E"
+
+	g.Expect(before.Eras[1].End).Should(gomega.BeNil())
+	g.Expect(summarize(before, snapshot(team))).Should(gomega.Equal([]string{
+		"added 2020 city edition era",
+		"2011-09-11 era: Royal Blue changed from #00338D to #00338E",
+		"2002 era: end changed from none to 2011-09-10",
+	}))
+}
+
+func TestSummarizePredecessors(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	team := &Team{Name: "Las Vegas Raiders", League: "NFL", Predecessors: []*Identity{{Name: "Oakland Raiders", From: 1995, To: 2019}}}
+	before := snapshot(team)
+	team.Predecessors[0].City = "Oakland"
+	after := snapshot(team)
+
+	g.Expect(before.Predecessors[0].City).Should(gomega.BeEmpty())
+	g.Expect(summarize(before, after)).Should(gomega.Equal([]string{"predecessors changed"}))
+	g.Expect(summarize(after, snapshot(team))).Should(gomega.BeEmpty())
+}
+
+func TestFileHistory(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	dir, err := ioutil.TempDir("", "teamhex")
+	g.Expect(err).Should(gomega.BeNil())
+	defer os.RemoveAll(dir)
+
+	data, _ := Load(testFile)
+	filename := filepath.Join(dir, "teamhex.json")
+	g.Expect(data.Save(filename)).Should(gomega.Succeed())
+
+	h := NewFileHistory(HistoryFilename(filename))
+	changes, err := h.TeamHistory("nfl", 2, "Buffalo Bills")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(changes).Should(gomega.BeEmpty())
+
+	u, err := NewUpdater(BackendJSON, filename, h)
+	g.Expect(err).Should(gomega.BeNil())
+
+	_, err = u.Update(Edit{Actor: "a", Reason: "new navy"}, func(data *DataFile) error {
+		return data.SetColor("nfl", "buffalo bills", EraKey{Year: 2011}, &Color{Name: "Royal Blue", Hex: "#00338D"}, false)
+	})
+	g.Expect(err).Should(gomega.BeNil())
+
+	// failed updates are not recorded
+	_, err = u.Update(Edit{Actor: "a"}, func(data *DataFile) error {
+		return data.RemoveEra("nfl", "buffalo bills", EraKey{Year: 1900})
+	})
+	g.Expect(err).Should(gomega.MatchError(ErrEraNotFound))
+
+	s, err := u.Update(Edit{Actor: "b", Reason: "relocation"}, func(data *DataFile) error {
+		return data.RenameTeam("nfl", "buffalo bills", "Toronto Bills")
+	})
+	g.Expect(err).Should(gomega.BeNil())
+
+	changes, err = h.TeamHistory("NFL", 2, "Toronto Bills")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(changes).Should(gomega.HaveLen(2))
+	g.Expect(changes[0].Actor).Should(gomega.Equal("b"))
+	g.Expect(changes[0].Time).Should(gomega.Equal(s.GenerationDate()))
+	g.Expect(changes[1].Reason).Should(gomega.Equal("new navy"))
+	g.Expect(changes[1].Before.Eras[0].Colors[0].Hex).Should(gomega.Equal("#003087"))
+	g.Expect(changes[1].After.Eras[0].Colors[0].Hex).Should(gomega.Equal("#00338D"))
+
+	// the name doesn't match changes to a team with another ID
+	changes, err = h.TeamHistory("nfl", 3, "Toronto Bills")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(changes).Should(gomega.BeEmpty())
+
+	// a new team with the old name doesn't inherit the renamed team's history
+	_, err = u.Update(Edit{Actor: "c", Reason: "expansion"}, func(data *DataFile) error {
+		return data.AddTeam(&Team{Name: "Buffalo Bills", League: "NFL", Eras: []*Era{{Year: 2030, Colors: []*Color{{Name: "Red", Hex: "#C60C30"}}}}})
+	})
+	g.Expect(err).Should(gomega.BeNil())
+
+	s, err = u.Update(Edit{Actor: "c", Reason: "new red"}, func(data *DataFile) error {
+		return data.SetColor("nfl", "buffalo bills", EraKey{Year: 2030}, &Color{Name: "Red", Hex: "#D50A0A"}, false)
+	})
+	g.Expect(err).Should(gomega.BeNil())
+
+	expansion, err := s.TeamByLeagueAndName("nfl", "buffalo bills")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(expansion.ID).ShouldNot(gomega.Equal(2))
+
+	changes, err = h.TeamHistory("nfl", expansion.ID, "Buffalo Bills")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(changes).Should(gomega.HaveLen(2))
+	g.Expect(changes[0].Reason).Should(gomega.Equal("new red"))
+	g.Expect(changes[1].Action).Should(gomega.Equal(ActionCreate))
+
+	changes, err = h.TeamHistory("nfl", 2, "Toronto Bills")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(changes).Should(gomega.HaveLen(2))
+
+	// a deleted team is found by the name it was last changed under
+	_, err = u.Update(Edit{Actor: "d", Reason: "folded"}, func(data *DataFile) error {
+		return data.RemoveTeam("nfl", "toronto bills")
+	})
+	g.Expect(err).Should(gomega.BeNil())
+
+	changes, err = h.TeamHistory("nfl", 0, "toronto-bills")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(changes).Should(gomega.HaveLen(3))
+	g.Expect(changes[0].Action).Should(gomega.Equal(ActionDelete))
+	g.Expect(changes[2].Reason).Should(gomega.Equal("new navy"))
+
+	changes, err = h.TeamHistory("nhl", 0, "toronto bills")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(changes).Should(gomega.BeEmpty())
+}
+
+func TestFileHistoryWithoutIDs(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	dir, err := ioutil.TempDir("", "teamhex")
+	g.Expect(err).Should(gomega.BeNil())
+	defer os.RemoveAll(dir)
+
+	// changes recorded before teams had IDs are followed by name
+	bills := &TeamSnapshot{Name: "Buffalo Bills", League: "NFL"}
+	toronto := &TeamSnapshot{Name: "Toronto Bills", League: "NFL"}
+	withID := &TeamSnapshot{ID: 7, Name: "Toronto Bills", League: "NFL"}
+	other := &TeamSnapshot{ID: 8, Name: "Toronto Argonauts", League: "NFL"}
+	h := NewFileHistory(filepath.Join(dir, "teamhex.history.jsonl"))
+	g.Expect(h.Append(
+		&Change{Action: ActionUpdate, Reason: "old", League: "NFL", Team: "Buffalo Bills", Before: bills, After: bills},
+		&Change{Action: ActionDelete, League: "NFL", Team: "Buffalo Bills", Before: bills},
+		&Change{Action: ActionCreate, Reason: "new", League: "NFL", Team: "Buffalo Bills", After: bills},
+		&Change{Action: ActionUpdate, Reason: "rename", League: "NFL", Team: "Toronto Bills", Before: bills, After: toronto},
+		&Change{Action: ActionUpdate, Reason: "other", League: "NFL", Team: "Toronto Argonauts", Before: other, After: other},
+		&Change{Action: ActionUpdate, Reason: "id", League: "NFL", Team: "Toronto Bills", Before: withID, After: withID},
+	)).Should(gomega.Succeed())
+
+	changes, err := h.TeamHistory("nfl", 7, "Toronto Bills")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(changes).Should(gomega.HaveLen(3))
+	g.Expect(changes[0].Reason).Should(gomega.Equal("id"))
+	g.Expect(changes[1].Reason).Should(gomega.Equal("rename"))
+	g.Expect(changes[2].Reason).Should(gomega.Equal("new"))
+}
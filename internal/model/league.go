@@ -40,7 +40,34 @@ func (s sortByLeagueRecord) Swap(i, j int) {
 	s[i], s[j] = s[j], s[i]
 }
 
+//DivisionRecord represents an individual division or conference in a league
+type DivisionRecord struct {
+	// Division is the name of the division
+	Division string `json:"division"`
+	// League is the name of the league the division is in
+	League string `json:"league"`
+	// Link is a link to retrieve teams for that division
+	Link string `json:"_link"`
+}
+
+type sortByDivisionRecord []*DivisionRecord
+
+func (s sortByDivisionRecord) Len() int {
+	return len(s)
+}
+
+func (s sortByDivisionRecord) Less(i, j int) bool {
+	return strings.Compare(s[i].Division, s[j].Division) < 0
+}
+
+func (s sortByDivisionRecord) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
 type leagueData struct {
 	sortedTeams Teams
-	teamByName  map[string]*Team
+	// teamBySlug has the slugs of every team's name and former names
+	teamBySlug      map[string]*Team
+	divisions       []*DivisionRecord
+	teamsByDivision map[string]Teams
 }
@@ -0,0 +1,140 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+// Identity is a name a franchise played under before its current one, such
+// as before it relocated or was renamed
+type Identity struct {
+	Name string `json:"name"`
+	// City is where the franchise played under the identity
+	City string `json:"city,omitempty"`
+	// From is the first season played under the identity
+	From int `json:"from,omitempty"`
+	// To is the last season played under the identity
+	To int `json:"to,omitempty"`
+	// Eras are the colors used under the identity, newest first
+	Eras []*Era `json:"eras"`
+}
+
+// historicalNames returns the team's former names followed by the names of
+// its predecessors
+func (t *Team) historicalNames() []string {
+	names := append([]string{}, t.FormerNames...)
+	for _, identity := range t.Predecessors {
+		if identity != nil {
+			names = append(names, identity.Name)
+		}
+	}
+
+	return names
+}
+
+// EraAt returns the era in effect for the year. See Team.EraAt.
+func (i *Identity) EraAt(year int) (*Era, error) {
+	return eraAt(i.Eras, year)
+}
+
+// Lineage is every identity of a franchise, from its first to the team today
+type Lineage struct {
+	// Team is the name of the team today
+	Team string `json:"team"`
+	// League is the league the team plays in
+	League string `json:"league"`
+	// Identities are oldest first. The last is the team today unless the
+	// lineage was limited to a year.
+	Identities []*LineageIdentity `json:"identities"`
+	// Link is a link to retrieve the team
+	Link string `json:"_link"`
+}
+
+// LineageIdentity is one identity in a franchise's lineage
+type LineageIdentity struct {
+	*Identity
+	// Current is whether this is the team today
+	Current bool `json:"current"`
+	// Predecessor is the name of the identity before this one
+	Predecessor string `json:"predecessor,omitempty"`
+	// Successor is the name of the identity after this one
+	Successor string `json:"successor,omitempty"`
+}
+
+// Lineage returns the identities of a team's franchise. The team may also be
+// found by the name of one of its predecessors.
+func (m *Model) Lineage(leagueName, name string) (*Lineage, error) {
+	team, err := m.TeamByLeagueAndName(leagueName, name)
+	if err != nil {
+		return nil, err
+	}
+
+	current := &Identity{Name: team.Name, Eras: team.Eras}
+	if n := len(team.Predecessors); n > 0 {
+		current.From = team.Predecessors[n-1].To + 1
+	}
+
+	identities := append(append([]*Identity{}, team.Predecessors...), current)
+	lineage := &Lineage{
+		Team:       team.Name,
+		League:     team.League,
+		Identities: make([]*LineageIdentity, len(identities)),
+		Link:       team.Link,
+	}
+
+	for i, identity := range identities {
+		entry := &LineageIdentity{Identity: identity, Current: identity == current}
+		if i > 0 {
+			entry.Predecessor = identities[i-1].Name
+		}
+		if i < len(identities)-1 {
+			entry.Successor = identities[i+1].Name
+		}
+		lineage.Identities[i] = entry
+	}
+
+	return lineage, nil
+}
+
+// AtYear returns a copy of the lineage with only the identity the franchise
+// played under in the year and the era it used. ErrEraNotFound is returned if
+// the year is before the franchise's first known era.
+func (l *Lineage) AtYear(year int) (*Lineage, error) {
+	for i := len(l.Identities) - 1; i >= 0; i-- {
+		entry := l.Identities[i]
+		if entry.From > year {
+			continue
+		}
+
+		if !entry.Current && entry.To < year {
+			break
+		}
+
+		era, err := entry.EraAt(year)
+		if err != nil {
+			return nil, err
+		}
+
+		identity := *entry.Identity
+		identity.Eras = []*Era{era}
+		copied := *entry
+		copied.Identity = &identity
+
+		limited := *l
+		limited.Identities = []*LineageIdentity{&copied}
+		return &limited, nil
+	}
+
+	return nil, ErrEraNotFound
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+)
+
+func newLineageStore(t *testing.T) *Model {
+	m, err := NewMemoryStore(time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC),
+		&Team{Name: "Las Vegas Raiders", League: "NFL", Eras: []*Era{
+			{Year: 2020, Colors: []*Color{{Name: "Silver", Hex: "#A2AAAD"}, {Name: "Black", Hex: "#010101"}}},
+		}, Predecessors: []*Identity{
+			{Name: "Los Angeles Raiders", City: "Los Angeles", From: 1982, To: 1994, Eras: []*Era{
+				{Year: 1982, Colors: []*Color{{Name: "Silver", Hex: "#A5ACAF"}, {Name: "Black", Hex: "#000000"}}},
+			}},
+			{Name: "Oakland Raiders", City: "Oakland", From: 1995, To: 2019, Eras: []*Era{
+				{Year: 1995, Colors: []*Color{{Name: "Silver", Hex: "#A2AAAD"}, {Name: "Black", Hex: "#010101"}}},
+			}},
+		}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return m
+}
+
+func TestLineage(t *testing.T) {
+	g := gomega.NewWithT(t)
+	m := newLineageStore(t)
+
+	lineage, err := m.Lineage("nfl", "las-vegas-raiders")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(lineage.Team).Should(gomega.Equal("Las Vegas Raiders"))
+	g.Expect(lineage.Link).Should(gomega.Equal("/leagues/nfl/las-vegas-raiders"))
+	g.Expect(lineage.Identities).Should(gomega.HaveLen(3))
+
+	first, second, current := lineage.Identities[0], lineage.Identities[1], lineage.Identities[2]
+	g.Expect(first.Name).Should(gomega.Equal("Los Angeles Raiders"))
+	g.Expect(first.Predecessor).Should(gomega.BeEmpty())
+	g.Expect(first.Successor).Should(gomega.Equal("Oakland Raiders"))
+	g.Expect(first.Current).Should(gomega.BeFalse())
+
+	g.Expect(second.Predecessor).Should(gomega.Equal("Los Angeles Raiders"))
+	g.Expect(second.Successor).Should(gomega.Equal("Las Vegas Raiders"))
+
+	g.Expect(current.Name).Should(gomega.Equal("Las Vegas Raiders"))
+	g.Expect(current.From).Should(gomega.Equal(2020))
+	g.Expect(current.Current).Should(gomega.BeTrue())
+	g.Expect(current.Predecessor).Should(gomega.Equal("Oakland Raiders"))
+	g.Expect(current.Successor).Should(gomega.BeEmpty())
+
+	// a predecessor's name finds the franchise
+	lineage, err = m.Lineage("nfl", "Oakland Raiders")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(lineage.Team).Should(gomega.Equal("Las Vegas Raiders"))
+
+	_, err = m.Lineage("nfl", "Tampa Bay Buccaneers")
+	g.Expect(err).Should(gomega.MatchError(ErrTeamNotFound))
+}
+
+func TestLineageWithoutPredecessors(t *testing.T) {
+	g := gomega.NewWithT(t)
+	m, _ := New(testFile)
+
+	lineage, err := m.Lineage("nhl", "buffalo sabres")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(lineage.Identities).Should(gomega.HaveLen(1))
+	g.Expect(lineage.Identities[0].Current).Should(gomega.BeTrue())
+	g.Expect(lineage.Identities[0].From).Should(gomega.Equal(0))
+}
+
+func TestLineageAtYear(t *testing.T) {
+	g := gomega.NewWithT(t)
+	lineage, _ := newLineageStore(t).Lineage("nfl", "las-vegas-raiders")
+
+	tests := []struct {
+		year int
+		name string
+		hex  string
+	}{
+		{1982, "Los Angeles Raiders", "#A5ACAF"},
+		{1994, "Los Angeles Raiders", "#A5ACAF"},
+		{2000, "Oakland Raiders", "#A2AAAD"},
+		{2030, "Las Vegas Raiders", "#A2AAAD"},
+	}
+
+	for _, test := range tests {
+		limited, err := lineage.AtYear(test.year)
+		g.Expect(err).Should(gomega.BeNil(), "year %d", test.year)
+		g.Expect(limited.Identities).Should(gomega.HaveLen(1))
+		g.Expect(limited.Identities[0].Name).Should(gomega.Equal(test.name))
+		g.Expect(limited.Identities[0].Eras).Should(gomega.HaveLen(1))
+		g.Expect(limited.Identities[0].Eras[0].Colors[0].Hex).Should(gomega.Equal(test.hex))
+	}
+
+	_, err := lineage.AtYear(1960)
+	g.Expect(err).Should(gomega.MatchError(ErrEraNotFound))
+
+	// the lineage itself is unchanged
+	g.Expect(lineage.Identities).Should(gomega.HaveLen(3))
+	g.Expect(lineage.Identities[2].Eras).Should(gomega.HaveLen(1))
+}
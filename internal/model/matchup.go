@@ -0,0 +1,221 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"math"
+	"sort"
+)
+
+// MatchupMinDistance is the CIEDE2000 difference under which two teams'
+// colors are considered to clash. Dark colors such as navy and black are
+// around 16 apart, which is hard to tell apart on a screen.
+const MatchupMinDistance = 20
+
+// Matchup is the color chosen for each team when they play each other
+type Matchup struct {
+	Home *MatchupSide `json:"home"`
+	Away *MatchupSide `json:"away"`
+	// Distance is the CIEDE2000 difference between the chosen colors
+	Distance float64 `json:"distance"`
+	// Clash is whether the chosen colors are closer than MatchupMinDistance,
+	// which only happens when every pair of the teams' colors is. The pair
+	// furthest apart is chosen then.
+	Clash bool `json:"clash"`
+}
+
+// MatchupSide is the color chosen for one team in a matchup
+type MatchupSide struct {
+	// Team is the name of the team
+	Team string `json:"team"`
+	// League is the league the team plays in
+	League string `json:"league"`
+	// Color is the team color chosen to represent the team
+	Color *Color `json:"color"`
+	// Text is the most readable text color on Color. It is a team color if
+	// one meets WCAG level AA, otherwise black or white.
+	Text *Color `json:"text"`
+	// Ratio is the contrast ratio of Text on Color
+	Ratio float64 `json:"ratio"`
+	// Link is a link to retrieve the team
+	Link string `json:"_link"`
+}
+
+// matchupColor is a color in a team's current era with its Lab value
+type matchupColor struct {
+	color *Color
+	lab   Lab
+}
+
+// matchupRank orders a team's colors by how well they represent the team:
+// its primary color, its secondary color, then the rest in the order they
+// were authored, with text and background colors last
+func matchupRank(color *Color) int {
+	switch color.Role {
+	case RolePrimary:
+		return 0
+	case RoleSecondary:
+		return 1
+	case RoleText, RoleBackground:
+		return 3
+	default:
+		return 2
+	}
+}
+
+// matchupCandidates returns the colors of the team's current era, most
+// representative first
+func matchupCandidates(team *Team) ([]*matchupColor, error) {
+	era := team.CurrentEra()
+	if era == nil {
+		return nil, ErrEraNotFound
+	}
+
+	if len(era.Colors) == 0 {
+		return nil, ErrColorNotFound
+	}
+
+	candidates := make([]*matchupColor, len(era.Colors))
+	for i, color := range era.Colors {
+		rgb, err := ParseHex(color.Hex)
+		if err != nil {
+			return nil, err
+		}
+
+		candidates[i] = &matchupColor{color: color, lab: rgb.Lab()}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return matchupRank(candidates[i].color) < matchupRank(candidates[j].color)
+	})
+
+	return candidates, nil
+}
+
+// matchupPreferred reports whether the pair of home color i and away color j
+// is preferred over the pair k and l, where a lower index is a more
+// representative color. Pairs are tried in the order (0, 0), (0, 1), (1, 0),
+// (1, 1), (0, 2), (1, 2), (2, 0) and so on, so the away team moves to a less
+// representative color first.
+func matchupPreferred(i, j, k, l int) bool {
+	if a, b := maxInt(i, j), maxInt(k, l); a != b {
+		return a < b
+	}
+
+	if i != k {
+		return i < k
+	}
+
+	return j < l
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
+
+// NewMatchup picks a color for each team from their current eras that can be
+// told apart. Each team's most representative color is used unless the two
+// clash, in which case the away team's next color is tried, then the home
+// team's, then both, and so on; see matchupRank. If every pair clashes, the
+// pair that is furthest apart is used.
+func NewMatchup(home, away *Team) (*Matchup, error) {
+	homeColors, err := matchupCandidates(home)
+	if err != nil {
+		return nil, err
+	}
+
+	awayColors, err := matchupCandidates(away)
+	if err != nil {
+		return nil, err
+	}
+
+	// the preferred pair that doesn't clash, and the furthest apart pair in
+	// case they all do
+	hi, ai, found := 0, 0, false
+	hf, af, furthest := 0, 0, -1.0
+	for i, h := range homeColors {
+		for j, a := range awayColors {
+			d := DeltaE2000(h.lab, a.lab)
+			if d > furthest {
+				hf, af, furthest = i, j, d
+			}
+
+			if d >= MatchupMinDistance && (!found || matchupPreferred(i, j, hi, ai)) {
+				hi, ai, found = i, j, true
+			}
+		}
+	}
+
+	if !found {
+		hi, ai = hf, af
+	}
+
+	h, a := homeColors[hi], awayColors[ai]
+	distance := DeltaE2000(h.lab, a.lab)
+	homeSide, err := newMatchupSide(home, h.color)
+	if err != nil {
+		return nil, err
+	}
+
+	awaySide, err := newMatchupSide(away, a.color)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Matchup{
+		Home:     homeSide,
+		Away:     awaySide,
+		Distance: math.Round(distance*100) / 100,
+		Clash:    distance < MatchupMinDistance,
+	}, nil
+}
+
+func newMatchupSide(team *Team, color *Color) (*MatchupSide, error) {
+	rec, err := recommendText(color, team.CurrentEra().Colors)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MatchupSide{
+		Team:   team.Name,
+		League: team.League,
+		Color:  color,
+		Text:   rec.Text,
+		Ratio:  rec.Ratio,
+		Link:   team.Link,
+	}, nil
+}
+
+// Matchup picks a color for each of two teams that can be told apart. See
+// NewMatchup.
+func (m *Model) Matchup(homeLeague, homeName, awayLeague, awayName string) (*Matchup, error) {
+	home, err := m.TeamByLeagueAndName(homeLeague, homeName)
+	if err != nil {
+		return nil, err
+	}
+
+	away, err := m.TeamByLeagueAndName(awayLeague, awayName)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMatchup(home, away)
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+func newMatchupTeam(name string, colors ...*Color) *Team {
+	return &Team{Name: name, League: "NFL", Link: "/leagues/nfl/" + Slugify(name), Eras: []*Era{{Year: 2020, Colors: colors}}}
+}
+
+func TestNewMatchup(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	cardinals := newMatchupTeam("Arizona Cardinals",
+		&Color{Name: "Cardinal Red", Hex: "#9B2743", Role: RolePrimary},
+		&Color{Name: "Black", Hex: "#010101", Role: RoleSecondary},
+		&Color{Name: "White", Hex: "#FFFFFF", Role: RoleText},
+	)
+	bills := newMatchupTeam("Buffalo Bills",
+		&Color{Name: "Royal Blue", Hex: "#00338D", Role: RolePrimary},
+		&Color{Name: "Red", Hex: "#C60C30", Role: RoleSecondary},
+	)
+
+	matchup, err := NewMatchup(cardinals, bills)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(matchup).Should(gomega.Equal(&Matchup{
+		Home: &MatchupSide{
+			Team:   "Arizona Cardinals",
+			League: "NFL",
+			Color:  cardinals.Eras[0].Colors[0],
+			Text:   cardinals.Eras[0].Colors[2],
+			Ratio:  7.59,
+			Link:   "/leagues/nfl/arizona-cardinals",
+		},
+		Away: &MatchupSide{
+			Team:   "Buffalo Bills",
+			League: "NFL",
+			Color:  bills.Eras[0].Colors[0],
+			Text:   whiteText,
+			Ratio:  11.3,
+			Link:   "/leagues/nfl/buffalo-bills",
+		},
+		Distance: 34.39,
+		Clash:    false,
+	}))
+}
+
+func TestNewMatchupFallsBackToSecondary(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	cardinals := newMatchupTeam("Arizona Cardinals",
+		&Color{Name: "Cardinal Red", Hex: "#9B2743", Role: RolePrimary},
+		&Color{Name: "Black", Hex: "#010101", Role: RoleSecondary},
+	)
+	falcons := newMatchupTeam("Atlanta Falcons",
+		&Color{Name: "White", Hex: "#FFFFFF", Role: RoleText},
+		&Color{Name: "Black", Hex: "#010101", Role: RoleSecondary},
+		&Color{Name: "Red", Hex: "#A6192E", Role: RolePrimary},
+	)
+
+	// the away team changes first
+	matchup, err := NewMatchup(cardinals, falcons)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(matchup.Home.Color.Name).Should(gomega.Equal("Cardinal Red"))
+	g.Expect(matchup.Away.Color.Name).Should(gomega.Equal("Black"))
+	g.Expect(matchup.Distance).Should(gomega.BeNumerically(">=", MatchupMinDistance))
+	g.Expect(matchup.Clash).Should(gomega.BeFalse())
+
+	// then the home team's, if the away team has no other colors
+	scarlet := newMatchupTeam("The Ohio State University", &Color{Name: "Scarlet", Hex: "#BA0C2F", Role: RolePrimary})
+	matchup, err = NewMatchup(falcons, scarlet)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(matchup.Home.Color.Name).Should(gomega.Equal("Black"))
+	g.Expect(matchup.Away.Color.Name).Should(gomega.Equal("Scarlet"))
+}
+
+func TestNewMatchupWithoutRoles(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	cardinals := newMatchupTeam("Arizona Cardinals",
+		&Color{Name: "Cardinal Red", Hex: "#9B2743"},
+		&Color{Name: "Black", Hex: "#010101"},
+		&Color{Name: "White", Hex: "#FFFFFF"},
+	)
+	falcons := newMatchupTeam("Atlanta Falcons",
+		&Color{Name: "Black", Hex: "#010101"},
+		&Color{Name: "Red", Hex: "#A6192E"},
+		&Color{Name: "Silver", Hex: "#B2B4B2"},
+		&Color{Name: "White", Hex: "#FFFFFF"},
+	)
+	ravens := newMatchupTeam("Baltimore Ravens",
+		&Color{Name: "Black", Hex: "#010101"},
+		&Color{Name: "Purple", Hex: "#24125F"},
+		&Color{Name: "Gold", Hex: "#9A7611"},
+		&Color{Name: "White", Hex: "#FFFFFF"},
+	)
+
+	// the first colors are used when they don't clash, not the pair furthest apart
+	matchup, err := NewMatchup(cardinals, falcons)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(matchup.Home.Color.Name).Should(gomega.Equal("Cardinal Red"))
+	g.Expect(matchup.Away.Color.Name).Should(gomega.Equal("Black"))
+	g.Expect(matchup.Clash).Should(gomega.BeFalse())
+
+	// when they do, the away team moves to its next color first
+	matchup, err = NewMatchup(falcons, ravens)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(matchup.Home.Color.Name).Should(gomega.Equal("Black"))
+	g.Expect(matchup.Away.Color.Name).Should(gomega.Equal("Purple"))
+	g.Expect(matchup.Distance).Should(gomega.BeNumerically(">=", MatchupMinDistance))
+
+	bills := newMatchupTeam("Buffalo Bills", &Color{Name: "Royal Blue", Hex: "#003087"}, &Color{Name: "Scarlet Red", Hex: "#C8102E"})
+	sabres := newMatchupTeam("Buffalo Sabres", &Color{Name: "Navy", Hex: "#041E42"}, &Color{Name: "Gold", Hex: "#FFB81C"})
+
+	matchup, err = NewMatchup(bills, sabres)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(matchup.Home.Color.Name).Should(gomega.Equal("Royal Blue"))
+	g.Expect(matchup.Away.Color.Name).Should(gomega.Equal("Gold"))
+	g.Expect(matchup.Clash).Should(gomega.BeFalse())
+}
+
+func TestNewMatchupClash(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	bills := newMatchupTeam("Buffalo Bills", &Color{Name: "Navy", Hex: "#0C2340", Role: RolePrimary})
+	sabres := newMatchupTeam("Buffalo Sabres", &Color{Name: "Navy", Hex: "#041E42", Role: RolePrimary})
+
+	matchup, err := NewMatchup(bills, sabres)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(matchup.Home.Color.Name).Should(gomega.Equal("Navy"))
+	g.Expect(matchup.Away.Color.Name).Should(gomega.Equal("Navy"))
+	g.Expect(matchup.Home.Text).Should(gomega.Equal(whiteText))
+	g.Expect(matchup.Distance).Should(gomega.BeNumerically("<", MatchupMinDistance))
+	g.Expect(matchup.Clash).Should(gomega.BeTrue())
+
+	_, err = NewMatchup(bills, &Team{Name: "Buffalo Bandits", League: "NLL"})
+	g.Expect(err).Should(gomega.Equal(ErrEraNotFound))
+}
+
+func TestModelMatchup(t *testing.T) {
+	g := gomega.NewWithT(t)
+	m, err := New(testFile)
+	g.Expect(err).Should(gomega.BeNil())
+
+	matchup, err := m.Matchup("nfl", "buffalo-bills", "nhl", "buffalo sabres")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(matchup.Home.Team).Should(gomega.Equal("Buffalo Bills"))
+	g.Expect(matchup.Home.Color.Name).Should(gomega.Equal("Scarlet Red"))
+	g.Expect(matchup.Away.Team).Should(gomega.Equal("Buffalo Sabres"))
+	g.Expect(matchup.Away.Color.Name).Should(gomega.Equal("Navy"))
+	g.Expect(matchup.Away.Link).Should(gomega.Equal("/leagues/nhl/buffalo-sabres"))
+
+	_, err = m.Matchup("nfl", "buffalo bills", "nfl", "buffalo sabres")
+	g.Expect(err).Should(gomega.Equal(ErrTeamNotFound))
+
+	_, err = m.Matchup("xfl", "buffalo bills", "nhl", "buffalo sabres")
+	g.Expect(err).Should(gomega.Equal(ErrLeagueNotFound))
+}
@@ -17,13 +17,15 @@ limitations under the License.
 package model
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/url"
-	"os"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -33,53 +35,155 @@ var ErrLeagueNotFound = errors.New("model: league not found")
 //ErrTeamNotFound represents an error when the team is not found
 var ErrTeamNotFound = errors.New("model: team not found")
 
+//ErrDivisionNotFound represents an error when the division is not found
+var ErrDivisionNotFound = errors.New("model: division not found")
+
+//ErrEraNotFound represents an error when the team has no matching era
+var ErrEraNotFound = errors.New("model: era not found")
+
+//ErrEraAmbiguous represents an error when more than one of the team's eras
+//started in the year
+var ErrEraAmbiguous = errors.New("model: more than one era started in the year")
+
 //Model provides capabilities for finding team colors
 type Model struct {
 	raw           *DataFile
+	sortedTeams   Teams
 	leagues       []*LeagueRecord
 	teamsByLeague map[string]*leagueData
-}
+	teamsByID     map[int]*Team
+	eraChanges    []Date
+	searchIndex   []*searchEntry
+	prefixIndex   prefixIndex
 
-//DataFile represents how the file is stored on disk
-type DataFile struct {
-	Generated time.Time `json:"generated"`
-	Teams     Teams     `json:"teams"`
+	//colorIndex is rebuilt when the eras in effect change; see currentColorIndex
+	colorIndexMu    sync.Mutex
+	colorIndex      []*indexedColor
+	colorIndexSince Date
 }
 
 //New returns a new model instance
-//An error is returned if the file cannot be found or parsed.
+//An error is returned if the file cannot be found, parsed, or fails validation.
 func New(dataFilename string) (*Model, error) {
-	file, err := os.Open(dataFilename)
+	data, err := Load(dataFilename)
 	if err != nil {
 		return nil, err
 	}
 
+	return NewFromDataFile(data), nil
+}
+
+//Load reads and validates a data file
+//If the file fails validation, the error will be a ValidationErrors.
+func Load(dataFilename string) (*DataFile, error) {
+	raw, err := ioutil.ReadFile(dataFilename)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(raw)
+}
+
+//Parse decodes and validates the contents of a data file
+//If the data fails validation, the error will be a ValidationErrors.
+func Parse(raw []byte) (*DataFile, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+
 	var data DataFile
-	if err := json.NewDecoder(file).Decode(&data); err != nil {
+	if err := dec.Decode(&data); err != nil {
+		return nil, decodeError(raw, err)
+	}
+	data.Migrate()
+
+	if err := Validate(&data); err != nil {
+		if errs, ok := err.(ValidationErrors); ok {
+			errs.locate(raw)
+		}
+
 		return nil, err
 	}
 
-	sort.Sort(data.Teams)
+	return &data, nil
+}
+
+//decodeError adds the line and column to JSON errors that have an offset
+func decodeError(raw []byte, err error) error {
+	var offset int64
+	switch e := err.(type) {
+	case *json.SyntaxError:
+		offset = e.Offset
+	case *json.UnmarshalTypeError:
+		offset = e.Offset
+	default:
+		return fmt.Errorf("model: %w", err)
+	}
+
+	line, column := lineAndColumn(raw, offset)
+	return fmt.Errorf("model: line %d, column %d: %w", line, column, err)
+}
+
+//NewFromDataFile returns a new model instance built from an already loaded data file
+//The data file should have been validated; see Validate.
+func NewFromDataFile(data *DataFile) *Model {
+	data.Migrate()
+
+	// keep the data file in the order it was authored
+	sortedTeams := make(Teams, len(data.Teams))
+	copy(sortedTeams, data.Teams)
+	sort.Sort(sortedTeams)
 
 	teamsByLeague := make(map[string]*leagueData)
+	teamsByID := make(map[int]*Team, len(sortedTeams))
 	uniqLeagues := make(map[string]bool)
 
-	for _, team := range data.Teams {
+	for _, team := range sortedTeams {
 		uniqLeagues[team.League] = true
 
 		league := strings.ToLower(team.League)
-		_, ok := teamsByLeague[league]
+		ld, ok := teamsByLeague[league]
 		if !ok {
-			teamsByLeague[league] = &leagueData{
-				sortedTeams: make(Teams, 0, 1),
-				teamByName:  make(map[string]*Team),
+			ld = &leagueData{
+				sortedTeams:     make(Teams, 0, 1),
+				teamBySlug:      make(map[string]*Team),
+				divisions:       make([]*DivisionRecord, 0),
+				teamsByDivision: make(map[string]Teams),
 			}
+			teamsByLeague[league] = ld
 		}
 
-		team.Link = fmt.Sprintf("/leagues/%s/%s", url.PathEscape(league), url.PathEscape(strings.ToLower(team.Name)))
+		team.Slug = Slugify(team.Name)
+		team.Link = fmt.Sprintf("/leagues/%s/%s", url.PathEscape(league), team.Slug)
 
-		teamsByLeague[league].sortedTeams = append(teamsByLeague[league].sortedTeams, team)
-		teamsByLeague[league].teamByName[strings.ToLower(team.Name)] = team
+		ld.sortedTeams = append(ld.sortedTeams, team)
+		ld.teamBySlug[team.Slug] = team
+		teamsByID[team.ID] = team
+
+		if team.Division != "" {
+			division := Slugify(team.Division)
+			if _, ok := ld.teamsByDivision[division]; !ok {
+				ld.divisions = append(ld.divisions, &DivisionRecord{
+					Division: team.Division,
+					League:   team.League,
+					Link:     fmt.Sprintf("/leagues/%s/divisions/%s", url.PathEscape(league), division),
+				})
+			}
+			ld.teamsByDivision[division] = append(ld.teamsByDivision[division], team)
+		}
+	}
+
+	// a former or predecessor name never hides a team that has it now
+	for _, team := range sortedTeams {
+		ld := teamsByLeague[strings.ToLower(team.League)]
+		for _, formerName := range team.historicalNames() {
+			if slug := Slugify(formerName); ld.teamBySlug[slug] == nil {
+				ld.teamBySlug[slug] = team
+			}
+		}
+	}
+
+	for _, ld := range teamsByLeague {
+		sort.Sort(sortByDivisionRecord(ld.divisions))
 	}
 
 	leagues := make([]*LeagueRecord, 0, len(uniqLeagues))
@@ -92,16 +196,25 @@ func New(dataFilename string) (*Model, error) {
 
 	sort.Sort(sortByLeagueRecord(leagues))
 
-	return &Model{
-		raw:           &data,
+	m := &Model{
+		raw:           data,
+		sortedTeams:   sortedTeams,
 		leagues:       leagues,
 		teamsByLeague: teamsByLeague,
-	}, nil
+		teamsByID:     teamsByID,
+		eraChanges:    newEraChanges(sortedTeams),
+		searchIndex:   newSearchIndex(sortedTeams),
+	}
+	m.colorIndexSince = m.LastEraChange(Today())
+	m.colorIndex = newColorIndex(sortedTeams)
+	m.prefixIndex = newPrefixIndex(m)
+
+	return m
 }
 
 //AllTeams returns all teams
 func (m *Model) AllTeams() Teams {
-	return m.raw.Teams
+	return m.sortedTeams
 }
 
 //Leagues returns a list of all the leagues
@@ -110,16 +223,15 @@ func (m *Model) Leagues() []*LeagueRecord {
 }
 
 //TeamByLeagueAndName returns a team by the league and team name
+//The name may also be the team's slug, one of its former names, the name of one of
+//its predecessors, or their slugs.
 func (m *Model) TeamByLeagueAndName(leagueName, name string) (*Team, error) {
-	lowerLeagueName := strings.ToLower(leagueName)
-	lowerName := strings.ToLower(name)
-
-	league, ok := m.teamsByLeague[lowerLeagueName]
+	league, ok := m.teamsByLeague[strings.ToLower(leagueName)]
 	if !ok {
 		return nil, ErrLeagueNotFound
 	}
 
-	team, ok := league.teamByName[lowerName]
+	team, ok := league.teamBySlug[Slugify(name)]
 	if !ok {
 		return nil, ErrTeamNotFound
 	}
@@ -127,6 +239,32 @@ func (m *Model) TeamByLeagueAndName(leagueName, name string) (*Team, error) {
 	return team, nil
 }
 
+//TeamByID returns a team by its ID
+func (m *Model) TeamByID(id int) (*Team, error) {
+	team, ok := m.teamsByID[id]
+	if !ok {
+		return nil, ErrTeamNotFound
+	}
+
+	return team, nil
+}
+
+//ColorsAt returns the colors a team used in the given year
+//ErrEraNotFound is returned if the year is before the team's first known era.
+func (m *Model) ColorsAt(leagueName, name string, year int) ([]*Color, error) {
+	team, err := m.TeamByLeagueAndName(leagueName, name)
+	if err != nil {
+		return nil, err
+	}
+
+	era, err := team.EraAt(year)
+	if err != nil {
+		return nil, err
+	}
+
+	return era.Colors, nil
+}
+
 //TeamsByLeague returns a list of all teams in a given league
 func (m *Model) TeamsByLeague(league string) (Teams, error) {
 	teams, ok := m.teamsByLeague[strings.ToLower(league)]
@@ -137,13 +275,39 @@ func (m *Model) TeamsByLeague(league string) (Teams, error) {
 	return teams.sortedTeams, nil
 }
 
-//Search will search a team in by its name
+//Divisions returns a list of all the divisions in a given league
+func (m *Model) Divisions(league string) ([]*DivisionRecord, error) {
+	ld, ok := m.teamsByLeague[strings.ToLower(league)]
+	if !ok {
+		return nil, ErrLeagueNotFound
+	}
+
+	return ld.divisions, nil
+}
+
+//TeamsByDivision returns a list of all teams in a given division of a league
+//The division can be given by its slug or its name.
+func (m *Model) TeamsByDivision(league, division string) (Teams, error) {
+	ld, ok := m.teamsByLeague[strings.ToLower(league)]
+	if !ok {
+		return nil, ErrLeagueNotFound
+	}
+
+	teams, ok := ld.teamsByDivision[Slugify(division)]
+	if !ok {
+		return nil, ErrDivisionNotFound
+	}
+
+	return teams, nil
+}
+
+//Search will search a team in by its name or aliases
+//Teams are ordered best match first; see SearchRanked.
 func (m *Model) Search(match string) Teams {
-	teams := make(Teams, 0)
-	for _, team := range m.raw.Teams {
-		if strings.Contains(strings.ToLower(team.Name), strings.ToLower(match)) {
-			teams = append(teams, team)
-		}
+	results, _ := m.SearchRanked(match, "")
+	teams := make(Teams, len(results))
+	for i, result := range results {
+		teams[i] = result.Team
 	}
 
 	return teams
@@ -153,3 +317,44 @@ func (m *Model) Search(match string) Teams {
 func (m *Model) GenerationDate() time.Time {
 	return m.raw.Generated
 }
+
+//LastEraChange returns the latest day, on or before day, that a team's era started or ended
+//Team.CurrentEra is the same for every team from that day until the next change. A zero Date is
+//returned if no era had started by day.
+func (m *Model) LastEraChange(day Date) Date {
+	i := sort.Search(len(m.eraChanges), func(i int) bool {
+		return m.eraChanges[i].After(day.Time)
+	})
+	if i == 0 {
+		return Date{}
+	}
+
+	return m.eraChanges[i-1]
+}
+
+//newEraChanges returns the days that any team's era started or ended, in order
+//An era ends on the day after its end date.
+func newEraChanges(teams Teams) []Date {
+	changes := make([]Date, 0, len(teams)*2)
+	for _, team := range teams {
+		for _, era := range team.Eras {
+			changes = append(changes, era.StartDate())
+			if era.End != nil {
+				changes = append(changes, Date{era.End.AddDate(0, 0, 1)})
+			}
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Before(changes[j].Time)
+	})
+
+	uniq := changes[:0]
+	for _, day := range changes {
+		if len(uniq) == 0 || !uniq[len(uniq)-1].Equal(day.Time) {
+			uniq = append(uniq, day)
+		}
+	}
+
+	return uniq
+}
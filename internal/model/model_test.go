@@ -55,7 +55,7 @@ func TestAllTeams(t *testing.T) {
 	g.Expect(teams[1].Name).Should(gomega.Equal("Buffalo Sabres"))
 	g.Expect(teams[2].Name).Should(gomega.Equal("The Ohio State University"))
 	g.Expect(teams[3].Name).Should(gomega.Equal("University At Buffalo, The State University Of New York"))
-	g.Expect(teams[3].Link).Should(gomega.Equal("/leagues/ncaa/university%20at%20buffalo%2C%20the%20state%20university%20of%20new%20york"))
+	g.Expect(teams[3].Link).Should(gomega.Equal("/leagues/ncaa/university-at-buffalo-the-state-university-of-new-york"))
 }
 
 func TestTeamID(t *testing.T) {
@@ -93,21 +93,67 @@ func TestTeamByLeagueAndName(t *testing.T) {
 	g.Expect(team).ShouldNot(gomega.BeNil())
 	g.Expect(team.Name).Should(gomega.Equal("Buffalo Bills"))
 	g.Expect(team.League).Should(gomega.Equal("NFL"))
-	g.Expect(team.Link).Should(gomega.Equal("/leagues/nfl/buffalo%20bills"))
+	g.Expect(team.Link).Should(gomega.Equal("/leagues/nfl/buffalo-bills"))
+	// eras without a start date are migrated to start on January 1
 	g.Expect(team.Eras).Should(gomega.Equal([]*Era{
 		{
-			Year: 2011, Colors: []*Color{
+			Year: 2011, Start: NewDate(2011, time.January, 1), Colors: []*Color{
 				{Name: "Royal Blue", Hex: "#003087"},
 				{Name: "Scarlet Red", Hex: "#C8102E"},
 			},
 		},
 		{
-			Year: 2002, Colors: []*Color{
+			Year: 2002, Start: NewDate(2002, time.January, 1), Colors: []*Color{
 				{Name: "Midnight Navy", Hex: "#091F2C"},
 			},
 		},
 	}))
 	g.Expect(team.Division).Should(gomega.Equal("AFC"))
+
+	team, err = m.TeamByLeagueAndName("nfl", "buffalo-bills")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(team.Slug).Should(gomega.Equal("buffalo-bills"))
+}
+
+func TestTeamByLeagueAndNameWithFormerNames(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	m, err := NewMemoryStore(time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC),
+		&Team{Name: "Arizona Coyotes", League: "NHL", FormerNames: []string{"Winnipeg Jets", "Phoenix Coyotes"}, Eras: []*Era{
+			{Year: 2003, Colors: []*Color{{Name: "Brick Red", Hex: "#8C2633"}}},
+		}},
+		&Team{Name: "Winnipeg Jets", League: "NHL", FormerNames: []string{"Atlanta Thrashers"}, Eras: []*Era{
+			{Year: 2011, Colors: []*Color{{Name: "Polar Night Blue", Hex: "#041E42"}}},
+		}},
+	)
+	g.Expect(err).Should(gomega.BeNil())
+
+	team, err := m.TeamByLeagueAndName("nhl", "phoenix-coyotes")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(team.Name).Should(gomega.Equal("Arizona Coyotes"))
+
+	team, err = m.TeamByLeagueAndName("nhl", "Atlanta Thrashers")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(team.Name).Should(gomega.Equal("Winnipeg Jets"))
+
+	// a team's current name wins over another team's former name
+	team, err = m.TeamByLeagueAndName("nhl", "winnipeg-jets")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(team.Name).Should(gomega.Equal("Winnipeg Jets"))
+	g.Expect(team.ID).Should(gomega.Equal(2))
+}
+
+func TestTeamByID(t *testing.T) {
+	g := gomega.NewWithT(t)
+	m, _ := New(testFile)
+
+	team, err := m.TeamByID(19)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(team.Name).Should(gomega.Equal("Buffalo Sabres"))
+
+	team, err = m.TeamByID(20)
+	g.Expect(team).Should(gomega.BeNil())
+	g.Expect(err).Should(gomega.MatchError(ErrTeamNotFound))
 }
 
 func TestTeamsByLeague(t *testing.T) {
@@ -144,3 +190,66 @@ func TestSearch(t *testing.T) {
 	g.Expect(teams[1].Name).Should(gomega.Equal("Buffalo Sabres"))
 	g.Expect(teams[2].Name).Should(gomega.Equal("University At Buffalo, The State University Of New York"))
 }
+
+func TestColorsAt(t *testing.T) {
+	g := gomega.NewWithT(t)
+	m, _ := New(testFile)
+
+	colors, err := m.ColorsAt("nfl", "bad", 2010)
+	g.Expect(colors).Should(gomega.BeNil())
+	g.Expect(err).Should(gomega.MatchError(ErrTeamNotFound))
+
+	colors, err = m.ColorsAt("nfl", "buffalo bills", 2001)
+	g.Expect(colors).Should(gomega.BeNil())
+	g.Expect(err).Should(gomega.MatchError(ErrEraNotFound))
+
+	colors, err = m.ColorsAt("nfl", "buffalo bills", 2002)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(colors).Should(gomega.Equal([]*Color{{Name: "Midnight Navy", Hex: "#091F2C"}}))
+
+	colors, err = m.ColorsAt("nfl", "buffalo bills", 2010)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(colors).Should(gomega.Equal([]*Color{{Name: "Midnight Navy", Hex: "#091F2C"}}))
+
+	colors, err = m.ColorsAt("nfl", "buffalo bills", 2020)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(colors[0].Name).Should(gomega.Equal("Royal Blue"))
+}
+
+func TestDivisions(t *testing.T) {
+	g := gomega.NewWithT(t)
+	m, _ := New(testFile)
+
+	divisions, err := m.Divisions("bad")
+	g.Expect(divisions).Should(gomega.BeNil())
+	g.Expect(err).Should(gomega.MatchError(ErrLeagueNotFound))
+
+	divisions, err = m.Divisions("NCAA")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(divisions).Should(gomega.Equal([]*DivisionRecord{
+		{Division: "Big Ten Conference", League: "NCAA", Link: "/leagues/ncaa/divisions/big-ten-conference"},
+		{Division: "Mid-American Conference", League: "NCAA", Link: "/leagues/ncaa/divisions/mid-american-conference"},
+	}))
+
+	divisions, err = m.Divisions("nhl")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(divisions).Should(gomega.BeEmpty())
+}
+
+func TestTeamsByDivision(t *testing.T) {
+	g := gomega.NewWithT(t)
+	m, _ := New(testFile)
+
+	teams, err := m.TeamsByDivision("bad", "afc")
+	g.Expect(teams).Should(gomega.BeNil())
+	g.Expect(err).Should(gomega.MatchError(ErrLeagueNotFound))
+
+	teams, err = m.TeamsByDivision("nfl", "nfc")
+	g.Expect(teams).Should(gomega.BeNil())
+	g.Expect(err).Should(gomega.MatchError(ErrDivisionNotFound))
+
+	teams, err = m.TeamsByDivision("nFl", "aFc")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(len(teams)).Should(gomega.Equal(1))
+	g.Expect(teams[0].Name).Should(gomega.Equal("Buffalo Bills"))
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// ColorMatch is a team color found by a nearest color search
+type ColorMatch struct {
+	// Team is the name of the team using the color
+	Team string `json:"team"`
+	// League is the league the team plays in
+	League string `json:"league"`
+	// Color is the team's color
+	Color *Color `json:"color"`
+	// Distance is the CIEDE2000 difference from the searched color
+	Distance float64 `json:"distance"`
+	// Link is a link to retrieve the team
+	Link string `json:"_link"`
+}
+
+// indexedColor is a color in a team's current era with its precomputed Lab value
+type indexedColor struct {
+	team  *Team
+	color *Color
+	lab   Lab
+}
+
+// newColorIndex indexes the colors of every team's current era
+func newColorIndex(teams Teams) []*indexedColor {
+	index := make([]*indexedColor, 0, len(teams)*4)
+	for _, team := range teams {
+		for _, color := range team.CurrentEra().Colors {
+			rgb, err := ParseHex(color.Hex)
+			if err != nil {
+				continue
+			}
+
+			index = append(index, &indexedColor{team: team, color: color, lab: rgb.Lab()})
+		}
+	}
+
+	return index
+}
+
+// currentColorIndex returns the color index, rebuilding it if an era has
+// started or ended since it was built
+func (m *Model) currentColorIndex() []*indexedColor {
+	since := m.LastEraChange(Today())
+
+	m.colorIndexMu.Lock()
+	defer m.colorIndexMu.Unlock()
+
+	if !m.colorIndexSince.Equal(since.Time) {
+		m.colorIndex = newColorIndex(m.sortedTeams)
+		m.colorIndexSince = since
+	}
+
+	return m.colorIndex
+}
+
+// NearestColors returns the team colors perceptually closest to hex, closest
+// first. Only the current era of each team is searched. If leagueName is not
+// empty, only teams in that league are searched.
+func (m *Model) NearestColors(hex, leagueName string, limit int) ([]*ColorMatch, error) {
+	rgb, err := ParseHex(hex)
+	if err != nil {
+		return nil, err
+	}
+
+	if leagueName != "" {
+		if _, ok := m.teamsByLeague[strings.ToLower(leagueName)]; !ok {
+			return nil, ErrLeagueNotFound
+		}
+	}
+
+	lab := rgb.Lab()
+	index := m.currentColorIndex()
+	matches := make([]*ColorMatch, 0, len(index))
+	for _, entry := range index {
+		if leagueName != "" && !strings.EqualFold(entry.team.League, leagueName) {
+			continue
+		}
+
+		matches = append(matches, &ColorMatch{
+			Team:     entry.team.Name,
+			League:   entry.team.League,
+			Color:    entry.color,
+			Distance: DeltaE2000(lab, entry.lab),
+			Link:     entry.team.Link,
+		})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Distance < matches[j].Distance
+	})
+
+	if limit >= 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	for _, match := range matches {
+		match.Distance = math.Round(match.Distance*100) / 100
+	}
+
+	return matches, nil
+}
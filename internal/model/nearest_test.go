@@ -0,0 +1,96 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+)
+
+func TestNearestColors(t *testing.T) {
+	g := gomega.NewWithT(t)
+	m, _ := New(testFile)
+
+	matches, err := m.NearestColors("#003366", "", 10)
+	g.Expect(err).Should(gomega.BeNil())
+	// the Bills' 2002 Midnight Navy is not in their current era
+	g.Expect(len(matches)).Should(gomega.Equal(6))
+	g.Expect(matches[0].Team).Should(gomega.Equal("Buffalo Bills"))
+	g.Expect(matches[0].Color).Should(gomega.Equal(&Color{Name: "Royal Blue", Hex: "#003087"}))
+	g.Expect(matches[0].Link).Should(gomega.Equal("/leagues/nfl/buffalo-bills"))
+	for i := 1; i < len(matches); i++ {
+		g.Expect(matches[i].Distance).Should(gomega.BeNumerically(">=", matches[i-1].Distance))
+	}
+	g.Expect(matches[5].Color.Name).Should(gomega.Equal("White"))
+
+	matches, err = m.NearestColors("#FFFFFF", "ncaa", 1)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(matches).Should(gomega.Equal([]*ColorMatch{{
+		Team:     "University At Buffalo, The State University Of New York",
+		League:   "NCAA",
+		Color:    &Color{Name: "White", Hex: "#FFFFFF"},
+		Distance: 0,
+		Link:     "/leagues/ncaa/university-at-buffalo-the-state-university-of-new-york",
+	}}))
+
+	_, err = m.NearestColors("#FFFFFF", "bad", 1)
+	g.Expect(err).Should(gomega.MatchError(ErrLeagueNotFound))
+
+	_, err = m.NearestColors("blue", "", 1)
+	g.Expect(err).ShouldNot(gomega.BeNil())
+}
+
+func TestNearestColorsWhenErasChange(t *testing.T) {
+	g := gomega.NewWithT(t)
+	defer func(original func() time.Time) { now = original }(now)
+	now = func() time.Time { return time.Date(2021, time.August, 31, 23, 0, 0, 0, time.UTC) }
+
+	end := NewDate(2021, time.September, 30)
+	m, err := NewMemoryStore(time.Date(2021, time.August, 1, 0, 0, 0, 0, time.UTC), &Team{
+		Name:   "Buffalo Bills",
+		League: "NFL",
+		Eras: []*Era{
+			{Year: 2021, Start: NewDate(2021, time.September, 1), End: &end, Colors: []*Color{{Name: "Red", Hex: "#C60C30"}}},
+			{Year: 2011, Colors: []*Color{{Name: "Royal Blue", Hex: "#00338D"}}},
+		},
+	})
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(m.LastEraChange(Today())).Should(gomega.Equal(NewDate(2011, time.January, 1)))
+	g.Expect(m.LastEraChange(NewDate(2010, time.December, 31))).Should(gomega.Equal(Date{}))
+
+	matches, err := m.NearestColors("#000000", "", -1)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(matches[0].Color.Name).Should(gomega.Equal("Royal Blue"))
+
+	// the next day the new era is current without reloading
+	now = func() time.Time { return time.Date(2021, time.September, 1, 1, 0, 0, 0, time.UTC) }
+	g.Expect(m.LastEraChange(Today())).Should(gomega.Equal(NewDate(2021, time.September, 1)))
+
+	matches, err = m.NearestColors("#000000", "", -1)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(matches[0].Color.Name).Should(gomega.Equal("Red"))
+
+	// and when it ends the previous era is current again
+	now = func() time.Time { return time.Date(2021, time.October, 1, 0, 0, 0, 0, time.UTC) }
+	g.Expect(m.LastEraChange(Today())).Should(gomega.Equal(NewDate(2021, time.October, 1)))
+
+	matches, err = m.NearestColors("#000000", "", -1)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(matches[0].Color.Name).Should(gomega.Equal("Royal Blue"))
+}
@@ -0,0 +1,238 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// Scores for each kind of match, from best to worst. A search result's score
+// is the best of any match against the team's name or aliases.
+const (
+	scoreExact     = 1.0
+	scorePrefix    = 0.9
+	scoreTokens    = 0.5 // plus up to scoreTokensMax for how well each token matched
+	scoreTokensMax = 0.3
+	scoreSubstring = 0.4
+)
+
+// Per-token scores that are averaged into a token match
+const (
+	tokenExact  = 1.0
+	tokenPrefix = 0.75
+	tokenFuzzy  = 0.5 // reduced for each edit
+)
+
+// SearchResult is a team found by a search along with how well it matched
+type SearchResult struct {
+	*Team
+	// Score is how well the team matched, from 0 to 1
+	Score float64 `json:"score"`
+}
+
+// searchEntry is a team with its name and aliases normalized for searching
+type searchEntry struct {
+	team   *Team
+	terms  []string
+	tokens []string
+}
+
+// normalize lower-cases s and reduces it to words separated by single spaces
+func normalize(s string) string {
+	return strings.Replace(Slugify(s), "-", " ", -1)
+}
+
+func newSearchIndex(teams Teams) []*searchEntry {
+	index := make([]*searchEntry, len(teams))
+	for i, team := range teams {
+		entry := &searchEntry{team: team}
+		seen := make(map[string]bool)
+		for _, term := range append([]string{team.Name}, team.Aliases...) {
+			term = normalize(term)
+			entry.terms = append(entry.terms, term)
+			for _, token := range strings.Fields(term) {
+				if !seen[token] {
+					seen[token] = true
+					entry.tokens = append(entry.tokens, token)
+				}
+			}
+		}
+		index[i] = entry
+	}
+
+	return index
+}
+
+// score returns how well the query matches the entry, or 0 if it doesn't
+func (e *searchEntry) score(query string, queryTokens []string) float64 {
+	best := 0.0
+	for _, term := range e.terms {
+		switch {
+		case term == query:
+			return scoreExact
+		case strings.HasPrefix(term, query):
+			best = math.Max(best, scorePrefix)
+		case strings.Contains(term, query):
+			best = math.Max(best, scoreSubstring)
+		}
+	}
+
+	if best >= scoreTokens+scoreTokensMax {
+		return best
+	}
+
+	// every query token must match one of the entry's tokens
+	total := 0.0
+	for _, queryToken := range queryTokens {
+		tokenBest := 0.0
+		for _, token := range e.tokens {
+			tokenBest = math.Max(tokenBest, scoreToken(queryToken, token))
+		}
+
+		if tokenBest == 0 {
+			return best
+		}
+		total += tokenBest
+	}
+
+	return math.Max(best, scoreTokens+scoreTokensMax*total/float64(len(queryTokens)))
+}
+
+func scoreToken(queryToken, token string) float64 {
+	switch {
+	case queryToken == token:
+		return tokenExact
+	case strings.HasPrefix(token, queryToken):
+		return tokenPrefix
+	}
+
+	maxEdits := maxTypos(queryToken)
+	if maxEdits == 0 {
+		return 0
+	}
+
+	if d := editDistance(queryToken, token, maxEdits); d <= maxEdits {
+		return tokenFuzzy * (1 - float64(d)/float64(maxEdits+1))
+	}
+
+	return 0
+}
+
+// maxTypos is how many edits are tolerated for a query token; short tokens
+// must match exactly or they would match almost anything
+func maxTypos(token string) int {
+	switch n := len(token); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance returns the optimal string alignment distance between a and
+// b, which counts insertions, deletions, substitutions and transpositions of
+// adjacent characters. Once the distance is known to exceed max, max+1 is
+// returned.
+func editDistance(a, b string, max int) int {
+	if diff := len(a) - len(b); diff > max || -diff > max {
+		return max + 1
+	}
+
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				curr[j] = minInt(curr[j], prev2[j-2]+1)
+			}
+			rowMin = minInt(rowMin, curr[j])
+		}
+
+		if rowMin > max {
+			return max + 1
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+
+	return prev[len(b)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+
+	return m
+}
+
+// SearchRanked searches teams by name and alias, allowing for prefixes,
+// partial words and typos. Results are ordered best match first. If
+// leagueName is not empty, only teams in that league are searched.
+func (m *Model) SearchRanked(query, leagueName string) ([]*SearchResult, error) {
+	if leagueName != "" {
+		if _, ok := m.teamsByLeague[strings.ToLower(leagueName)]; !ok {
+			return nil, ErrLeagueNotFound
+		}
+	}
+
+	results := make([]*SearchResult, 0)
+	query = normalize(query)
+	if query == "" {
+		return results, nil
+	}
+
+	queryTokens := strings.Fields(query)
+	for _, entry := range m.searchIndex {
+		if leagueName != "" && !strings.EqualFold(entry.team.League, leagueName) {
+			continue
+		}
+
+		if score := entry.score(query, queryTokens); score > 0 {
+			results = append(results, &SearchResult{
+				Team:  entry.team,
+				Score: math.Round(score*1000) / 1000,
+			})
+		}
+	}
+
+	// the index is sorted by name, so ties stay in name order
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	return results, nil
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+)
+
+func newSearchModel() *Model {
+	era := []*Era{{Year: 2000, Colors: []*Color{{Name: "Red", Hex: "#FF0000"}}}}
+	return NewFromDataFile(&DataFile{
+		Generated: time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC),
+		Teams: Teams{
+			{Name: "Arizona Cardinals", League: "NFL", Eras: era, Aliases: []string{"ARI", "Cards"}},
+			{Name: "San Francisco 49ers", League: "NFL", Eras: era, Aliases: []string{"SF", "Niners"}},
+			{Name: "St. Louis Cardinals", League: "MLB", Eras: era, Aliases: []string{"STL", "Cards"}},
+			{Name: "Arizona Coyotes", League: "NHL", Eras: era, Aliases: []string{"ARI", "Yotes"}},
+		},
+	})
+}
+
+func searchNames(results []*SearchResult) []string {
+	names := make([]string, len(results))
+	for i, result := range results {
+		names[i] = result.Name
+	}
+
+	return names
+}
+
+func TestSearchRanked(t *testing.T) {
+	g := gomega.NewWithT(t)
+	m := newSearchModel()
+
+	results, err := m.SearchRanked("Niners", "")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(searchNames(results)).Should(gomega.Equal([]string{"San Francisco 49ers"}))
+	g.Expect(results[0].Score).Should(gomega.Equal(1.0))
+
+	// ties are broken by name
+	results, _ = m.SearchRanked("ari", "")
+	g.Expect(searchNames(results)).Should(gomega.Equal([]string{"Arizona Cardinals", "Arizona Coyotes"}))
+
+	results, _ = m.SearchRanked("ari", "nfl")
+	g.Expect(searchNames(results)).Should(gomega.Equal([]string{"Arizona Cardinals"}))
+
+	// an exact alias beats a prefix of a name
+	results, _ = m.SearchRanked("cards", "")
+	g.Expect(searchNames(results)).Should(gomega.Equal([]string{"Arizona Cardinals", "St. Louis Cardinals"}))
+
+	results, _ = m.SearchRanked("cardinals", "")
+	g.Expect(searchNames(results)).Should(gomega.Equal([]string{"Arizona Cardinals", "St. Louis Cardinals"}))
+	g.Expect(results[0].Score).Should(gomega.Equal(0.8))
+
+	// prefix of the name beats matching one of its words
+	results, _ = m.SearchRanked("arizona c", "")
+	g.Expect(searchNames(results)).Should(gomega.Equal([]string{"Arizona Cardinals", "Arizona Coyotes"}))
+	g.Expect(results[0].Score).Should(gomega.Equal(0.9))
+
+	results, _ = m.SearchRanked("Cardnals", "")
+	g.Expect(searchNames(results)).Should(gomega.Equal([]string{"Arizona Cardinals", "St. Louis Cardinals"}))
+	g.Expect(results[0].Score).Should(gomega.BeNumerically("<", 0.8))
+
+	results, _ = m.SearchRanked("st louis", "")
+	g.Expect(searchNames(results)).Should(gomega.Equal([]string{"St. Louis Cardinals"}))
+
+	// short tokens must not match fuzzily
+	results, _ = m.SearchRanked("arx", "")
+	g.Expect(results).Should(gomega.BeEmpty())
+
+	results, _ = m.SearchRanked("  ", "")
+	g.Expect(results).Should(gomega.BeEmpty())
+
+	_, err = m.SearchRanked("ari", "bad")
+	g.Expect(err).Should(gomega.MatchError(ErrLeagueNotFound))
+}
+
+func TestEditDistance(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	g.Expect(editDistance("cardinals", "cardinals", 2)).Should(gomega.Equal(0))
+	g.Expect(editDistance("cardnals", "cardinals", 2)).Should(gomega.Equal(1))
+	g.Expect(editDistance("cadrinals", "cardinals", 2)).Should(gomega.Equal(1))
+	g.Expect(editDistance("kitten", "sitting", 3)).Should(gomega.Equal(3))
+	g.Expect(editDistance("kitten", "sitting", 1)).Should(gomega.Equal(2))
+	g.Expect(editDistance("a", "abcd", 1)).Should(gomega.Equal(2))
+}
@@ -0,0 +1,34 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+func TestSlugify(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	g.Expect(Slugify("Arizona Cardinals")).Should(gomega.Equal("arizona-cardinals"))
+	g.Expect(Slugify("Saint Joseph’s University")).Should(gomega.Equal("saint-josephs-university"))
+	g.Expect(Slugify("University At Buffalo, The State University Of New York")).Should(gomega.Equal("university-at-buffalo-the-state-university-of-new-york"))
+	g.Expect(Slugify("Texas A&M University")).Should(gomega.Equal("texas-a-m-university"))
+	g.Expect(Slugify("  St. Louis Blues ")).Should(gomega.Equal("st-louis-blues"))
+	g.Expect(Slugify("PMS 7427 C")).Should(gomega.Equal("pms-7427-c"))
+}
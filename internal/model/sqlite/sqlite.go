@@ -0,0 +1,561 @@
+//go:build sqlite
+// +build sqlite
+
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	// registers the sqlite3 driver
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/weters/teamhex/internal/model"
+)
+
+// Backend is the name the backend is registered with
+const Backend = "sqlite"
+
+func init() {
+	model.RegisterBackend(Backend, model.Backend{
+		Open: func(source string) (model.Store, error) {
+			m, err := Open(source)
+			if err != nil {
+				return nil, err
+			}
+
+			return m, nil
+		},
+		Update: func(source string, fn func(data *model.DataFile) error) (model.Store, error) {
+			m, err := Update(source, fn)
+			if err != nil {
+				return nil, err
+			}
+
+			return m, nil
+		},
+	})
+}
+
+// schema keeps teams, eras, colors, aliases, former names and predecessors in
+// the order they were authored using their position columns. A predecessor's
+// eras are stored as JSON in the form they take in the data file.
+const schema = `
+CREATE TABLE IF NOT EXISTS meta (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS teams (
+	position INTEGER PRIMARY KEY,
+	id       INTEGER,
+	name     TEXT NOT NULL,
+	league   TEXT NOT NULL,
+	division TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS aliases (
+	team     INTEGER NOT NULL REFERENCES teams (position),
+	position INTEGER NOT NULL,
+	alias    TEXT NOT NULL,
+	PRIMARY KEY (team, position)
+);
+
+CREATE TABLE IF NOT EXISTS former_names (
+	team     INTEGER NOT NULL REFERENCES teams (position),
+	position INTEGER NOT NULL,
+	name     TEXT NOT NULL,
+	PRIMARY KEY (team, position)
+);
+
+CREATE TABLE IF NOT EXISTS predecessors (
+	team      INTEGER NOT NULL REFERENCES teams (position),
+	position  INTEGER NOT NULL,
+	name      TEXT NOT NULL,
+	city      TEXT NOT NULL DEFAULT '',
+	from_year INTEGER NOT NULL,
+	to_year   INTEGER NOT NULL,
+	eras      TEXT NOT NULL,
+	PRIMARY KEY (team, position)
+);
+
+CREATE TABLE IF NOT EXISTS eras (
+	team       INTEGER NOT NULL REFERENCES teams (position),
+	position   INTEGER NOT NULL,
+	year       INTEGER NOT NULL,
+	start_date TEXT,
+	end_date   TEXT,
+	label      TEXT NOT NULL DEFAULT '',
+	PRIMARY KEY (team, position)
+);
+
+CREATE TABLE IF NOT EXISTS colors (
+	team     INTEGER NOT NULL,
+	era      INTEGER NOT NULL,
+	position INTEGER NOT NULL,
+	name     TEXT NOT NULL,
+	hex      TEXT NOT NULL,
+	pantone  TEXT NOT NULL DEFAULT '',
+	cmyk_c   REAL,
+	cmyk_m   REAL,
+	cmyk_y   REAL,
+	cmyk_k   REAL,
+	rgb_r    INTEGER,
+	rgb_g    INTEGER,
+	rgb_b    INTEGER,
+	source   TEXT NOT NULL DEFAULT '',
+	role     TEXT NOT NULL DEFAULT '',
+	PRIMARY KEY (team, era, position),
+	FOREIGN KEY (team, era) REFERENCES eras (team, position)
+);
+`
+
+// addedEraColumns were added to the eras table after it was first released.
+// Write adds any that an older database is missing, and Read leaves the eras
+// of such a database to be migrated like an older data file.
+var addedEraColumns = []struct{ name, definition string }{
+	{"start_date", "TEXT"},
+	{"end_date", "TEXT"},
+	{"label", "TEXT NOT NULL DEFAULT ''"},
+}
+
+// addedColorColumns were added to the colors table after it was first
+// released. Write adds any that an older database is missing.
+var addedColorColumns = []struct{ name, definition string }{
+	{"pantone", "TEXT NOT NULL DEFAULT ''"},
+	{"cmyk_c", "REAL"},
+	{"cmyk_m", "REAL"},
+	{"cmyk_y", "REAL"},
+	{"cmyk_k", "REAL"},
+	{"rgb_r", "INTEGER"},
+	{"rgb_g", "INTEGER"},
+	{"rgb_b", "INTEGER"},
+	{"source", "TEXT NOT NULL DEFAULT ''"},
+	{"role", "TEXT NOT NULL DEFAULT ''"},
+}
+
+// Keys in the meta table
+const (
+	generatedKey = "generated"
+	nextIDKey    = "nextId"
+)
+
+// Open reads every team from the database and returns a model of them
+func Open(filename string) (*model.Model, error) {
+	db, err := sql.Open("sqlite3", "file:"+url.PathEscape(filename)+"?mode=ro")
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	data, err := Read(db)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: %s: %w", filename, err)
+	}
+
+	if err := model.Validate(data); err != nil {
+		return nil, err
+	}
+
+	return model.NewFromDataFile(data), nil
+}
+
+// Read reads the database into a data file. The data file is not validated.
+func Read(db *sql.DB) (*model.DataFile, error) {
+	data := &model.DataFile{}
+
+	var generated string
+	if err := db.QueryRow(`SELECT value FROM meta WHERE key = ?`, generatedKey).Scan(&generated); err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	if generated != "" {
+		t, err := time.Parse(time.RFC3339Nano, generated)
+		if err != nil {
+			return nil, err
+		}
+		data.Generated = t
+	}
+
+	var nextID string
+	if err := db.QueryRow(`SELECT value FROM meta WHERE key = ?`, nextIDKey).Scan(&nextID); err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	if nextID != "" {
+		n, err := strconv.Atoi(nextID)
+		if err != nil {
+			return nil, err
+		}
+		data.NextID = n
+	}
+
+	teams := make(map[int64]*model.Team)
+	if err := query(db, `SELECT position, id, name, league, division FROM teams ORDER BY position`, func(rows *sql.Rows) error {
+		var position int64
+		var id sql.NullInt64
+		team := &model.Team{}
+		if err := rows.Scan(&position, &id, &team.Name, &team.League, &team.Division); err != nil {
+			return err
+		}
+
+		team.ID = int(id.Int64)
+		teams[position] = team
+		data.Teams = append(data.Teams, team)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := query(db, `SELECT team, alias FROM aliases ORDER BY team, position`, func(rows *sql.Rows) error {
+		var position int64
+		var alias string
+		if err := rows.Scan(&position, &alias); err != nil {
+			return err
+		}
+
+		team, ok := teams[position]
+		if !ok {
+			return fmt.Errorf("alias %q belongs to unknown team %d", alias, position)
+		}
+		team.Aliases = append(team.Aliases, alias)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := query(db, `SELECT team, name FROM former_names ORDER BY team, position`, func(rows *sql.Rows) error {
+		var position int64
+		var name string
+		if err := rows.Scan(&position, &name); err != nil {
+			return err
+		}
+
+		team, ok := teams[position]
+		if !ok {
+			return fmt.Errorf("former name %q belongs to unknown team %d", name, position)
+		}
+		team.FormerNames = append(team.FormerNames, name)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := query(db, `SELECT team, name, city, from_year, to_year, eras FROM predecessors ORDER BY team, position`, func(rows *sql.Rows) error {
+		var position int64
+		var eras string
+		identity := &model.Identity{}
+		if err := rows.Scan(&position, &identity.Name, &identity.City, &identity.From, &identity.To, &eras); err != nil {
+			return err
+		}
+
+		if err := json.Unmarshal([]byte(eras), &identity.Eras); err != nil {
+			return fmt.Errorf("eras of predecessor %q: %w", identity.Name, err)
+		}
+
+		team, ok := teams[position]
+		if !ok {
+			return fmt.Errorf("predecessor %q belongs to unknown team %d", identity.Name, position)
+		}
+		team.Predecessors = append(team.Predecessors, identity)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	type eraKey struct{ team, era int64 }
+	eras := make(map[eraKey]*model.Era)
+	if err := query(db, `SELECT team, position, year, start_date, end_date, label FROM eras ORDER BY team, position`, func(rows *sql.Rows) error {
+		var key eraKey
+		var start, end sql.NullString
+		era := &model.Era{}
+		if err := rows.Scan(&key.team, &key.era, &era.Year, &start, &end, &era.Label); err != nil {
+			return err
+		}
+
+		if start.Valid {
+			date, err := model.ParseDate(start.String)
+			if err != nil {
+				return err
+			}
+			era.Start = date
+		}
+
+		if end.Valid {
+			date, err := model.ParseDate(end.String)
+			if err != nil {
+				return err
+			}
+			era.End = &date
+		}
+
+		team, ok := teams[key.team]
+		if !ok {
+			return fmt.Errorf("era %d belongs to unknown team %d", era.Year, key.team)
+		}
+		team.Eras = append(team.Eras, era)
+		eras[key] = era
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := query(db, `SELECT team, era, name, hex, pantone, cmyk_c, cmyk_m, cmyk_y, cmyk_k, rgb_r, rgb_g, rgb_b, source, role
+			FROM colors ORDER BY team, era, position`, func(rows *sql.Rows) error {
+		var key eraKey
+		var c, m, y, k sql.NullFloat64
+		var r, g, b sql.NullInt64
+		color := &model.Color{}
+		if err := rows.Scan(&key.team, &key.era, &color.Name, &color.Hex, &color.Pantone, &c, &m, &y, &k, &r, &g, &b, &color.Source, &color.Role); err != nil {
+			return err
+		}
+
+		if c.Valid {
+			color.CMYK = &model.CMYK{C: c.Float64, M: m.Float64, Y: y.Float64, K: k.Float64}
+		}
+		if r.Valid {
+			color.RGB = &model.RGB{R: uint8(r.Int64), G: uint8(g.Int64), B: uint8(b.Int64)}
+		}
+
+		era, ok := eras[key]
+		if !ok {
+			return fmt.Errorf("color %q belongs to unknown era %d of team %d", color.Name, key.era, key.team)
+		}
+		era.Colors = append(era.Colors, color)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func query(db *sql.DB, q string, scan func(rows *sql.Rows) error) error {
+	rows, err := db.Query(q)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// Update applies fn to the teams in the database and writes them back if the
+// result is still valid
+func Update(filename string, fn func(data *model.DataFile) error) (*model.Model, error) {
+	db, err := sql.Open("sqlite3", "file:"+url.PathEscape(filename)+"?mode=rw&_foreign_keys=1")
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	data, err := Read(db)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: %s: %w", filename, err)
+	}
+
+	if err := fn(data); err != nil {
+		return nil, err
+	}
+
+	if err := model.Validate(data); err != nil {
+		return nil, err
+	}
+
+	data.Generated = time.Now().UTC().Truncate(time.Millisecond)
+	if err := Write(db, data); err != nil {
+		return nil, fmt.Errorf("sqlite: %s: %w", filename, err)
+	}
+
+	return model.NewFromDataFile(data), nil
+}
+
+// Save writes the data file to the database, creating it if needed and
+// replacing any teams already in it
+func Save(filename string, data *model.DataFile) error {
+	db, err := sql.Open("sqlite3", "file:"+url.PathEscape(filename)+"?_foreign_keys=1")
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := Write(db, data); err != nil {
+		return fmt.Errorf("sqlite: %s: %w", filename, err)
+	}
+
+	return nil
+}
+
+// Write replaces the contents of the database with the data file in a
+// single transaction
+func Write(db *sql.DB, data *model.DataFile) error {
+	if _, err := db.Exec(schema); err != nil {
+		return err
+	}
+
+	if err := migrate(db); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := write(tx, data); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// migrate adds any columns missing from a database created by an older
+// version
+func migrate(db *sql.DB) error {
+	if err := addColumns(db, "eras", addedEraColumns); err != nil {
+		return err
+	}
+
+	return addColumns(db, "colors", addedColorColumns)
+}
+
+func addColumns(db *sql.DB, table string, added []struct{ name, definition string }) error {
+	columns := make(map[string]bool)
+	if err := query(db, `PRAGMA table_info(`+table+`)`, func(rows *sql.Rows) error {
+		var cid, notNull, pk int
+		var name, typ string
+		var def sql.NullString
+		if err := rows.Scan(&cid, &name, &typ, &notNull, &def, &pk); err != nil {
+			return err
+		}
+		columns[name] = true
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for _, column := range added {
+		if !columns[column.name] {
+			if _, err := db.Exec(`ALTER TABLE ` + table + ` ADD COLUMN ` + column.name + ` ` + column.definition); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func write(tx *sql.Tx, data *model.DataFile) error {
+	data.Migrate()
+	for _, table := range []string{"colors", "eras", "predecessors", "former_names", "aliases", "teams", "meta"} {
+		if _, err := tx.Exec(`DELETE FROM ` + table); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(`INSERT INTO meta (key, value) VALUES (?, ?)`, generatedKey, data.Generated.Format(time.RFC3339Nano)); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`INSERT INTO meta (key, value) VALUES (?, ?)`, nextIDKey, strconv.Itoa(data.NextID)); err != nil {
+		return err
+	}
+
+	for i, team := range data.Teams {
+		id := sql.NullInt64{Int64: int64(team.ID), Valid: team.ID != 0}
+		if _, err := tx.Exec(`INSERT INTO teams (position, id, name, league, division) VALUES (?, ?, ?, ?, ?)`,
+			i, id, team.Name, team.League, team.Division); err != nil {
+			return err
+		}
+
+		for j, alias := range team.Aliases {
+			if _, err := tx.Exec(`INSERT INTO aliases (team, position, alias) VALUES (?, ?, ?)`, i, j, alias); err != nil {
+				return err
+			}
+		}
+
+		for j, name := range team.FormerNames {
+			if _, err := tx.Exec(`INSERT INTO former_names (team, position, name) VALUES (?, ?, ?)`, i, j, name); err != nil {
+				return err
+			}
+		}
+
+		for j, identity := range team.Predecessors {
+			eras, err := json.Marshal(identity.Eras)
+			if err != nil {
+				return err
+			}
+
+			if _, err := tx.Exec(`INSERT INTO predecessors (team, position, name, city, from_year, to_year, eras) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+				i, j, identity.Name, identity.City, identity.From, identity.To, string(eras)); err != nil {
+				return err
+			}
+		}
+
+		for j, era := range team.Eras {
+			var end sql.NullString
+			if era.End != nil {
+				end = sql.NullString{String: era.End.String(), Valid: true}
+			}
+
+			if _, err := tx.Exec(`INSERT INTO eras (team, position, year, start_date, end_date, label) VALUES (?, ?, ?, ?, ?, ?)`,
+				i, j, era.Year, era.StartDate().String(), end, era.Label); err != nil {
+				return err
+			}
+
+			for k, color := range era.Colors {
+				var c, m, y, bk sql.NullFloat64
+				if cmyk := color.CMYK; cmyk != nil {
+					c, m, y, bk = nullFloat(cmyk.C), nullFloat(cmyk.M), nullFloat(cmyk.Y), nullFloat(cmyk.K)
+				}
+
+				var r, g, b sql.NullInt64
+				if rgb := color.RGB; rgb != nil {
+					r, g, b = nullInt(rgb.R), nullInt(rgb.G), nullInt(rgb.B)
+				}
+
+				if _, err := tx.Exec(`INSERT INTO colors (team, era, position, name, hex, pantone, cmyk_c, cmyk_m, cmyk_y, cmyk_k, rgb_r, rgb_g, rgb_b, source, role)
+						VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+					i, j, k, color.Name, color.Hex, color.Pantone, c, m, y, bk, r, g, b, color.Source, color.Role); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func nullFloat(v float64) sql.NullFloat64 {
+	return sql.NullFloat64{Float64: v, Valid: true}
+}
+
+func nullInt(v uint8) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(v), Valid: true}
+}
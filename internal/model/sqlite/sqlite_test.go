@@ -0,0 +1,224 @@
+//go:build sqlite
+// +build sqlite
+
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlite
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	"github.com/weters/teamhex/internal/model"
+)
+
+func TestSaveAndOpen(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	dir, err := ioutil.TempDir("", "teamhex")
+	g.Expect(err).Should(gomega.BeNil())
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "teamhex.db")
+
+	data, err := model.Load("../../../configs/teamhex.json")
+	g.Expect(err).Should(gomega.BeNil())
+
+	// saving twice replaces the teams rather than adding to them
+	g.Expect(Save(filename, data)).Should(gomega.Succeed())
+	g.Expect(Save(filename, data)).Should(gomega.Succeed())
+
+	db, err := sql.Open("sqlite3", filename)
+	g.Expect(err).Should(gomega.BeNil())
+	defer db.Close()
+
+	read, err := Read(db)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(read.Generated.Equal(data.Generated)).Should(gomega.BeTrue())
+	read.Generated = data.Generated
+	g.Expect(read).Should(gomega.Equal(data))
+
+	s, err := model.Open(Backend, filename)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(s.AllTeams()).Should(gomega.HaveLen(len(data.Teams)))
+
+	results, err := s.SearchRanked("niners", "nfl")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(results[0].Name).Should(gomega.Equal("San Francisco 49ers"))
+
+	lineage, err := s.Lineage("nfl", "oakland-raiders")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(lineage.Team).Should(gomega.Equal("Las Vegas Raiders"))
+	g.Expect(lineage.Identities[0].Eras[0].Colors).Should(gomega.HaveLen(3))
+}
+
+func TestOpenInvalid(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	_, err := Open(filepath.Join(os.TempDir(), "teamhex-does-not-exist.db"))
+	g.Expect(err).ShouldNot(gomega.BeNil())
+
+	dir, err := ioutil.TempDir("", "teamhex")
+	g.Expect(err).Should(gomega.BeNil())
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "teamhex.db")
+
+	g.Expect(Save(filename, &model.DataFile{})).Should(gomega.Succeed())
+	_, err = Open(filename)
+	g.Expect(err).Should(gomega.BeAssignableToTypeOf(model.ValidationErrors{}))
+}
+
+func TestUpdate(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	dir, err := ioutil.TempDir("", "teamhex")
+	g.Expect(err).Should(gomega.BeNil())
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "teamhex.db")
+
+	data, err := model.Load("../../../configs/teamhex.json")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(Save(filename, data)).Should(gomega.Succeed())
+
+	s, err := model.Update(Backend, filename, func(data *model.DataFile) error {
+		return data.RenameTeam("nfl", "arizona cardinals", "Phoenix Cardinals")
+	})
+	g.Expect(err).Should(gomega.BeNil())
+	_, err = s.TeamByLeagueAndName("nfl", "phoenix cardinals")
+	g.Expect(err).Should(gomega.BeNil())
+	team, err := s.TeamByLeagueAndName("nfl", "arizona-cardinals")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(team.Name).Should(gomega.Equal("Phoenix Cardinals"))
+	g.Expect(team.FormerNames).Should(gomega.Equal([]string{"Arizona Cardinals"}))
+
+	_, err = model.Update(Backend, filename, func(data *model.DataFile) error {
+		data.Teams[0].Eras = nil
+		return nil
+	})
+	g.Expect(err).Should(gomega.BeAssignableToTypeOf(model.ValidationErrors{}))
+
+	m, err := Open(filename)
+	g.Expect(err).Should(gomega.BeNil())
+	_, err = m.TeamByLeagueAndName("nfl", "phoenix cardinals")
+	g.Expect(err).Should(gomega.BeNil())
+
+	// a removed team's ID is not given to the next team
+	var id int
+	_, err = model.Update(Backend, filename, func(data *model.DataFile) error {
+		id = data.NextID
+		return data.AddTeam(&model.Team{Name: "Buffalo Bandits", League: "NLL", Eras: []*model.Era{{Year: 1992, Colors: []*model.Color{{Name: "Orange", Hex: "#F47A38"}}}}})
+	})
+	g.Expect(err).Should(gomega.BeNil())
+
+	_, err = model.Update(Backend, filename, func(data *model.DataFile) error {
+		return data.RemoveTeam("nll", "buffalo bandits")
+	})
+	g.Expect(err).Should(gomega.BeNil())
+
+	s, err = model.Update(Backend, filename, func(data *model.DataFile) error {
+		return data.AddTeam(&model.Team{Name: "Buffalo Bandits", League: "NLL", Eras: []*model.Era{{Year: 1992, Colors: []*model.Color{{Name: "Orange", Hex: "#F47A38"}}}}})
+	})
+	g.Expect(err).Should(gomega.BeNil())
+	team, err = s.TeamByLeagueAndName("nll", "buffalo bandits")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(team.ID).Should(gomega.Equal(id + 1))
+}
+
+func TestColorReferences(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	dir, err := ioutil.TempDir("", "teamhex")
+	g.Expect(err).Should(gomega.BeNil())
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "teamhex.db")
+
+	// a database from before colors had published values
+	db, err := sql.Open("sqlite3", filename)
+	g.Expect(err).Should(gomega.BeNil())
+	defer db.Close()
+	_, err = db.Exec(`CREATE TABLE colors (
+		team     INTEGER NOT NULL,
+		era      INTEGER NOT NULL,
+		position INTEGER NOT NULL,
+		name     TEXT NOT NULL,
+		hex      TEXT NOT NULL,
+		PRIMARY KEY (team, era, position)
+	)`)
+	g.Expect(err).Should(gomega.BeNil())
+
+	data, err := model.Load("../../../configs/teamhex.json")
+	g.Expect(err).Should(gomega.BeNil())
+	data.Teams[0].Eras[0].Colors[0].Pantone = "PMS 201 C"
+	data.Teams[0].Eras[0].Colors[0].CMYK = &model.CMYK{C: 0, M: 100, Y: 65, K: 34}
+	data.Teams[0].Eras[0].Colors[0].RGB = &model.RGB{R: 151, G: 35, B: 63}
+	data.Teams[0].Eras[0].Colors[0].Source = "https://example.com/brand"
+	data.Teams[0].Eras[0].Colors[0].Role = model.RolePrimary
+	g.Expect(Write(db, data)).Should(gomega.Succeed())
+
+	read, err := Read(db)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(read.Teams[0].Eras[0].Colors[0]).Should(gomega.Equal(data.Teams[0].Eras[0].Colors[0]))
+	g.Expect(read.Teams[0].Eras[0].Colors[1].CMYK).Should(gomega.BeNil())
+	g.Expect(read.Teams[0].Eras[0].Colors[1].RGB).Should(gomega.BeNil())
+}
+
+func TestEraDates(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	dir, err := ioutil.TempDir("", "teamhex")
+	g.Expect(err).Should(gomega.BeNil())
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "teamhex.db")
+
+	// a database from before eras had dates and labels
+	db, err := sql.Open("sqlite3", filename)
+	g.Expect(err).Should(gomega.BeNil())
+	defer db.Close()
+	_, err = db.Exec(`CREATE TABLE eras (
+		team     INTEGER NOT NULL,
+		position INTEGER NOT NULL,
+		year     INTEGER NOT NULL,
+		PRIMARY KEY (team, position)
+	)`)
+	g.Expect(err).Should(gomega.BeNil())
+
+	data, err := model.Load("../../../configs/teamhex.json")
+	g.Expect(err).Should(gomega.BeNil())
+	end := model.NewDate(2020, time.June, 30)
+	data.Teams[0].Eras[0].End = &end
+	data.Teams[0].Eras[0].Label = "alternate"
+	g.Expect(Write(db, data)).Should(gomega.Succeed())
+
+	read, err := Read(db)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(read.Teams[0].Eras[0]).Should(gomega.Equal(data.Teams[0].Eras[0]))
+	g.Expect(read.Teams[0].Eras[1].End).Should(gomega.BeNil())
+
+	// eras written before start dates were added are migrated when opened
+	_, err = db.Exec(`UPDATE eras SET start_date = NULL`)
+	g.Expect(err).Should(gomega.BeNil())
+
+	m, err := Open(filename)
+	g.Expect(err).Should(gomega.BeNil())
+	team, err := m.TeamByID(data.Teams[1].ID)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(team.Eras[0].Start).Should(gomega.Equal(model.NewDate(team.Eras[0].Year, time.January, 1)))
+}
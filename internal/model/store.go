@@ -0,0 +1,281 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Store is a read-only source of leagues, divisions and teams. *Model is the
+// in-memory implementation that every backend loads its data into.
+type Store interface {
+	// GenerationDate is when the data was generated
+	GenerationDate() time.Time
+	// LastEraChange returns the latest day, on or before day, that a team's
+	// era started or ended. Current eras don't change until the next one.
+	LastEraChange(day Date) Date
+	// Leagues returns every league, sorted by name
+	Leagues() []*LeagueRecord
+	// AllTeams returns every team, sorted by name
+	AllTeams() Teams
+	// TeamsByLeague returns the teams in a league, sorted by name
+	TeamsByLeague(league string) (Teams, error)
+	// TeamByLeagueAndName returns a single team by its name, slug, a
+	// former name or the name of a predecessor
+	TeamByLeagueAndName(league, name string) (*Team, error)
+	// TeamByID returns a single team by its ID
+	TeamByID(id int) (*Team, error)
+	// Divisions returns the divisions in a league, sorted by name
+	Divisions(league string) ([]*DivisionRecord, error)
+	// TeamsByDivision returns the teams in a division, given by its slug or
+	// name, sorted by name
+	TeamsByDivision(league, division string) (Teams, error)
+	// ColorsAt returns a team's colors in effect for the year
+	ColorsAt(league, name string, year int) ([]*Color, error)
+	// Search returns the teams matching a query, best match first
+	Search(query string) Teams
+	// SearchRanked returns the teams matching a query with their scores,
+	// optionally limited to a league
+	SearchRanked(query, league string) ([]*SearchResult, error)
+	// Autocomplete returns leagues, divisions and teams starting with a query
+	Autocomplete(query string, limit int) []*Suggestion
+	// NearestColors returns the team colors closest to a hex color
+	NearestColors(hex, league string, limit int) ([]*ColorMatch, error)
+	// Accessibility returns the contrast report for a team's colors
+	Accessibility(league, name string) (*AccessibilityReport, error)
+	// Lineage returns every identity of a team's franchise, oldest first
+	Lineage(league, name string) (*Lineage, error)
+	// Matchup returns the colors that tell two teams apart
+	Matchup(homeLeague, homeName, awayLeague, awayName string) (*Matchup, error)
+}
+
+var _ Store = (*Model)(nil)
+
+// OpenFunc opens a store from a data source, such as a file name
+type OpenFunc func(source string) (Store, error)
+
+// UpdateFunc applies fn to the data in a source, validates the result and
+// saves it, then returns a store of the updated data. Nothing is saved if fn
+// or validation fails.
+type UpdateFunc func(source string, fn func(data *DataFile) error) (Store, error)
+
+// Backend is a way of storing teams
+type Backend struct {
+	Open OpenFunc
+	// Update is nil if the backend is read-only
+	Update UpdateFunc
+}
+
+// BackendJSON is the name of the JSON file backend
+const BackendJSON = "json"
+
+// ErrReadOnly represents an error when a backend cannot be updated
+var ErrReadOnly = errors.New("model: backend is read-only")
+
+var (
+	backendsMu sync.RWMutex
+	backends   = map[string]Backend{
+		BackendJSON: {
+			Open: func(source string) (Store, error) {
+				m, err := New(source)
+				if err != nil {
+					return nil, err
+				}
+
+				return m, nil
+			},
+			Update: func(source string, fn func(data *DataFile) error) (Store, error) {
+				data, err := Load(source)
+				if err != nil {
+					return nil, err
+				}
+
+				if err := fn(data); err != nil {
+					return nil, err
+				}
+
+				if err := Validate(data); err != nil {
+					return nil, err
+				}
+
+				data.Generated = now().UTC().Truncate(time.Millisecond)
+				if err := data.Save(source); err != nil {
+					return nil, err
+				}
+
+				return NewFromDataFile(data), nil
+			},
+		},
+	}
+
+	// updateMu makes every update a single read, modify and write
+	updateMu sync.Mutex
+
+	// now is replaced in tests
+	now = time.Now
+)
+
+// RegisterBackend makes a storage backend available to Open and Update by
+// name. It is meant to be called from the init function of the backend's
+// package.
+func RegisterBackend(name string, backend Backend) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+
+	if _, ok := backends[name]; ok {
+		panic(fmt.Sprintf("model: backend %q registered twice", name))
+	}
+	backends[name] = backend
+}
+
+// Backends returns the names of the registered backends, sorted
+func Backends() []string {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func lookupBackend(name string) (Backend, error) {
+	backendsMu.RLock()
+	backend, ok := backends[name]
+	backendsMu.RUnlock()
+
+	if !ok {
+		return Backend{}, fmt.Errorf("model: unknown backend %q (have %v)", name, Backends())
+	}
+
+	return backend, nil
+}
+
+// Open opens a store with the named backend
+func Open(backend, source string) (Store, error) {
+	b, err := lookupBackend(backend)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.Open(source)
+}
+
+// Update changes the data in a source with the named backend. See UpdateFunc.
+// Updates within the process are made one at a time. ErrReadOnly is returned
+// if the backend cannot be updated.
+func Update(backend, source string, fn func(data *DataFile) error) (Store, error) {
+	b, err := lookupBackend(backend)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.Update == nil {
+		return nil, ErrReadOnly
+	}
+
+	updateMu.Lock()
+	defer updateMu.Unlock()
+	return b.Update(source, fn)
+}
+
+// Updater changes the data behind a store
+type Updater interface {
+	// Update applies fn to the data, validates and saves the result and
+	// returns a store of it. The edit is recorded with every change.
+	Update(edit Edit, fn func(data *DataFile) error) (Store, error)
+}
+
+type sourceUpdater struct {
+	backend string
+	source  string
+	history History
+	// mu keeps the history in the same order as the changes
+	mu sync.Mutex
+}
+
+// NewUpdater returns an Updater for a source with the named backend. If
+// history is not nil, every change to a team is appended to it.
+// ErrReadOnly is returned if the backend cannot be updated.
+func NewUpdater(backend, source string, history History) (Updater, error) {
+	b, err := lookupBackend(backend)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.Update == nil {
+		return nil, ErrReadOnly
+	}
+
+	return &sourceUpdater{backend: backend, source: source, history: history}, nil
+}
+
+func (u *sourceUpdater) Update(edit Edit, fn func(data *DataFile) error) (Store, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	var changes []*Change
+	s, err := Update(u.backend, u.source, func(data *DataFile) error {
+		before := newTeamsSnapshot(data)
+		if err := fn(data); err != nil {
+			return err
+		}
+
+		changes = before.changes(data, edit, time.Time{})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if u.history != nil {
+		for _, change := range changes {
+			change.Time = s.GenerationDate()
+		}
+
+		if err := u.history.Append(changes...); err != nil {
+			return nil, fmt.Errorf("model: changes were saved but not added to the history: %w", err)
+		}
+	}
+
+	return s, nil
+}
+
+// NewMemoryStore returns a store of the teams, which is useful for tests.
+// Teams without an ID are numbered after the highest one, then the teams
+// are validated as if they were loaded from a data file.
+func NewMemoryStore(generated time.Time, teams ...*Team) (*Model, error) {
+	data := &DataFile{Generated: generated, Teams: teams}
+	for _, team := range teams {
+		if team != nil && team.ID == 0 {
+			team.ID = data.newID()
+		}
+	}
+
+	if err := Validate(data); err != nil {
+		return nil, err
+	}
+
+	return NewFromDataFile(data), nil
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+)
+
+func TestOpen(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	s, err := Open(BackendJSON, testFile)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(s.AllTeams()).Should(gomega.HaveLen(4))
+
+	s, err = Open(BackendJSON, "badfile.json")
+	g.Expect(s).Should(gomega.BeNil())
+	g.Expect(err).ShouldNot(gomega.BeNil())
+
+	_, err = Open("bad", testFile)
+	g.Expect(err).Should(gomega.MatchError(gomega.HavePrefix(`model: unknown backend "bad"`)))
+
+	g.Expect(Backends()).Should(gomega.ContainElement(BackendJSON))
+	g.Expect(func() { RegisterBackend(BackendJSON, Backend{}) }).Should(gomega.Panic())
+}
+
+func TestNewMemoryStore(t *testing.T) {
+	g := gomega.NewWithT(t)
+	generated := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	s, err := NewMemoryStore(generated, &Team{
+		Name:   "Buffalo Bandits",
+		League: "NLL",
+		Eras:   []*Era{{Year: 1992, Colors: []*Color{{Name: "Orange", Hex: "#F47A38"}}}},
+	})
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(s.GenerationDate()).Should(gomega.Equal(generated))
+
+	team, err := s.TeamByLeagueAndName("nll", "buffalo bandits")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(team.Link).Should(gomega.Equal("/leagues/nll/buffalo-bandits"))
+
+	_, err = NewMemoryStore(generated, &Team{Name: "Buffalo Bandits", League: "NLL"})
+	g.Expect(err).Should(gomega.BeAssignableToTypeOf(ValidationErrors{}))
+}
+
+func TestUpdate(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	dir, err := ioutil.TempDir("", "teamhex")
+	g.Expect(err).Should(gomega.BeNil())
+	defer os.RemoveAll(dir)
+
+	data, _ := Load(testFile)
+	filename := filepath.Join(dir, "teamhex.json")
+	g.Expect(data.Save(filename)).Should(gomega.Succeed())
+
+	defer func(original func() time.Time) { now = original }(now)
+	now = func() time.Time { return time.Date(2020, 3, 1, 12, 0, 0, 123456789, time.FixedZone("EST", -5*3600)) }
+
+	u, err := NewUpdater(BackendJSON, filename, nil)
+	g.Expect(err).Should(gomega.BeNil())
+
+	s, err := u.Update(Edit{}, func(data *DataFile) error {
+		return data.RenameTeam("nfl", "buffalo bills", "Buffalo Bisons")
+	})
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(s.GenerationDate()).Should(gomega.Equal(time.Date(2020, 3, 1, 17, 0, 0, 123000000, time.UTC)))
+	_, err = s.TeamByLeagueAndName("nfl", "buffalo bisons")
+	g.Expect(err).Should(gomega.BeNil())
+
+	saved, _ := Load(filename)
+	g.Expect(saved.Teams[1].Name).Should(gomega.Equal("Buffalo Bisons"))
+
+	// neither a failed change nor an invalid result is saved
+	_, err = u.Update(Edit{}, func(data *DataFile) error {
+		data.Teams[1].Name = "Renamed"
+		return errors.New("failed")
+	})
+	g.Expect(err).Should(gomega.MatchError("failed"))
+
+	_, err = u.Update(Edit{}, func(data *DataFile) error {
+		data.Teams[1].Eras = nil
+		return nil
+	})
+	g.Expect(err).Should(gomega.BeAssignableToTypeOf(ValidationErrors{}))
+
+	unchanged, _ := Load(filename)
+	g.Expect(unchanged).Should(gomega.Equal(saved))
+
+	RegisterBackend("read-only", Backend{Open: func(string) (Store, error) { return nil, nil }})
+	defer delete(backends, "read-only")
+	_, err = NewUpdater("read-only", filename, nil)
+	g.Expect(err).Should(gomega.MatchError(ErrReadOnly))
+	_, err = Update("read-only", filename, nil)
+	g.Expect(err).Should(gomega.MatchError(ErrReadOnly))
+}
@@ -16,28 +16,315 @@ limitations under the License.
 
 package model
 
-import "strings"
+import (
+	"strconv"
+	"strings"
+	"time"
+)
 
 // Team represents an individual team
 type Team struct {
-	ID       int    `json:"id,omitempty"`
-	Name     string `json:"name"`
+	// ID identifies the team for good. It is unique across every league and
+	// never changes, even when the team is renamed.
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	// Slug is the team's name as it appears in its canonical URL, e.g. arizona-cardinals
+	Slug     string `json:"slug"`
 	Eras     []*Era `json:"eras"`
 	League   string `json:"league"`
 	Division string `json:"division,omitempty"`
-	Link     string `json:"_link"`
+	// Aliases are other names the team is searched by, such as abbreviations and nicknames
+	Aliases []string `json:"aliases,omitempty"`
+	// FormerNames are names the team was known by before, oldest first. Links
+	// using them are redirected to the team.
+	FormerNames []string `json:"formerNames,omitempty"`
+	// Predecessors are the identities the franchise had before this one,
+	// oldest first. Links using their names are redirected to the team.
+	Predecessors []*Identity `json:"predecessors,omitempty"`
+	Link         string      `json:"_link"`
 }
 
+// PrimaryLabel is the label of the colors a team usually wears. Eras without
+// a label are primary.
+const PrimaryLabel = "primary"
+
 // Era represents a particular period in time
 type Era struct {
-	Year   int      `json:"year"`
+	// Year is the year the era started. Along with the label, it identifies
+	// the era in URLs.
+	Year int `json:"year"`
+	// Start is the first day of the era. Eras from data files written before
+	// start dates were added start on January 1 of their year.
+	Start Date `json:"start"`
+	// End is the last day of the era. An era without one has not ended,
+	// though a later era with the same label takes its place.
+	End *Date `json:"end,omitempty"`
+	// Label says how the colors are used, such as primary, alternate,
+	// throwback or city edition
+	Label  string   `json:"label,omitempty"`
 	Colors []*Color `json:"colors"`
 }
 
+// StartDate returns the first day of the era, which is January 1 of its year
+// if it has no start date
+func (e *Era) StartDate() Date {
+	if e.Start.IsZero() {
+		return NewDate(e.Year, time.January, 1)
+	}
+
+	return e.Start
+}
+
+// HasLabel returns whether the era has the label, ignoring case. An empty
+// label matches primary eras.
+func (e *Era) HasLabel(label string) bool {
+	if label == "" || strings.EqualFold(label, PrimaryLabel) {
+		return e.Label == "" || strings.EqualFold(e.Label, PrimaryLabel)
+	}
+
+	return strings.EqualFold(e.Label, label)
+}
+
+// activeDuring returns whether any day of the era is between from and to
+func (e *Era) activeDuring(from, to Date) bool {
+	return !e.StartDate().After(to.Time) && (e.End == nil || !e.End.Before(from.Time))
+}
+
 // Color represents an individual color in an era
 type Color struct {
 	Name string `json:"name"`
 	Hex  string `json:"hex"`
+	// Pantone is the Pantone Matching System reference the team publishes, e.g. PMS 201 C
+	Pantone string `json:"pantone,omitempty"`
+	// CMYK is the print values the team publishes, which may differ from a conversion of Hex
+	CMYK *CMYK `json:"cmyk,omitempty"`
+	// RGB is the screen values the team publishes, which may differ from Hex
+	RGB *RGB `json:"rgb,omitempty"`
+	// Source is a URL or citation for where the values were published
+	Source string `json:"source,omitempty"`
+	// Role says how the team uses the color, from Roles. Colors without one
+	// have no particular use.
+	Role string `json:"role,omitempty"`
+	// Formats is the color in other formats. It is only set when requested.
+	Formats *ColorFormats `json:"formats,omitempty"`
+}
+
+// Color roles
+const (
+	RolePrimary    = "primary"
+	RoleSecondary  = "secondary"
+	RoleAccent     = "accent"
+	RoleText       = "text"
+	RoleBackground = "background"
+)
+
+// Roles are the roles a color can have. An era has at most one color with
+// each role, except for accents.
+var Roles = []string{RolePrimary, RoleSecondary, RoleAccent, RoleText, RoleBackground}
+
+// IsRole returns whether role is one of Roles
+func IsRole(role string) bool {
+	for _, r := range Roles {
+		if r == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ColorByRole returns the era's first color with the role. ErrColorNotFound
+// is returned if no color has it; colors are never assumed to have a role
+// because of their order.
+func (e *Era) ColorByRole(role string) (*Color, error) {
+	for _, color := range e.Colors {
+		if color.Role == role {
+			return color, nil
+		}
+	}
+
+	return nil, ErrColorNotFound
+}
+
+// ColorsByRole returns the era's colors with the role, in order
+func (e *Era) ColorsByRole(role string) []*Color {
+	colors := make([]*Color, 0)
+	for _, color := range e.Colors {
+		if color.Role == role {
+			colors = append(colors, color)
+		}
+	}
+
+	return colors
+}
+
+// ColorByRole returns the color with the role in the team's current era. See
+// Era.ColorByRole.
+func (t *Team) ColorByRole(role string) (*Color, error) {
+	era := t.CurrentEra()
+	if era == nil {
+		return nil, ErrColorNotFound
+	}
+
+	return era.ColorByRole(role)
+}
+
+// EraAt returns the primary era in effect for the year, which is the latest
+// started of those in effect at any time that year. ErrEraNotFound is
+// returned if there is none, such as when the year is before the first known
+// era.
+func (t *Team) EraAt(year int) (*Era, error) {
+	return eraAt(t.Eras, year)
+}
+
+// EraIn returns the era with the label in effect for the year. See EraAt.
+func (t *Team) EraIn(year int, label string) (*Era, error) {
+	return eraDuring(t.Eras, label, NewDate(year, time.January, 1), NewDate(year, time.December, 31))
+}
+
+// EraOn returns the era with the label in effect on the day
+func (t *Team) EraOn(date Date, label string) (*Era, error) {
+	return eraDuring(t.Eras, label, date, date)
+}
+
+// EraByYear returns the era with the label that started in the year. If
+// colors changed more than once that year, ErrEraAmbiguous is returned and
+// the era must be found by the day it started with EraByStart.
+func (t *Team) EraByYear(year int, label string) (*Era, error) {
+	var found *Era
+	for _, era := range t.Eras {
+		if era.Year == year && era.HasLabel(label) {
+			if found != nil {
+				return nil, ErrEraAmbiguous
+			}
+			found = era
+		}
+	}
+
+	if found == nil {
+		return nil, ErrEraNotFound
+	}
+
+	return found, nil
+}
+
+// EraByStart returns the era with the label that started on the day
+func (t *Team) EraByStart(start Date, label string) (*Era, error) {
+	for _, era := range t.Eras {
+		if era.StartDate().Equal(start.Time) && era.HasLabel(label) {
+			return era, nil
+		}
+	}
+
+	return nil, ErrEraNotFound
+}
+
+// Era returns the era the key identifies. See EraByYear and EraByStart.
+func (t *Team) Era(key EraKey) (*Era, error) {
+	if !key.Start.IsZero() {
+		return t.EraByStart(key.Start, key.Label)
+	}
+
+	return t.EraByYear(key.Year, key.Label)
+}
+
+// EraKey identifies one of a team's eras by its label and when it started.
+// The year is enough unless colors changed more than once that year, in
+// which case the day it started is needed too.
+type EraKey struct {
+	// Year is the year the era started
+	Year int
+	// Start is the day the era started, or zero to only match the year
+	Start Date
+	// Label is the era's label, or empty for the primary era
+	Label string
+}
+
+// ParseEraKey returns the key of the era with the label that started in the
+// year or on the day, e.g. 2011 or 2011-09-11
+func ParseEraKey(start, label string) (EraKey, error) {
+	if year, err := strconv.Atoi(start); err == nil {
+		return EraKey{Year: year, Label: label}, nil
+	}
+
+	date, err := ParseDate(start)
+	if err != nil {
+		return EraKey{}, err
+	}
+
+	return EraKey{Year: date.Year(), Start: date, Label: label}, nil
+}
+
+// String returns the day the era started if it is known, otherwise the year
+func (k EraKey) String() string {
+	if !k.Start.IsZero() {
+		return k.Start.String()
+	}
+
+	return strconv.Itoa(k.Year)
+}
+
+// ErasWithLabel returns the team's eras with the label, newest first
+func (t *Team) ErasWithLabel(label string) []*Era {
+	eras := make([]*Era, 0, len(t.Eras))
+	for _, era := range t.Eras {
+		if era.HasLabel(label) {
+			eras = append(eras, era)
+		}
+	}
+
+	return eras
+}
+
+// CurrentEra returns the primary era in effect today. A team whose primary
+// eras have all ended, or that has only labelled eras, returns its newest.
+func (t *Team) CurrentEra() *Era {
+	return currentEra(t.Eras)
+}
+
+func currentEra(eras []*Era) *Era {
+	today := Today()
+	if era, err := eraDuring(eras, "", today, today); err == nil {
+		return era
+	}
+
+	// eras are ordered newest first
+	for _, era := range eras {
+		if era.HasLabel(PrimaryLabel) {
+			return era
+		}
+	}
+
+	if len(eras) > 0 {
+		return eras[0]
+	}
+
+	return nil
+}
+
+func eraAt(eras []*Era, year int) (*Era, error) {
+	return eraDuring(eras, "", NewDate(year, time.January, 1), NewDate(year, time.December, 31))
+}
+
+// eraDuring returns the latest started era with the label that was in effect
+// for any of the days between from and to
+func eraDuring(eras []*Era, label string, from, to Date) (*Era, error) {
+	var found *Era
+	for _, era := range eras {
+		if !era.HasLabel(label) || !era.activeDuring(from, to) {
+			continue
+		}
+
+		if found == nil || era.StartDate().After(found.StartDate().Time) {
+			found = era
+		}
+	}
+
+	if found == nil {
+		return nil, ErrEraNotFound
+	}
+
+	return found, nil
 }
 
 // Teams is a collection of teams
@@ -0,0 +1,199 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+)
+
+func newErasTeam() *Team {
+	end := NewDate(2019, time.December, 31)
+	return &Team{Name: "Buffalo Bills", League: "NFL", Eras: []*Era{
+		{Year: 2021, Start: NewDate(2021, time.October, 3), Label: "alternate", Colors: []*Color{{Name: "Red", Hex: "#C60C30"}}},
+		{Year: 2011, Start: NewDate(2011, time.September, 11), Colors: []*Color{{Name: "Royal Blue", Hex: "#00338D"}}},
+		{Year: 2011, Start: NewDate(2011, time.January, 1), End: &end, Label: "Throwback", Colors: []*Color{{Name: "Royal Blue", Hex: "#003087"}}},
+		{Year: 2002, Colors: []*Color{{Name: "Midnight Navy", Hex: "#091F2C"}}},
+	}}
+}
+
+func TestEraIn(t *testing.T) {
+	g := gomega.NewWithT(t)
+	team := newErasTeam()
+
+	for year, start := range map[int]Date{
+		2002: NewDate(2002, time.January, 1),
+		2010: NewDate(2002, time.January, 1),
+		2011: NewDate(2011, time.September, 11),
+		2030: NewDate(2011, time.September, 11),
+	} {
+		era, err := team.EraAt(year)
+		g.Expect(err).Should(gomega.BeNil(), "year %d", year)
+		g.Expect(era.StartDate()).Should(gomega.Equal(start), "year %d", year)
+	}
+
+	_, err := team.EraAt(2001)
+	g.Expect(err).Should(gomega.MatchError(ErrEraNotFound))
+
+	era, err := team.EraIn(2019, "throwback")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(era.Label).Should(gomega.Equal("Throwback"))
+
+	// the throwback era ended
+	_, err = team.EraIn(2020, "throwback")
+	g.Expect(err).Should(gomega.MatchError(ErrEraNotFound))
+
+	era, err = team.EraIn(2011, PrimaryLabel)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(era.Colors[0].Hex).Should(gomega.Equal("#00338D"))
+}
+
+func TestEraOn(t *testing.T) {
+	g := gomega.NewWithT(t)
+	team := newErasTeam()
+
+	// the colors changed mid-season
+	era, err := team.EraOn(NewDate(2011, time.September, 10), "")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(era.Year).Should(gomega.Equal(2002))
+
+	era, err = team.EraOn(NewDate(2011, time.September, 11), "")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(era.Year).Should(gomega.Equal(2011))
+
+	_, err = team.EraOn(NewDate(2021, time.October, 2), "alternate")
+	g.Expect(err).Should(gomega.MatchError(ErrEraNotFound))
+}
+
+func TestCurrentEra(t *testing.T) {
+	g := gomega.NewWithT(t)
+	team := newErasTeam()
+
+	// the newest era is an alternate, so it is not current
+	g.Expect(team.CurrentEra()).Should(gomega.Equal(team.Eras[1]))
+
+	// a primary era that has ended is still current if it is the newest
+	end := NewDate(2015, time.December, 31)
+	team.Eras[1].End = &end
+	team.Eras[3].End = &end
+	g.Expect(team.CurrentEra()).Should(gomega.Equal(team.Eras[1]))
+
+	team.Eras = team.Eras[:1]
+	g.Expect(team.CurrentEra()).Should(gomega.Equal(team.Eras[0]))
+
+	team.Eras = nil
+	g.Expect(team.CurrentEra()).Should(gomega.BeNil())
+}
+
+func TestErasWithLabel(t *testing.T) {
+	g := gomega.NewWithT(t)
+	team := newErasTeam()
+
+	g.Expect(team.ErasWithLabel("")).Should(gomega.Equal([]*Era{team.Eras[1], team.Eras[3]}))
+	g.Expect(team.ErasWithLabel("THROWBACK")).Should(gomega.Equal([]*Era{team.Eras[2]}))
+	g.Expect(team.ErasWithLabel("city edition")).Should(gomega.BeEmpty())
+
+	era, err := team.EraByYear(2011, "")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(era).Should(gomega.Equal(team.Eras[1]))
+
+	era, err = team.EraByYear(2011, "throwback")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(era).Should(gomega.Equal(team.Eras[2]))
+
+	_, err = team.EraByYear(2021, "")
+	g.Expect(err).Should(gomega.MatchError(ErrEraNotFound))
+}
+
+func TestEraByStart(t *testing.T) {
+	g := gomega.NewWithT(t)
+	team := newErasTeam()
+
+	// the primary colors changed twice in 2011
+	early := &Era{Year: 2011, Colors: []*Color{{Name: "Navy", Hex: "#00274C"}}}
+	team.Eras = append(team.Eras[:3], early, team.Eras[3])
+	_, err := team.EraByYear(2011, "")
+	g.Expect(err).Should(gomega.MatchError(ErrEraAmbiguous))
+
+	era, err := team.EraByStart(NewDate(2011, time.September, 11), "")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(era).Should(gomega.Equal(team.Eras[1]))
+
+	era, err = team.Era(EraKey{Year: 2011, Start: NewDate(2011, time.January, 1)})
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(era).Should(gomega.BeIdenticalTo(early))
+
+	era, err = team.Era(EraKey{Year: 2011, Label: "throwback"})
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(era).Should(gomega.Equal(team.Eras[2]))
+
+	_, err = team.EraByStart(NewDate(2011, time.September, 12), "")
+	g.Expect(err).Should(gomega.MatchError(ErrEraNotFound))
+}
+
+func TestParseEraKey(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	key, err := ParseEraKey("2011", "throwback")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(key).Should(gomega.Equal(EraKey{Year: 2011, Label: "throwback"}))
+	g.Expect(key.String()).Should(gomega.Equal("2011"))
+
+	key, err = ParseEraKey("2011-09-11", "")
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(key).Should(gomega.Equal(EraKey{Year: 2011, Start: NewDate(2011, time.September, 11)}))
+	g.Expect(key.String()).Should(gomega.Equal("2011-09-11"))
+
+	_, err = ParseEraKey("2011-13-01", "")
+	g.Expect(err).Should(gomega.HaveOccurred())
+}
+
+func TestColorByRole(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	team := &Team{Name: "Buffalo Bills", League: "NFL", Eras: []*Era{
+		{Year: 2011, Colors: []*Color{
+			{Name: "White", Hex: "#FFFFFF", Role: RoleText},
+			{Name: "Royal Blue", Hex: "#00338D", Role: RolePrimary},
+			{Name: "Red", Hex: "#C60C30", Role: RoleAccent},
+			{Name: "Navy", Hex: "#0C2340", Role: RoleAccent},
+		}},
+		{Year: 2002, Colors: []*Color{{Name: "Midnight Navy", Hex: "#091F2C"}}},
+	}}
+
+	color, err := team.ColorByRole(RolePrimary)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(color.Name).Should(gomega.Equal("Royal Blue"))
+
+	color, err = team.Eras[0].ColorByRole(RoleAccent)
+	g.Expect(err).Should(gomega.BeNil())
+	g.Expect(color.Name).Should(gomega.Equal("Red"))
+	g.Expect(team.Eras[0].ColorsByRole(RoleAccent)).Should(gomega.Equal(team.Eras[0].Colors[2:]))
+
+	_, err = team.ColorByRole(RoleBackground)
+	g.Expect(err).Should(gomega.Equal(ErrColorNotFound))
+
+	// colors without roles are not assumed to have one from their order
+	_, err = team.Eras[1].ColorByRole(RolePrimary)
+	g.Expect(err).Should(gomega.Equal(ErrColorNotFound))
+	g.Expect(team.Eras[1].ColorsByRole(RolePrimary)).Should(gomega.BeEmpty())
+
+	_, err = (&Team{}).ColorByRole(RolePrimary)
+	g.Expect(err).Should(gomega.Equal(ErrColorNotFound))
+}
@@ -0,0 +1,474 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var hexPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// pantonePattern matches references such as PMS 201 C, 7621 C, Black 6 C and
+// Reflex Blue
+var pantonePattern = regexp.MustCompile(`^[0-9A-Za-z]+( [0-9A-Za-z-]+)*$`)
+
+// ValidationError describes a single problem found in a data file
+type ValidationError struct {
+	// Path is the JSON path of the offending value, e.g. teams[41].eras[0].colors[2].hex
+	Path string
+	// Line is the line in the data file the value was found on, or 0 if unknown
+	Line    int
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: %s: %s", e.Line, e.Path, e.Message)
+	}
+
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// ValidationErrors is every problem found while validating a data file
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+
+	return fmt.Sprintf("model: data file has %d problem(s):\n\t%s", len(e), strings.Join(msgs, "\n\t"))
+}
+
+// Validate checks the data file for problems that would cause teams to be
+// served incorrectly. If any are found, a ValidationErrors is returned
+// containing all of them.
+func Validate(data *DataFile) error {
+	v := validator{}
+	v.validate(data)
+	if len(v.errs) > 0 {
+		return v.errs
+	}
+
+	return nil
+}
+
+type validator struct {
+	errs ValidationErrors
+}
+
+func (v *validator) addf(path, format string, args ...interface{}) {
+	v.errs = append(v.errs, &ValidationError{
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) validate(data *DataFile) {
+	if data.Generated.IsZero() {
+		v.addf("generated", "generation date is required")
+	}
+
+	if len(data.Teams) == 0 {
+		v.addf("teams", "at least one team is required")
+	}
+
+	// keyed by lower-case league and slug, valued by the path first seen at
+	seen := make(map[string]string)
+	formerNames := make(map[string]string)
+	ids := make(map[int]string)
+	for i, team := range data.Teams {
+		path := fmt.Sprintf("teams[%d]", i)
+		if team == nil {
+			v.addf(path, "team is null")
+			continue
+		}
+
+		if team.ID <= 0 {
+			v.addf(path+".id", "id must be a positive number")
+		} else if first, ok := ids[team.ID]; ok {
+			v.addf(path+".id", "duplicate team id %d (first defined at %s)", team.ID, first)
+		} else {
+			ids[team.ID] = path
+		}
+
+		v.validateTeam(path, team)
+
+		league := strings.ToLower(team.League) + "\x00"
+		if slug := Slugify(team.Name); slug != "" {
+			if first, ok := seen[league+slug]; ok {
+				v.addf(path+".name", "duplicate team name %q in league %q (first defined at %s)", team.Name, team.League, first)
+			} else {
+				seen[league+slug] = path
+			}
+		}
+
+		// a former or predecessor name must lead to one team; duplicates
+		// within a team are reported by validateTeam
+		for _, former := range historicalNames(path, team) {
+			slug := Slugify(former.name)
+			if slug == "" {
+				continue
+			}
+
+			if first, ok := formerNames[league+slug]; !ok {
+				formerNames[league+slug] = former.path
+			} else if !strings.HasPrefix(first, path+".") {
+				v.addf(former.path, "former name %q is also a former name of the team at %s", former.name, first)
+			}
+		}
+	}
+}
+
+func (v *validator) validateTeam(path string, team *Team) {
+	if strings.TrimSpace(team.Name) == "" {
+		v.addf(path+".name", "name is required")
+	} else if Slugify(team.Name) == "" {
+		v.addf(path+".name", "name %q needs a letter or digit to make a slug from", team.Name)
+	}
+
+	if strings.TrimSpace(team.League) == "" {
+		v.addf(path+".league", "league is required")
+	}
+
+	if len(team.Eras) == 0 {
+		v.addf(path+".eras", "at least one era is required")
+	}
+
+	aliases := make(map[string]string)
+	for i, alias := range team.Aliases {
+		aliasPath := fmt.Sprintf("%s.aliases[%d]", path, i)
+		key := strings.ToLower(strings.TrimSpace(alias))
+		if key == "" {
+			v.addf(aliasPath, "alias must not be empty")
+		} else if first, ok := aliases[key]; ok {
+			v.addf(aliasPath, "duplicate alias %q (first defined at %s)", alias, first)
+		} else if key == strings.ToLower(team.Name) {
+			v.addf(aliasPath, "alias %q is the same as the team name", alias)
+		} else {
+			aliases[key] = aliasPath
+		}
+	}
+
+	formerNames := make(map[string]string)
+	for i, formerName := range team.FormerNames {
+		formerPath := fmt.Sprintf("%s.formerNames[%d]", path, i)
+		slug := Slugify(formerName)
+		if slug == "" {
+			v.addf(formerPath, "former name must have a letter or digit")
+		} else if first, ok := formerNames[slug]; ok {
+			v.addf(formerPath, "duplicate former name %q (first defined at %s)", formerName, first)
+		} else if slug == Slugify(team.Name) {
+			v.addf(formerPath, "former name %q is the same as the team name", formerName)
+		} else {
+			formerNames[slug] = formerPath
+		}
+	}
+
+	v.validateEras(path, team.Eras, 0, 0)
+	v.validatePredecessors(path, team)
+}
+
+type pathName struct {
+	path string
+	name string
+}
+
+// historicalNames returns the team's former names and the names of its
+// predecessors along with their paths
+func historicalNames(path string, team *Team) []pathName {
+	var names []pathName
+	for i, formerName := range team.FormerNames {
+		names = append(names, pathName{fmt.Sprintf("%s.formerNames[%d]", path, i), formerName})
+	}
+
+	for i, identity := range team.Predecessors {
+		if identity != nil {
+			names = append(names, pathName{fmt.Sprintf("%s.predecessors[%d].name", path, i), identity.Name})
+		}
+	}
+
+	return names
+}
+
+// validatePredecessors checks the identities a franchise had before the
+// team, which must be ordered oldest first without overlapping
+func (v *validator) validatePredecessors(path string, team *Team) {
+	var prev *Identity
+	for i, identity := range team.Predecessors {
+		identityPath := fmt.Sprintf("%s.predecessors[%d]", path, i)
+		if identity == nil {
+			v.addf(identityPath, "predecessor is null")
+			continue
+		}
+
+		if strings.TrimSpace(identity.Name) == "" {
+			v.addf(identityPath+".name", "name is required")
+		} else if Slugify(identity.Name) == "" {
+			v.addf(identityPath+".name", "name %q needs a letter or digit to make a slug from", identity.Name)
+		}
+
+		if identity.From <= 0 {
+			v.addf(identityPath+".from", "from must be a positive number")
+		}
+
+		if identity.To < identity.From {
+			v.addf(identityPath+".to", "to %d is before from %d", identity.To, identity.From)
+		}
+
+		if prev != nil && identity.From <= prev.To {
+			v.addf(identityPath+".from", "predecessors must be ordered oldest first without overlapping, but %d is not after %d", identity.From, prev.To)
+		}
+		prev = identity
+
+		if len(identity.Eras) == 0 {
+			v.addf(identityPath+".eras", "at least one era is required")
+		}
+
+		v.validateEras(identityPath, identity.Eras, identity.From, identity.To)
+	}
+
+	if prev != nil {
+		for i, era := range team.Eras {
+			if era != nil && era.Year > 0 && era.Year <= prev.To {
+				v.addf(fmt.Sprintf("%s.eras[%d].year", path, i), "era year %d is not after the last predecessor ended in %d", era.Year, prev.To)
+			}
+		}
+	}
+}
+
+// validateEras checks eras are ordered newest first. If from or to are not
+// zero, each era must begin between them.
+func (v *validator) validateEras(path string, eras []*Era, from, to int) {
+	// keyed by start date and label
+	starts := make(map[string]string)
+	for i, era := range eras {
+		eraPath := fmt.Sprintf("%s.eras[%d]", path, i)
+		if era == nil {
+			v.addf(eraPath, "era is null")
+			continue
+		}
+
+		if era.Year <= 0 {
+			v.addf(eraPath+".year", "year must be a positive number")
+		} else if key := eraKey(era); starts[key] != "" {
+			v.addf(eraPath+".year", "duplicate %s (first defined at %s)", eraName(era), starts[key])
+		} else {
+			starts[key] = eraPath
+		}
+
+		if !era.Start.IsZero() && era.Start.Year() != era.Year {
+			v.addf(eraPath+".start", "start %s is not in the era's year %d", era.Start, era.Year)
+		}
+
+		if era.End != nil && era.End.Before(era.StartDate().Time) {
+			v.addf(eraPath+".end", "end %s is before start %s", era.End, era.StartDate())
+		}
+
+		if era.Label != strings.TrimSpace(era.Label) || era.Label != "" && strings.TrimSpace(era.Label) == "" {
+			v.addf(eraPath+".label", "label %q must not be blank or have surrounding spaces", era.Label)
+		}
+
+		if i > 0 && eras[i-1] != nil {
+			prev := eras[i-1]
+			if prev.Year < era.Year {
+				v.addf(eraPath+".year", "eras must be ordered newest first, but %d follows %d", era.Year, prev.Year)
+			} else if prev.Year == era.Year && prev.StartDate().Before(era.StartDate().Time) {
+				v.addf(eraPath+".start", "eras must be ordered newest first, but %s follows %s", era.StartDate(), prev.StartDate())
+			}
+		}
+
+		if era.Year > 0 && (from > 0 && era.Year < from || to > 0 && era.Year > to) {
+			v.addf(eraPath+".year", "era year %d is outside of %d to %d", era.Year, from, to)
+		}
+
+		v.validateColors(eraPath, era.Colors)
+	}
+}
+
+// eraKey identifies an era by its start date and label
+func eraKey(era *Era) string {
+	label := strings.ToLower(era.Label)
+	if era.HasLabel(PrimaryLabel) {
+		label = ""
+	}
+
+	return era.StartDate().String() + "\x00" + label
+}
+
+func (v *validator) validateColors(eraPath string, colors []*Color) {
+	if len(colors) == 0 {
+		v.addf(eraPath+".colors", "at least one color is required")
+	}
+
+	roles := make(map[string]string)
+	for i, color := range colors {
+		path := fmt.Sprintf("%s.colors[%d]", eraPath, i)
+		if color == nil {
+			v.addf(path, "color is null")
+			continue
+		}
+
+		if strings.TrimSpace(color.Name) == "" {
+			v.addf(path+".name", "name is required")
+		}
+
+		if !hexPattern.MatchString(color.Hex) {
+			v.addf(path+".hex", "invalid hex color %q, expected the form #RRGGBB", color.Hex)
+		}
+
+		// an era can have any number of accents, but only one of each other role
+		switch first := roles[color.Role]; {
+		case color.Role == "":
+		case !IsRole(color.Role):
+			v.addf(path+".role", "invalid role %q, expected one of %s", color.Role, strings.Join(Roles, ", "))
+		case first != "" && color.Role != RoleAccent:
+			v.addf(path+".role", "duplicate %s role (first used at %s)", color.Role, first)
+		case first == "":
+			roles[color.Role] = path
+		}
+
+		v.validateReferences(path, color)
+	}
+}
+
+// validateReferences checks the optional values a team publishes for a color
+func (v *validator) validateReferences(path string, color *Color) {
+	if color.Pantone != "" && !pantonePattern.MatchString(color.Pantone) {
+		v.addf(path+".pantone", "invalid Pantone reference %q, expected a name or number such as PMS 201 C", color.Pantone)
+	}
+
+	if cmyk := color.CMYK; cmyk != nil {
+		for _, ink := range []struct {
+			name  string
+			value float64
+		}{{"c", cmyk.C}, {"m", cmyk.M}, {"y", cmyk.Y}, {"k", cmyk.K}} {
+			if ink.value < 0 || ink.value > 100 {
+				v.addf(path+".cmyk."+ink.name, "invalid CMYK value %v, expected a percentage from 0 to 100", ink.value)
+			}
+		}
+	}
+
+	if color.Formats != nil {
+		v.addf(path+".formats", "formats are computed from hex and cannot be set")
+	}
+
+	if color.Source != "" {
+		if strings.TrimSpace(color.Source) != color.Source {
+			v.addf(path+".source", "source has leading or trailing spaces")
+		} else if strings.Contains(color.Source, "://") {
+			if u, err := url.Parse(color.Source); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				v.addf(path+".source", "invalid source URL %q, expected an http or https URL", color.Source)
+			}
+		}
+	}
+}
+
+// locate fills in the line number of every error using the raw file contents
+func (e ValidationErrors) locate(raw []byte) {
+	lines := jsonPathLines(raw)
+	for _, err := range e {
+		// a missing value won't have a line, so fall back to its closest parent
+		for path := err.Path; path != "" && err.Line == 0; path = parentPath(path) {
+			err.Line = lines[path]
+		}
+	}
+}
+
+func parentPath(path string) string {
+	if i := strings.LastIndexAny(path, ".["); i >= 0 {
+		return path[:i]
+	}
+
+	return ""
+}
+
+// jsonPathLines maps the JSON path of every value in raw to the line it starts on
+func jsonPathLines(raw []byte) map[string]int {
+	var newlines []int
+	for i, b := range raw {
+		if b == '\n' {
+			newlines = append(newlines, i)
+		}
+	}
+
+	lines := make(map[string]int)
+	dec := json.NewDecoder(bytes.NewReader(raw))
+
+	var walk func(path string) error
+	walk = func(path string) error {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		// InputOffset is just past the token, which never spans a line
+		lines[path] = sort.SearchInts(newlines, int(dec.InputOffset())-1) + 1
+
+		switch tok {
+		case json.Delim('{'):
+			for dec.More() {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+
+				childPath := fmt.Sprintf("%s.%s", path, key)
+				if path == "" {
+					childPath = fmt.Sprint(key)
+				}
+
+				if err := walk(childPath); err != nil {
+					return err
+				}
+			}
+			_, err = dec.Token()
+		case json.Delim('['):
+			for i := 0; dec.More(); i++ {
+				if err := walk(fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+			_, err = dec.Token()
+		}
+
+		return err
+	}
+
+	// a partial map is still useful if the file is malformed
+	_ = walk("")
+	return lines
+}
+
+// lineAndColumn converts a byte offset in raw to a 1-based line and column
+func lineAndColumn(raw []byte, offset int64) (int, int) {
+	if offset > int64(len(raw)) {
+		offset = int64(len(raw))
+	}
+
+	before := raw[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
@@ -0,0 +1,257 @@
+/*
+Copyright 2020 Tom Peters
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+)
+
+func TestValidate(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	m, err := New("testdata/problems.json")
+	g.Expect(m).Should(gomega.BeNil())
+	g.Expect(err).Should(gomega.BeAssignableToTypeOf(ValidationErrors{}))
+
+	g.Expect(err.(ValidationErrors)).Should(gomega.Equal(ValidationErrors{
+		{Path: "teams[0].id", Line: 4, Message: "id must be a positive number"},
+		{Path: "teams[0].eras[0].colors[0].hex", Line: 4, Message: `invalid hex color "#9B274", expected the form #RRGGBB`},
+		{Path: "teams[0].eras[1].year", Line: 4, Message: "duplicate 2000 era (first defined at teams[0].eras[0])"},
+		{Path: "teams[0].eras[1].colors", Line: 4, Message: "at least one color is required"},
+		{Path: "teams[1].eras[0].colors[0].name", Line: 8, Message: "name is required"},
+		{Path: "teams[1].name", Line: 7, Message: `duplicate team name "a" in league "nfl" (first defined at teams[0])`},
+		{Path: "teams[2].id", Line: 11, Message: "duplicate team id 2 (first defined at teams[1])"},
+		{Path: "teams[2].league", Line: 11, Message: "league is required"},
+	}))
+}
+
+func TestValidateEraOrder(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	err := Validate(&DataFile{
+		Teams: Teams{
+			{ID: 1, Name: "Buffalo Bills", League: "NFL", Eras: []*Era{
+				{Year: 2002, Colors: []*Color{{Name: "Midnight Navy", Hex: "#091F2C"}}},
+				{Year: 2011, Colors: []*Color{{Name: "Royal Blue", Hex: "#003087"}}},
+			}},
+		},
+	})
+
+	g.Expect(err).Should(gomega.MatchError("model: data file has 2 problem(s):\n" +
+		"\tgenerated: generation date is required\n" +
+		"\tteams[0].eras[1].year: eras must be ordered newest first, but 2011 follows 2002"))
+}
+
+func TestValidateAliases(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	err := Validate(&DataFile{
+		Generated: time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC),
+		Teams: Teams{
+			{ID: 1, Name: "Arizona Cardinals", League: "NFL", Aliases: []string{"ARI", " ", "ari", "arizona cardinals"}, Eras: []*Era{
+				{Year: 2005, Colors: []*Color{{Name: "Cardinal Red", Hex: "#9B2743"}}},
+			}},
+		},
+	})
+
+	g.Expect(err).Should(gomega.Equal(ValidationErrors{
+		{Path: "teams[0].aliases[1]", Message: "alias must not be empty"},
+		{Path: "teams[0].aliases[2]", Message: `duplicate alias "ari" (first defined at teams[0].aliases[0])`},
+		{Path: "teams[0].aliases[3]", Message: `alias "arizona cardinals" is the same as the team name`},
+	}))
+}
+
+func TestValidateFormerNames(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	eras := []*Era{{Year: 2020, Colors: []*Color{{Name: "Black", Hex: "#000000"}}}}
+	data := &DataFile{
+		Generated: time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC),
+		Teams: Teams{
+			{ID: 1, Name: "Washington Football Team", League: "NFL", FormerNames: []string{"Boston Braves", "Boston Redskins", "Washington Redskins"}, Eras: eras},
+			{ID: 2, Name: "Arizona Cardinals", League: "NFL", FormerNames: []string{"Boston Braves", "Phoenix Cardinals", "phoenix-cardinals", "Arizona  Cardinals", "?"}, Eras: eras},
+			{ID: 3, Name: "Atlanta Braves", League: "MLB", FormerNames: []string{"Boston Braves"}, Eras: eras},
+			{ID: 3, Name: "St. Louis Blues", League: "NHL", Eras: eras},
+			{ID: 5, Name: "St Louis Blues", League: "NHL", Eras: eras},
+			{ID: 6, Name: "!!", League: "NHL", Eras: eras},
+		},
+	}
+
+	g.Expect(Validate(data)).Should(gomega.Equal(ValidationErrors{
+		{Path: "teams[1].formerNames[2]", Message: `duplicate former name "phoenix-cardinals" (first defined at teams[1].formerNames[1])`},
+		{Path: "teams[1].formerNames[3]", Message: `former name "Arizona  Cardinals" is the same as the team name`},
+		{Path: "teams[1].formerNames[4]", Message: "former name must have a letter or digit"},
+		{Path: "teams[1].formerNames[0]", Message: `former name "Boston Braves" is also a former name of the team at teams[0].formerNames[0]`},
+		{Path: "teams[3].id", Message: "duplicate team id 3 (first defined at teams[2])"},
+		{Path: "teams[4].name", Message: `duplicate team name "St Louis Blues" in league "NHL" (first defined at teams[3])`},
+		{Path: "teams[5].name", Message: `name "!!" needs a letter or digit to make a slug from`},
+	}))
+}
+
+func TestValidatePredecessors(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	era := func(year int) []*Era { return []*Era{{Year: year, Colors: []*Color{{Name: "Black", Hex: "#000000"}}}} }
+	data := &DataFile{
+		Generated: time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC),
+		Teams: Teams{
+			{ID: 1, Name: "Las Vegas Raiders", League: "NFL", FormerNames: []string{"Oakland Raiders"}, Eras: era(2020), Predecessors: []*Identity{
+				{Name: "Oakland Raiders", City: "Oakland", From: 1960, To: 1981, Eras: era(1963)},
+				{Name: "Los Angeles Raiders", City: "Los Angeles", From: 1982, To: 1994, Eras: era(1982)},
+				{Name: "Oakland Raiders", City: "Oakland", From: 1995, To: 2019, Eras: era(1995)},
+			}},
+		},
+	}
+	g.Expect(Validate(data)).Should(gomega.Succeed())
+
+	data.Teams = append(data.Teams,
+		&Team{ID: 2, Name: "Los Angeles Chargers", League: "NFL", Eras: era(2010), Predecessors: []*Identity{
+			{Name: "", From: 1960, To: 1960, Eras: era(1960)},
+			{Name: "San Diego Chargers", From: 1961, To: 2016, Eras: era(2017)},
+			{Name: "Los Angeles Raiders", From: 2015, To: 2014},
+			nil,
+		}},
+	)
+
+	g.Expect(Validate(data)).Should(gomega.Equal(ValidationErrors{
+		{Path: "teams[1].predecessors[0].name", Message: "name is required"},
+		{Path: "teams[1].predecessors[1].eras[0].year", Message: "era year 2017 is outside of 1961 to 2016"},
+		{Path: "teams[1].predecessors[2].to", Message: "to 2014 is before from 2015"},
+		{Path: "teams[1].predecessors[2].from", Message: "predecessors must be ordered oldest first without overlapping, but 2015 is not after 2016"},
+		{Path: "teams[1].predecessors[2].eras", Message: "at least one era is required"},
+		{Path: "teams[1].predecessors[3]", Message: "predecessor is null"},
+		{Path: "teams[1].eras[0].year", Message: "era year 2010 is not after the last predecessor ended in 2014"},
+		{Path: "teams[1].predecessors[2].name", Message: `former name "Los Angeles Raiders" is also a former name of the team at teams[0].predecessors[1].name`},
+	}))
+}
+
+func TestValidateEraDates(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	colors := []*Color{{Name: "Black", Hex: "#000000"}}
+	end := NewDate(2019, time.December, 31)
+	data := &DataFile{
+		Generated: time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC),
+		Teams: Teams{
+			{ID: 1, Name: "Buffalo Bills", League: "NFL", Eras: []*Era{
+				{Year: 2011, Start: NewDate(2011, time.September, 11), Colors: colors},
+				{Year: 2011, Start: NewDate(2011, time.January, 1), End: &end, Label: "throwback", Colors: colors},
+				{Year: 2011, Colors: colors},
+				{Year: 2002, Colors: colors},
+			}},
+		},
+	}
+	g.Expect(Validate(data)).Should(gomega.Succeed())
+
+	earlyEnd := NewDate(2011, time.March, 1)
+	data.Teams[0].Eras = []*Era{
+		{Year: 2011, Start: NewDate(2012, time.September, 11), Colors: colors},
+		{Year: 2011, Start: NewDate(2011, time.June, 1), End: &earlyEnd, Label: " throwback", Colors: colors},
+		{Year: 2011, Start: NewDate(2011, time.September, 11), Label: "Primary", Colors: colors},
+		{Year: 2002, Label: "alternate", Colors: colors},
+		{Year: 2002, Start: NewDate(2002, time.January, 1), Label: "Alternate", Colors: colors},
+	}
+
+	g.Expect(Validate(data)).Should(gomega.Equal(ValidationErrors{
+		{Path: "teams[0].eras[0].start", Message: "start 2012-09-11 is not in the era's year 2011"},
+		{Path: "teams[0].eras[1].end", Message: "end 2011-03-01 is before start 2011-06-01"},
+		{Path: "teams[0].eras[1].label", Message: `label " throwback" must not be blank or have surrounding spaces`},
+		{Path: "teams[0].eras[2].start", Message: "eras must be ordered newest first, but 2011-09-11 follows 2011-06-01"},
+		{Path: "teams[0].eras[4].year", Message: "duplicate 2002 Alternate era (first defined at teams[0].eras[3])"},
+	}))
+}
+
+func TestValidateColorRoles(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	data := &DataFile{
+		Generated: time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC),
+		Teams: Teams{
+			{ID: 1, Name: "Buffalo Bills", League: "NFL", Eras: []*Era{
+				{Year: 2011, Colors: []*Color{
+					{Name: "Royal Blue", Hex: "#00338D", Role: RolePrimary},
+					{Name: "Red", Hex: "#C60C30", Role: RoleAccent},
+					{Name: "Navy", Hex: "#0C2340", Role: RoleAccent},
+					{Name: "White", Hex: "#FFFFFF"},
+				}},
+			}},
+		},
+	}
+	g.Expect(Validate(data)).Should(gomega.Succeed())
+
+	data.Teams[0].Eras[0].Colors[1].Role = "Secondary"
+	data.Teams[0].Eras[0].Colors[2].Role = RolePrimary
+	data.Teams[0].Eras[0].Colors[3].Role = "highlight"
+
+	g.Expect(Validate(data)).Should(gomega.Equal(ValidationErrors{
+		{Path: "teams[0].eras[0].colors[1].role", Message: `invalid role "Secondary", expected one of primary, secondary, accent, text, background`},
+		{Path: "teams[0].eras[0].colors[2].role", Message: "duplicate primary role (first used at teams[0].eras[0].colors[0])"},
+		{Path: "teams[0].eras[0].colors[3].role", Message: `invalid role "highlight", expected one of primary, secondary, accent, text, background`},
+	}))
+}
+
+func TestValidateColorReferences(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	data := &DataFile{
+		Generated: time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC),
+		Teams: Teams{
+			{ID: 1, Name: "Arizona Cardinals", League: "NFL", Eras: []*Era{
+				{Year: 2005, Colors: []*Color{
+					{
+						Name:    "Cardinal Red",
+						Hex:     "#97233F",
+						Pantone: "PMS 201 C",
+						CMYK:    &CMYK{C: 0, M: 100, Y: 65, K: 34},
+						RGB:     &RGB{R: 151, G: 35, B: 63},
+						Source:  "https://www.azcardinals.com/brand",
+					},
+					{Name: "Black", Hex: "#000000", Pantone: "Black 6 C", Source: "Arizona Cardinals style guide, 2005"},
+				}},
+			}},
+		},
+	}
+	g.Expect(Validate(data)).Should(gomega.Succeed())
+
+	data.Teams[0].Eras[0].Colors = []*Color{
+		{Name: "Cardinal Red", Hex: "#97233F", Pantone: " 201 C", CMYK: &CMYK{C: -1, M: 100, Y: 65, K: 101}, Source: "ftp://example.com"},
+		{Name: "Black", Hex: "#000000", Source: "style guide ", Formats: &ColorFormats{}},
+	}
+	g.Expect(Validate(data)).Should(gomega.Equal(ValidationErrors{
+		{Path: "teams[0].eras[0].colors[0].pantone", Message: `invalid Pantone reference " 201 C", expected a name or number such as PMS 201 C`},
+		{Path: "teams[0].eras[0].colors[0].cmyk.c", Message: "invalid CMYK value -1, expected a percentage from 0 to 100"},
+		{Path: "teams[0].eras[0].colors[0].cmyk.k", Message: "invalid CMYK value 101, expected a percentage from 0 to 100"},
+		{Path: "teams[0].eras[0].colors[0].source", Message: `invalid source URL "ftp://example.com", expected an http or https URL`},
+		{Path: "teams[0].eras[0].colors[1].formats", Message: "formats are computed from hex and cannot be set"},
+		{Path: "teams[0].eras[0].colors[1].source", Message: "source has leading or trailing spaces"},
+	}))
+}
+
+func TestParseSyntaxError(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	data, err := Parse([]byte("{\n  \"teams\": [\n    { \"name\": 1 }\n  ]\n}"))
+	g.Expect(data).Should(gomega.BeNil())
+	g.Expect(err).Should(gomega.MatchError(gomega.HavePrefix("model: line 3, column 16: json: cannot unmarshal number")))
+
+	data, err = Parse([]byte(`{"teams": [], "colour": "red"}`))
+	g.Expect(data).Should(gomega.BeNil())
+	g.Expect(err).Should(gomega.MatchError(`model: json: unknown field "colour"`))
+}